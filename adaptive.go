@@ -0,0 +1,58 @@
+package main
+
+import (
+	"asteroids/internal/config"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// What the adaptive difficulty has seen of the player since its last
+// adjustment.
+type Adaptive struct {
+	timer    float32 // Seconds since the last adjustment.
+	alive    float32 // Seconds since the ship last died.
+	deaths   int
+	shots    int
+	shotHits int
+}
+
+// Rates how comfortably the player is coping every adaptive interval and nudges
+// the spawn rate and asteroid speed to keep the rating within the target band.
+// The rating in [0, 1] is the average of the accuracy and survival time, less a
+// penalty for every death.
+func updateAdaptive(state *GameState) {
+	// Survival escalates on its own schedule instead, and versus players are
+	// only up against each other.
+	if !config.IsAdaptive() || state.mode == Survival || state.mode == Versus {
+		return
+	}
+	tuning := config.Get().Adaptive
+
+	if anyAlive(state) {
+		state.adaptive.alive += TICK
+	}
+
+	state.adaptive.timer += TICK
+	if state.adaptive.timer < tuning.Interval {
+		return
+	}
+
+	accuracy := float32(0.5)
+	if state.adaptive.shots > 0 {
+		accuracy = min(1, float32(state.adaptive.shotHits)/float32(state.adaptive.shots))
+	}
+	survival := min(1, state.adaptive.alive/tuning.SurvivalGoal)
+	rating := (accuracy+survival)/2 - tuning.DeathPenalty*float32(state.adaptive.deaths)
+
+	adjustment := config.CurrentAdjustment()
+	scale := adjustment.SpawnRate
+	if rating > tuning.TargetHigh {
+		scale += tuning.Step
+	} else if rating < tuning.TargetLow {
+		scale -= tuning.Step
+	}
+	scale = rl.Clamp(scale, tuning.MinScale, tuning.MaxScale)
+	config.SetAdjustment(config.Adjustment{SpawnRate: scale, Speed: scale})
+
+	state.adaptive = Adaptive{alive: state.adaptive.alive}
+}
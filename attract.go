@@ -0,0 +1,56 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Seconds that the menu is left alone before a demo game starts.
+const ATTRACT_DELAY = 20.0
+
+// Demo game flown by the autopilot while nobody is playing, which returns to
+// the menu as soon as a key is pressed.
+type Attract struct {
+	Active    bool
+	idleTimer float32
+}
+
+// Counts how long the menu has been left alone. Returns true when a demo game
+// should start.
+func UpdateAttractIdle(attract *Attract) bool {
+	if rl.GetKeyPressed() != 0 || rl.GetGamepadButtonPressed() != 0 {
+		attract.idleTimer = 0
+		return false
+	}
+	attract.idleTimer += TICK
+	if attract.idleTimer < ATTRACT_DELAY {
+		return false
+	}
+	attract.idleTimer = 0
+	return true
+}
+
+// Starts a demo game of classic with a single ship flown by the best pilot.
+// The daily run isn't touched, as demo games are never recorded.
+func startAttract(attract *Attract) GameState {
+	attract.Active = true
+	state := newGame(Classic, 1, &DailyRun{})
+	assignPilots(&state, 1, Ace)
+	return state
+}
+
+// Returns true when the demo game should end, either because a key was
+// pressed or because the pilot ran out of lives.
+func attractOver(state *GameState) bool {
+	return rl.GetKeyPressed() != 0 || rl.GetGamepadButtonPressed() != 0 || state.isGameOver
+}
+
+// Renders the demo banner at the bottom of the screen.
+func drawAttractBanner(scale float32) {
+	fontSize := int32(20 * scale)
+
+	banner := "DEMO - PRESS ANY KEY"
+
+	width := int32(rl.GetScreenWidth())
+	y := int32(rl.GetScreenHeight()) - fontSize - int32(16*scale)
+	rl.DrawText(banner, width/2-rl.MeasureText(banner, fontSize)/2, y, fontSize, rl.Gray)
+}
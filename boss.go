@@ -0,0 +1,92 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/entities"
+	"asteroids/internal/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Seconds that the warning banner is shown for before a boss arrives.
+const BOSS_WARNING_DURATION = 3.0
+
+// Counts down to the next boss encounter and runs the boss while it is active.
+// Normal asteroid spawning is paused during the encounter.
+func updateBoss(state *GameState) {
+	tuning := config.Get().Boss
+	state.bossWarningTimer = max(0, state.bossWarningTimer-TICK)
+
+	// Bosses don't appear in the zen sandbox or in versus.
+	if !state.boss.Active && (state.mode == Zen || state.mode == Versus) {
+		return
+	}
+
+	if !state.boss.Active {
+		state.bossTimer += TICK
+		if state.bossTimer >= tuning.Interval {
+			state.bossTimer = 0
+			state.boss = entities.NewBoss(tuning.WeakPointHealth)
+			state.bossWarningTimer = BOSS_WARNING_DURATION
+		}
+		return
+	}
+
+	launched := entities.UpdateBoss(&state.boss, leadShip(state), TICK)
+	state.asteroids = append(state.asteroids, launched...)
+
+	// Shields can't absorb a collision with the boss, which would otherwise
+	// drain every charge over consecutive frames of contact.
+	for i := range state.players {
+		player := &state.players[i]
+		if !player.ship.IsDead() && state.boss.Collides(player.ship.Pos, entities.SHIP_HITBOX_RADIUS) {
+			killShip(state, player)
+		}
+	}
+}
+
+// Checks bullets against the boss' armour and weak points. The boss absorbs
+// every bullet that hits it, including piercing ones.
+func checkForBulletBossCollisions(state *GameState) {
+	if !state.boss.Active {
+		return
+	}
+
+	for i := len(state.bullets) - 1; i >= 0; i-- {
+		bullet := state.bullets[i]
+		if hitBoss(state, &state.players[bullet.Owner], bullet.Start, bullet.Radius, bullet.Damage) {
+			state.bullets = append(state.bullets[:i], state.bullets[i+1:]...)
+		}
+	}
+}
+
+// Damages the part of the boss at `pos`, adding any debris it sheds to the
+// game. The player who lands the final hit gets the bonus. Returns false if
+// nothing was hit.
+func hitBoss(state *GameState, player *Player, pos rl.Vector2, radius float32, damage int) bool {
+	phase := state.boss.Phase
+
+	hit, debris := entities.HitBoss(&state.boss, pos, radius, damage)
+	if !hit {
+		return false
+	}
+	state.asteroids = append(state.asteroids, debris...)
+
+	if state.boss.Phase != phase {
+		input.SendFeedback(input.EVERY_PLAYER, input.FeedbackBossPhase)
+	}
+	if state.boss.IsDefeated() {
+		defeatBoss(state, player)
+	}
+	return true
+}
+
+// Awards the bonus for defeating the boss, which breaks apart into debris.
+func defeatBoss(state *GameState, player *Player) {
+	addScore(state, player, state.boss.Pos, config.Get().Boss.Score)
+	state.asteroids = append(state.asteroids, entities.ShedArmour(&state.boss)...)
+	state.explosions = append(state.explosions, entities.NewExplosion(state.boss.Pos))
+	input.SendFeedback(input.EVERY_PLAYER, input.FeedbackBossPhase)
+
+	state.boss.Active = false
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/entities"
+	"asteroids/internal/input"
+	"asteroids/internal/network"
+	"fmt"
+	"math"
+	"net"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// Controls repeated in every inputs message, so that a lost packet is
+	// covered by the ones after it.
+	INPUT_REDUNDANCY = 8
+
+	// Ticks that other ships and the asteroids are drawn behind the newest
+	// snapshot, so that there is usually a later snapshot to move them
+	// towards even if one is lost.
+	INTERPOLATION_DELAY = 2 * SNAPSHOT_INTERVAL
+
+	// Seconds between hellos while waiting to be welcomed.
+	HELLO_INTERVAL = 0.5
+
+	// Seconds without a snapshot before the connection is considered lost.
+	SERVER_TIMEOUT = 5.0
+
+	// Most controls kept while waiting for the server to apply them.
+	MAX_PENDING_CONTROLS = 2 * constants.TICK_RATE
+
+	// Most snapshots kept for interpolation.
+	MAX_SNAPSHOTS = 16
+)
+
+// Controls sent to the server, along with the client tick they were sampled
+// on.
+type sentControls struct {
+	tick     uint32
+	controls input.Controls
+}
+
+// A client of a game running on a server. Only the local ship is simulated
+// on the client, straight away from the local controls, and it is corrected
+// whenever a snapshot shows where the server put it. Everything else is drawn
+// from the snapshots, slightly in the past so that it moves smoothly between
+// them. Spectators have no ship and draw everything from the snapshots.
+type Client struct {
+	conn       *network.Conn
+	server     *net.UDPAddr
+	spectating bool
+	welcomed   bool
+	player     int // Player slot given by the server, or -1 for a spectator.
+
+	tick      uint32         // Client tick of the newest controls.
+	pending   []sentControls // Controls that the server hasn't applied yet, oldest first.
+	snapshots []Snapshot     // Snapshots received, oldest first.
+	ship      entities.Ship  // Local ship predicted from the pending controls.
+	waiting   int            // Player slots still needing a client.
+
+	drawTick    float32 // Server tick that the view is drawn at.
+	helloTimer  float32
+	silentTimer float32 // Seconds since the server was last heard from.
+	rejected    string  // Why the server wouldn't let the client join.
+
+	View GameState // The game as it is drawn.
+}
+
+// Connects to the server at `address`, e.g. "localhost:7777", to play or to
+// spectate. Nothing is sent until the client is first updated.
+func Connect(address string, conditions network.Conditions, spectating bool) (*Client, error) {
+	server, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := network.Listen(":0", conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn: conn, server: server, spectating: spectating, player: -1, View: NewGameState(Classic, 1)}, nil
+}
+
+// Tells the server that the client is leaving and closes the socket.
+func (client *Client) Close() {
+	client.conn.Send(client.server, network.Message{Kind: network.Bye})
+	client.conn.Close()
+}
+
+// Returns true once the first snapshot has arrived and there is a game to
+// draw.
+func (client *Client) IsPlaying() bool {
+	return client.welcomed && len(client.snapshots) > 0
+}
+
+// Advances the client by one frame: handles the messages from the server,
+// sends the local controls and predicts the local ship, then moves the view
+// along. The cursor is the mouse position in playfield coordinates.
+// Spectators keep saying hello instead, so that the server keeps sending to
+// them.
+func (client *Client) Update(cursor rl.Vector2) {
+	client.handlePackets()
+
+	client.silentTimer += TICK
+	if !client.welcomed || client.spectating {
+		client.helloTimer -= TICK
+		if client.helloTimer <= 0 && client.rejected == "" {
+			client.conn.Send(client.server, network.Message{
+				Kind:     network.Hello,
+				Version:  network.VERSION,
+				Spectate: client.spectating,
+			})
+			client.helloTimer = HELLO_INTERVAL
+			if client.welcomed {
+				client.helloTimer = SPECTATOR_KEEPALIVE
+			}
+		}
+	}
+	if !client.IsPlaying() {
+		return
+	}
+
+	if !client.spectating {
+		controls := input.PollPlayer(0, client.ship.Pos, cursor)
+		client.sendControls(controls)
+		if client.isPredicting() {
+			predictShip(&client.ship, controls)
+		}
+	}
+
+	client.interpolate()
+}
+
+// Handles the messages received since the last frame.
+func (client *Client) handlePackets() {
+	for _, packet := range client.conn.Poll() {
+		if packet.From.String() != client.server.String() {
+			continue
+		}
+		client.silentTimer = 0
+
+		message := packet.Message
+		switch message.Kind {
+		case network.Welcome:
+			if !client.welcomed {
+				client.welcomed = true
+				client.player = message.Player
+				config.SetDifficulty(config.Difficulty(message.Difficulty))
+			}
+		case network.Rejected:
+			client.rejected = message.Reason
+		case network.Snapshot:
+			snapshot, err := decodeSnapshot(message.State)
+			if err != nil {
+				continue
+			}
+			// Snapshots which arrive out of order are too old to be of use.
+			if n := len(client.snapshots); n > 0 && snapshot.Tick <= client.snapshots[n-1].Tick {
+				continue
+			}
+			client.waiting = message.Waiting
+			client.receiveSnapshot(snapshot, message.Ack)
+		}
+	}
+}
+
+// Sends the newest controls to the server along with the ones before it that
+// may not have arrived, and keeps them until the server has applied them.
+func (client *Client) sendControls(controls input.Controls) {
+	client.tick++
+	client.pending = append(client.pending, sentControls{tick: client.tick, controls: controls})
+	if len(client.pending) > MAX_PENDING_CONTROLS {
+		client.pending = client.pending[len(client.pending)-MAX_PENDING_CONTROLS:]
+	}
+
+	recent := []input.Controls{}
+	for _, sent := range client.pending[max(len(client.pending)-INPUT_REDUNDANCY, 0):] {
+		recent = append(recent, sent.controls)
+	}
+	client.conn.Send(client.server, network.Message{
+		Kind:     network.Inputs,
+		Tick:     client.tick,
+		Controls: recent,
+	})
+}
+
+// Stores a snapshot and reconciles the local ship with it. The ship is put
+// where the server had it and the controls that the server hasn't applied yet
+// are replayed on top, so that it stays ahead of the server without drifting
+// away from it.
+func (client *Client) receiveSnapshot(snapshot Snapshot, ack uint32) {
+	client.snapshots = append(client.snapshots, snapshot)
+	if len(client.snapshots) > MAX_SNAPSHOTS {
+		client.snapshots = client.snapshots[len(client.snapshots)-MAX_SNAPSHOTS:]
+	}
+	if len(client.snapshots) == 1 {
+		client.drawTick = float32(snapshot.Tick)
+	}
+
+	if client.player < 0 || client.player >= len(snapshot.Players) {
+		return
+	}
+	client.ship = snapshot.Players[client.player].Ship
+
+	for len(client.pending) > 0 && client.pending[0].tick <= ack {
+		client.pending = client.pending[1:]
+	}
+	if client.isPredicting() {
+		for _, sent := range client.pending {
+			predictShip(&client.ship, sent.controls)
+		}
+	}
+}
+
+// Returns true while the game is running on the server, so that the local
+// ship should move.
+func (client *Client) isPredicting() bool {
+	newest := client.snapshots[len(client.snapshots)-1]
+	return client.waiting == 0 && !newest.IsGameOver && newest.RoundBreak <= 0
+}
+
+// Moves the local ship as the server would for the controls. Hyperspace isn't
+// predicted, as only the server knows where the ship lands, so the ship jumps
+// when the snapshot arrives instead.
+func predictShip(ship *entities.Ship, controls input.Controls) {
+	controls.Hyperspace = false
+	entities.UpdateShip(ship, controls)
+}
+
+// Moves the view along by a tick, drawing the game between the two snapshots
+// around the draw tick. The draw tick is kept a little behind the newest
+// snapshot, and snaps back into place if it strays too far, e.g. after a
+// burst of lost packets.
+func (client *Client) interpolate() {
+	newest := client.snapshots[len(client.snapshots)-1]
+	target := float32(newest.Tick) - INTERPOLATION_DELAY
+
+	client.drawTick++
+	if float32(math.Abs(float64(client.drawTick-target))) > 2*SNAPSHOT_INTERVAL {
+		client.drawTick = target
+	}
+	client.drawTick = min(client.drawTick, float32(newest.Tick))
+
+	// Snapshots before the last one at or before the draw tick are no longer
+	// needed.
+	last := 0
+	for i, snapshot := range client.snapshots {
+		if float32(snapshot.Tick) > client.drawTick {
+			break
+		}
+		last = i
+	}
+	client.snapshots = client.snapshots[last:]
+
+	from, to := client.snapshots[0], client.snapshots[0]
+	if len(client.snapshots) > 1 && float32(from.Tick) <= client.drawTick {
+		to = client.snapshots[1]
+	}
+
+	t := float32(0)
+	if to.Tick > from.Tick {
+		t = (client.drawTick - float32(from.Tick)) / float32(to.Tick-from.Tick)
+	}
+	client.View = interpolateSnapshots(from, to, t, client.drawTick-float32(from.Tick))
+
+	if client.player >= 0 && client.player < len(client.View.players) {
+		client.View.players[client.player].ship = client.ship
+	}
+}
+
+// Builds the view between two snapshots, `t` of the way from `from` to `to`.
+// Everything apart from the positions is taken from `from`. Bullets have no
+// identity to match them by, so they are moved on by their velocity for the
+// ticks `elapsed` since `from` instead.
+func interpolateSnapshots(from Snapshot, to Snapshot, t float32, elapsed float32) GameState {
+	state := restoreSnapshot(from)
+
+	for i := range state.players {
+		if i < len(to.Players) {
+			ship := &state.players[i].ship
+			ship.Pos = lerpWrapped(ship.Pos, to.Players[i].Ship.Pos, t)
+			ship.Rot = lerpAngle(ship.Rot, to.Players[i].Ship.Rot, t)
+		}
+	}
+
+	next := map[uint32]rl.Vector2{}
+	for _, asteroid := range to.Asteroids {
+		next[asteroid.ID] = asteroid.Pos
+	}
+	for i := range state.asteroids {
+		if pos, ok := next[state.asteroids[i].ID]; ok {
+			state.asteroids[i].Pos = lerpWrapped(state.asteroids[i].Pos, pos, t)
+		}
+	}
+
+	for i := range state.bullets {
+		bullet := &state.bullets[i]
+		offset := rl.Vector2Scale(rl.Vector2Multiply(bullet.Vel, bullet.Dir), elapsed)
+		bullet.Start = rl.Vector2Add(bullet.Start, offset)
+		bullet.End = rl.Vector2Add(bullet.End, offset)
+	}
+
+	return state
+}
+
+// Moves `t` of the way from `a` to `b`, unless the two are so far apart that
+// the object must have wrapped around the edge of the window, in which case
+// it stays at `a` until it has caught up.
+func lerpWrapped(a rl.Vector2, b rl.Vector2, t float32) rl.Vector2 {
+	if math.Abs(float64(b.X-a.X)) > SCREEN_WIDTH/2 || math.Abs(float64(b.Y-a.Y)) > SCREEN_HEIGHT/2 {
+		return a
+	}
+	return rl.Vector2Lerp(a, b, t)
+}
+
+// Turns `t` of the way from angle `a` to `b` the short way round.
+func lerpAngle(a float32, b float32, t float32) float32 {
+	diff := float32(math.Remainder(float64(b-a), 2*math.Pi))
+	return a + diff*t
+}
+
+// Returns the state of the connection to show at the bottom of the screen,
+// or "" while the game is being played.
+func (client *Client) status() string {
+	switch {
+	case client.rejected != "":
+		return "Could not join: " + client.rejected
+	case client.silentTimer > SERVER_TIMEOUT:
+		return "Lost connection to " + client.server.String()
+	case !client.IsPlaying():
+		return "Connecting to " + client.server.String() + "..."
+	case client.waiting > 0:
+		return fmt.Sprintf("Waiting for %d more player(s) to join", client.waiting)
+	case client.View.isGameOver:
+		return "A new game will start shortly"
+	case client.snapshots[len(client.snapshots)-1].Truncated:
+		return "Too much is going on to send everything - some objects are hidden"
+	}
+	return ""
+}
+
+// Renders the state of the connection at the bottom of the screen.
+func drawNetworkBanner(client *Client, scale float32) {
+	banner := client.status()
+	if banner == "" {
+		return
+	}
+
+	fontSize := int32(20 * scale)
+	width := int32(rl.GetScreenWidth())
+	y := int32(rl.GetScreenHeight()) - fontSize - int32(16*scale)
+	rl.DrawText(banner, width/2-rl.MeasureText(banner, fontSize)/2, y, fontSize, rl.Gray)
+}
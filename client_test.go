@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Returns a spectating client which has received snapshots on the given
+// server ticks. The first ship is moved 10 units along for every tick, so
+// that the view shows which snapshots it was drawn between.
+func newTestClient(t *testing.T, ticks ...uint32) *Client {
+	t.Helper()
+	state := newTestState(t, 1)
+	client := &Client{player: -1, welcomed: true, spectating: true}
+	for _, tick := range ticks {
+		state.players[0].ship.Pos = rl.Vector2{X: 10 * float32(tick), Y: 100}
+		client.receiveSnapshot(takeSnapshot(&state, tick), 0)
+	}
+	return client
+}
+
+func TestInterpolateBetweenSnapshots(t *testing.T) {
+	client := newTestClient(t, 0, 6, 12, 18, 24)
+
+	client.interpolate()
+	if client.drawTick != 1 || client.snapshots[0].Tick != 0 {
+		t.Fatalf("drawing tick %v from snapshot %d, want tick 1 from snapshot 0", client.drawTick, client.snapshots[0].Tick)
+	}
+	if x := client.View.players[0].ship.Pos.X; x < 9.9 || x > 10.1 {
+		t.Errorf("ship drawn at x %v, want 10", x)
+	}
+
+	for range 6 {
+		client.interpolate()
+	}
+	if client.snapshots[0].Tick != 6 || len(client.snapshots) != 4 {
+		t.Errorf("%d snapshots kept from tick %d, want 4 from tick 6", len(client.snapshots), client.snapshots[0].Tick)
+	}
+}
+
+func TestInterpolateCatchesUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		ticks     []uint32
+		drawTick  float32
+		wantTick  float32
+		wantFirst uint32
+	}{
+		// A burst of snapshots arrives at once, leaving the draw tick far
+		// behind the newest.
+		{"burst", []uint32{0, 6, 12, 18, 24, 30, 36, 42}, 0, 30, 30},
+		// Snapshots were lost, so there is a gap before the newest ones.
+		{"lost snapshots", []uint32{0, 48, 54, 60}, 0, 48, 48},
+		// The draw tick reaches the newest snapshot, which is then the only
+		// one left to draw.
+		{"newest", []uint32{0, 6, 12, 18, 24, 30, 36}, 35, 36, 36},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newTestClient(t, test.ticks...)
+			client.drawTick = test.drawTick
+
+			client.interpolate()
+			if client.drawTick != test.wantTick {
+				t.Errorf("draw tick %v, want %v", client.drawTick, test.wantTick)
+			}
+			if client.snapshots[0].Tick != test.wantFirst {
+				t.Errorf("oldest snapshot kept is from tick %d, want %d", client.snapshots[0].Tick, test.wantFirst)
+			}
+			if x := client.View.players[0].ship.Pos.X; x != 10*test.wantTick {
+				t.Errorf("ship drawn at x %v, want %v", x, 10*test.wantTick)
+			}
+		})
+	}
+}
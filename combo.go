@@ -0,0 +1,128 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/entities"
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Tracks consecutive hits for the score multiplier, along with the shots and
+// survival time used for the accuracy and streak bonuses.
+type Combo struct {
+	Hits  int     // Hits made without letting the combo window run out.
+	Timer float32 // Seconds left to make the next hit before the combo ends.
+
+	shots       int     // Projectiles fired since the last accuracy check.
+	shotHits    int     // Projectiles which hit an asteroid since the last accuracy check.
+	streakTimer float32 // Seconds survived since the last streak bonus.
+	streaks     int     // Streak bonuses awarded since the ship last died.
+}
+
+// Returns the combo tuning of the game's mode.
+func comboTuning(state *GameState) config.ComboTuning {
+	return config.Get().Combo.ForMode(state.mode.Key())
+}
+
+// Returns the score multiplier for the current combo.
+func (combo Combo) Multiplier(tuning config.ComboTuning) uint64 {
+	if !tuning.Enabled {
+		return 1
+	}
+	return min(1+uint64(combo.Hits/tuning.HitsPerStep), tuning.MaxMultiplier)
+}
+
+// Counts down every player's combo window and awards a bonus for every streak
+// interval survived without dying.
+func updateCombo(state *GameState) {
+	tuning := comboTuning(state)
+
+	for i := range state.players {
+		player := &state.players[i]
+
+		if player.combo.Timer > 0 {
+			player.combo.Timer -= TICK
+			if player.combo.Timer <= 0 {
+				player.combo.Hits = 0
+			}
+		}
+
+		if tuning.Enabled && !player.ship.IsDead() {
+			player.combo.streakTimer += TICK
+			if player.combo.streakTimer >= tuning.StreakInterval {
+				player.combo.streakTimer = 0
+				player.combo.streaks++
+				label := fmt.Sprintf("%.0fs STREAK", tuning.StreakInterval*float32(player.combo.streaks))
+				addBonus(state, player, label, tuning.StreakBonus*uint64(player.combo.streaks))
+			}
+		}
+	}
+
+	for i := len(state.popups) - 1; i >= 0; i-- {
+		state.popups[i].Timer -= TICK
+		if state.popups[i].Timer <= 0 {
+			state.popups = append(state.popups[:i], state.popups[i+1:]...)
+		}
+	}
+}
+
+// Ends the combo and streak when the ship dies.
+func breakCombo(player *Player) {
+	player.combo.Hits = 0
+	player.combo.Timer = 0
+	player.combo.streakTimer = 0
+	player.combo.streaks = 0
+}
+
+// Scores a hit at `pos` for the player, continuing their combo and applying
+// its multiplier. The popup is drawn in the colour of the player's ship.
+func scoreHit(state *GameState, player *Player, pos rl.Vector2, points uint64) {
+	tuning := comboTuning(state)
+	if tuning.Enabled {
+		player.combo.Hits++
+		player.combo.Timer = tuning.Window
+	}
+
+	multiplier := player.combo.Multiplier(tuning)
+	text := fmt.Sprintf("+%d", points*multiplier)
+	if multiplier > 1 {
+		text += fmt.Sprintf(" x%d", multiplier)
+	}
+
+	player.Score += points * multiplier
+	state.popups = append(state.popups, entities.NewPopup(pos, text, player.ship.Color))
+}
+
+// Adds points which aren't affected by the combo multiplier at `pos`.
+func addScore(state *GameState, player *Player, pos rl.Vector2, points uint64) {
+	player.Score += points
+	state.popups = append(state.popups, entities.NewPopup(pos, fmt.Sprintf("+%d", points), rl.Gold))
+}
+
+// Adds a labelled bonus, shown above the player's ship.
+func addBonus(state *GameState, player *Player, label string, points uint64) {
+	player.Score += points
+	state.popups = append(state.popups, entities.NewPopup(player.ship.Pos, fmt.Sprintf("%s +%d", label, points), rl.Yellow))
+}
+
+// Counts a fired projectile, checking the accuracy of the last batch of shots
+// once enough have been fired. The laser is not counted.
+func recordShot(state *GameState, player *Player) {
+	tuning := comboTuning(state)
+	if !tuning.Enabled {
+		return
+	}
+
+	player.combo.shots++
+	if player.combo.shots < tuning.AccuracyShots {
+		return
+	}
+
+	accuracy := float32(player.combo.shotHits) / float32(player.combo.shots)
+	if accuracy >= tuning.AccuracyThreshold {
+		addBonus(state, player, fmt.Sprintf("%.0f%% ACCURACY", min(accuracy, 1)*100), tuning.AccuracyBonus)
+	}
+	player.combo.shots = 0
+	player.combo.shotHits = 0
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"asteroids/internal/constants"
+	"asteroids/internal/input"
+	"asteroids/internal/replay"
+	"asteroids/internal/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Number of past daily results shown on the menu.
+const MENU_DAILY_RESULTS = 5
+
+// Outcome of the scored daily challenge attempt of a single day. The result
+// is saved without a score or replay as soon as the attempt starts, so that
+// quitting part way through doesn't allow another scored attempt.
+type DailyResult struct {
+	Date   string `json:"date"`
+	Seed   uint64 `json:"seed"`
+	Score  uint64 `json:"score"`
+	Replay string `json:"replay"` // Name of the replay file next to the result, empty until the attempt has finished.
+}
+
+// A daily challenge attempt being recorded, or a recorded one being played
+// back.
+type DailyRun struct {
+	Replay   replay.Replay
+	Playback bool // Whether the controls come from the replay instead of the player.
+	Scored   bool // Whether this is the day's scored attempt, rather than practice.
+	tick     int  // Index of the next controls to play back.
+}
+
+// Returns the seed of the daily challenge for a date. Everyone playing on the
+// same date gets the same seed.
+func DailySeed(date string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("asteroids-daily-" + date))
+	return hash.Sum64()
+}
+
+// Returns today's date in the format used for daily challenges.
+func today() string {
+	return time.Now().Format(time.DateOnly)
+}
+
+func dailyResultPath(date string) string {
+	return filepath.Join(constants.DAILY_DIR, date+".json")
+}
+
+// Starts recording an attempt at the daily challenge for a date. Only the
+// first attempt of the day is scored, which is marked as played straight away.
+// If the mark can't be written the attempt is still scored, and the error
+// comes up again when the result is saved at the end.
+func NewDailyRun(date string) DailyRun {
+	_, err := os.Stat(dailyResultPath(date))
+	run := DailyRun{
+		Replay: replay.New(DailySeed(date), date),
+		Scored: errors.Is(err, os.ErrNotExist),
+	}
+	if run.Scored {
+		writeDailyResult(DailyResult{Date: date, Seed: run.Replay.Seed})
+	}
+	return run
+}
+
+// Returns the recorded controls for the next tick of a replay, or false once
+// the replay has run out.
+func (run *DailyRun) Next() (input.Controls, bool) {
+	if run.tick >= len(run.Replay.Controls) {
+		return input.Controls{}, false
+	}
+	run.tick++
+	return run.Replay.Controls[run.tick-1], true
+}
+
+// Saves the result and replay of the day's scored attempt. Practice attempts
+// and replays being played back are not saved.
+func finishDaily(state *GameState, run *DailyRun, toast *utils.Toast) {
+	if run.Playback || !run.Scored {
+		return
+	}
+
+	date := run.Replay.Date
+	result := DailyResult{Date: date, Seed: run.Replay.Seed, Score: totalScore(state), Replay: date + ".replay"}
+	if err := saveDailyResult(result, run.Replay); err != nil {
+		*toast = utils.NewToast([]string{"Failed to save the daily result: " + err.Error()}, rl.Red)
+		return
+	}
+	*toast = utils.NewToast([]string{fmt.Sprintf("Daily challenge %s saved with %d points", date, totalScore(state))}, rl.Green)
+}
+
+// Writes a daily result and its replay to the daily directory.
+func saveDailyResult(result DailyResult, recording replay.Replay) error {
+	if err := os.MkdirAll(constants.DAILY_DIR, 0o755); err != nil {
+		return err
+	}
+	if err := replay.Save(filepath.Join(constants.DAILY_DIR, result.Replay), recording); err != nil {
+		return err
+	}
+	return writeDailyResult(result)
+}
+
+// Writes a daily result to the daily directory, replacing any earlier result
+// for the same date.
+func writeDailyResult(result DailyResult) error {
+	if err := os.MkdirAll(constants.DAILY_DIR, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dailyResultPath(result.Date), append(data, '\n'), 0o644)
+}
+
+// Reads the saved daily results, newest first. Unreadable results are skipped.
+func loadDailyResults() []DailyResult {
+	paths, _ := filepath.Glob(filepath.Join(constants.DAILY_DIR, "*.json"))
+
+	results := []DailyResult{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		result := DailyResult{}
+		if json.Unmarshal(data, &result) == nil {
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Date > results[j].Date
+	})
+	return results
+}
+
+// Draws which daily challenge is being played, and whether it is scored, at
+// the bottom centre of the window.
+func drawDailyBanner(run DailyRun, scale float32) {
+	fontSize := int32(20 * scale)
+
+	banner := "DAILY " + run.Replay.Date
+	switch {
+	case run.Playback:
+		banner += " - REPLAY"
+	case !run.Scored:
+		banner += " - PRACTICE, ALREADY PLAYED TODAY"
+	}
+
+	width := int32(rl.GetScreenWidth())
+	y := int32(rl.GetScreenHeight()) - fontSize - int32(16*scale)
+	rl.DrawText(banner, width/2-rl.MeasureText(banner, fontSize)/2, y, fontSize, rl.Gray)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"asteroids/internal/utils"
+	"os"
+	"testing"
+)
+
+// Runs the test from an empty directory, so that daily results are written
+// there.
+func inTempDir(t *testing.T) {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(dir) })
+}
+
+func TestDailyAttemptCountsOnceStarted(t *testing.T) {
+	inTempDir(t)
+	if run := NewDailyRun("2026-01-01"); !run.Scored {
+		t.Fatal("first attempt of the day isn't scored")
+	}
+
+	// The first attempt is abandoned without reaching game over.
+	if run := NewDailyRun("2026-01-01"); run.Scored {
+		t.Error("second attempt is scored after the first was abandoned")
+	}
+	if run := NewDailyRun("2026-01-02"); !run.Scored {
+		t.Error("first attempt of the next day isn't scored")
+	}
+}
+
+func TestFinishDailySavesResult(t *testing.T) {
+	inTempDir(t)
+	state := newTestState(t, 1)
+	state.players[0].Score = 1234
+
+	run := NewDailyRun("2026-01-01")
+	toast := utils.Toast{}
+	finishDaily(&state, &run, &toast)
+
+	results := loadDailyResults()
+	if len(results) != 1 {
+		t.Fatalf("%d daily results saved, want 1", len(results))
+	}
+	if results[0].Score != 1234 || results[0].Replay == "" {
+		t.Errorf("saved %+v, want the score and replay of the attempt", results[0])
+	}
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/input"
+	"asteroids/internal/utils"
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net"
+	"os"
+	"slices"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// Discrete actions: every combination of turning left, not turning or
+	// turning right, with and without thrust and with and without fire,
+	// followed by hyperspace on its own.
+	NUM_ENV_ACTIONS   = 13
+	HYPERSPACE_ACTION = NUM_ENV_ACTIONS - 1
+
+	// Rays cast out from the ship for the sensor observation, evenly spaced
+	// and starting straight ahead.
+	NUM_RAYS = 16
+
+	// Furthest that a ray can see. Rays which hit nothing read as 1.
+	RAY_LENGTH = SCREEN_HEIGHT / 2
+
+	// Reward taken away for every life lost.
+	LIFE_PENALTY = 100
+)
+
+// Kinds of observation that an environment returns.
+const (
+	ENTITIES_OBSERVATION = "entities"
+	RAYS_OBSERVATION     = "rays"
+)
+
+// A message from the agent. Resets start a new episode, optionally with a
+// seed and the kind of observation to return. Steps take either a discrete
+// action or continuous controls. Specs describe the environment.
+type EnvRequest struct {
+	Type        string       `json:"type"` // "reset", "step" or "spec".
+	Seed        *uint64      `json:"seed,omitempty"`
+	Observation string       `json:"observation,omitempty"`
+	Action      *int         `json:"action,omitempty"`
+	Controls    *EnvControls `json:"controls,omitempty"`
+}
+
+// Continuous controls of the ship for a step.
+type EnvControls struct {
+	Rotate     float32 `json:"rotate"` // -1 turns fully left, 1 turns fully right.
+	Thrust     float32 `json:"thrust"` // 0 to 1 forward thrust.
+	Fire       bool    `json:"fire"`
+	Hyperspace bool    `json:"hyperspace"`
+}
+
+// The reply to a request.
+type EnvResponse struct {
+	Observation any      `json:"observation,omitempty"`
+	Reward      float32  `json:"reward"`
+	Done        bool     `json:"done"`      // The ship ran out of lives or the game ended.
+	Truncated   bool     `json:"truncated"` // The episode hit the tick limit.
+	Info        EnvInfo  `json:"info"`
+	Spec        *EnvSpec `json:"spec,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// How the episode is going, sent with every response.
+type EnvInfo struct {
+	Score uint64 `json:"score"`
+	Lives int    `json:"lives"`
+	Tick  int    `json:"tick"`
+}
+
+// Sizes of the action and observation spaces.
+type EnvSpec struct {
+	Actions     int     `json:"actions"`
+	Rays        int     `json:"rays"`
+	RaysSize    int     `json:"rays_size"` // Length of the sensor vector: the rays then the ship's velocity.
+	FrameSkip   int     `json:"frame_skip"`
+	MaxTicks    int     `json:"max_ticks"`
+	TickRate    int     `json:"tick_rate"`
+	Width       float32 `json:"width"`
+	Height      float32 `json:"height"`
+	LifePenalty float32 `json:"life_penalty"`
+}
+
+// The ship as seen in the entities observation.
+type EnvShip struct {
+	X          float32 `json:"x"`
+	Y          float32 `json:"y"`
+	VelX       float32 `json:"vx"`
+	VelY       float32 `json:"vy"`
+	Rot        float32 `json:"rot"`
+	Dead       bool    `json:"dead"`
+	Shields    int     `json:"shields"`
+	WeaponTime float32 `json:"weapon_timer"` // Seconds until the weapon can fire again.
+}
+
+// An asteroid as seen in the entities observation, relative to the ship
+// across the edges of the playfield.
+type EnvAsteroid struct {
+	OffsetX float32 `json:"dx"`
+	OffsetY float32 `json:"dy"`
+	VelX    float32 `json:"vx"`
+	VelY    float32 `json:"vy"`
+	Radius  float32 `json:"radius"`
+	Health  int     `json:"health"`
+}
+
+// The entities observation.
+type EnvEntities struct {
+	Ship      EnvShip       `json:"ship"`
+	Asteroids []EnvAsteroid `json:"asteroids"` // Nearest first.
+}
+
+// A single-player game stepped by an agent, as fast as the agent asks, with
+// nothing drawn.
+type Environment struct {
+	state       GameState
+	mode        GameMode
+	observation string
+	frameSkip   int
+	maxTicks    int
+	tick        int
+	random      *rand.Rand // Picks the seeds of resets which don't give one.
+}
+
+func NewEnvironment(mode GameMode, frameSkip int, maxTicks int) *Environment {
+	return &Environment{
+		mode:        mode,
+		observation: RAYS_OBSERVATION,
+		frameSkip:   frameSkip,
+		maxTicks:    maxTicks,
+		random:      rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// Handles a request from the agent.
+func (env *Environment) Handle(request EnvRequest) EnvResponse {
+	switch request.Type {
+	case "spec":
+		return EnvResponse{Spec: env.spec(), Info: env.info()}
+	case "reset":
+		if request.Observation != "" && request.Observation != ENTITIES_OBSERVATION && request.Observation != RAYS_OBSERVATION {
+			return EnvResponse{Error: fmt.Sprintf("unknown observation %q", request.Observation)}
+		}
+		seed := env.random.Uint64()
+		if request.Seed != nil {
+			seed = *request.Seed
+		}
+		env.Reset(seed, request.Observation)
+		return EnvResponse{Observation: env.observe(), Info: env.info()}
+	case "step":
+		if env.state.players == nil {
+			return EnvResponse{Error: "reset before stepping"}
+		}
+		var controls input.Controls
+		switch {
+		case request.Action != nil:
+			if *request.Action < 0 || *request.Action >= NUM_ENV_ACTIONS {
+				return EnvResponse{Error: fmt.Sprintf("action must be from 0 to %d", NUM_ENV_ACTIONS-1)}
+			}
+			controls = actionControls(*request.Action)
+		case request.Controls != nil:
+			controls = input.Controls{
+				Rotate:     rl.Clamp(request.Controls.Rotate, -1, 1),
+				Thrust:     rl.Clamp(request.Controls.Thrust, 0, 1),
+				Fire:       request.Controls.Fire,
+				Hyperspace: request.Controls.Hyperspace,
+			}
+		default:
+			return EnvResponse{Error: "step needs an action or controls"}
+		}
+		return env.Step(controls)
+	}
+	return EnvResponse{Error: fmt.Sprintf("unknown request type %q", request.Type)}
+}
+
+// Starts a new episode from `seed`. The same seed and actions always play out
+// the same way.
+func (env *Environment) Reset(seed uint64, observation string) {
+	if observation != "" {
+		env.observation = observation
+	}
+	utils.Seed(seed)
+	env.state = newGame(env.mode, 1, &DailyRun{})
+	env.tick = 0
+}
+
+// Holds the controls for the frame skip, or until the episode ends, and
+// returns what the agent sees afterwards. The reward is the score earned,
+// less the life penalty for every life lost.
+func (env *Environment) Step(controls input.Controls) EnvResponse {
+	response := EnvResponse{}
+	for range env.frameSkip {
+		if env.state.isGameOver || env.tick >= env.maxTicks {
+			break
+		}
+		player := env.state.players[0]
+		update(&env.state, []input.Controls{controls})
+		controls = controls.Held()
+		env.tick++
+
+		after := env.state.players[0]
+		response.Reward += float32(after.Score) - float32(player.Score)
+		if after.lives < player.lives {
+			response.Reward -= LIFE_PENALTY * float32(player.lives-after.lives)
+		}
+	}
+
+	response.Done = env.state.isGameOver
+	response.Truncated = !response.Done && env.tick >= env.maxTicks
+	response.Observation = env.observe()
+	response.Info = env.info()
+	return response
+}
+
+func (env *Environment) info() EnvInfo {
+	if env.state.players == nil {
+		return EnvInfo{}
+	}
+	player := env.state.players[0]
+	return EnvInfo{Score: player.Score, Lives: int(player.lives), Tick: env.tick}
+}
+
+func (env *Environment) spec() *EnvSpec {
+	return &EnvSpec{
+		Actions:     NUM_ENV_ACTIONS,
+		Rays:        NUM_RAYS,
+		RaysSize:    NUM_RAYS + 2,
+		FrameSkip:   env.frameSkip,
+		MaxTicks:    env.maxTicks,
+		TickRate:    constants.TICK_RATE,
+		Width:       SCREEN_WIDTH,
+		Height:      SCREEN_HEIGHT,
+		LifePenalty: LIFE_PENALTY,
+	}
+}
+
+// Returns the observation of the kind chosen on the last reset.
+func (env *Environment) observe() any {
+	switch env.observation {
+	case ENTITIES_OBSERVATION:
+		return observeEntities(&env.state)
+	case RAYS_OBSERVATION:
+		return observeRays(&env.state)
+	}
+	panic("unreachable: unknown observation " + env.observation)
+}
+
+// Returns the controls for a discrete action.
+func actionControls(action int) input.Controls {
+	if action == HYPERSPACE_ACTION {
+		return input.Controls{Hyperspace: true}
+	}
+	return input.Controls{
+		Rotate: float32(action%3 - 1),
+		Thrust: float32(action / 3 % 2),
+		Fire:   action/6%2 == 1,
+	}
+}
+
+// Returns the ship and every asteroid, nearest first.
+func observeEntities(state *GameState) EnvEntities {
+	player := state.players[0]
+	ship := player.ship
+	vel := shipVelocity(ship)
+	entities := EnvEntities{
+		Ship: EnvShip{
+			X:          ship.Pos.X,
+			Y:          ship.Pos.Y,
+			VelX:       vel.X,
+			VelY:       vel.Y,
+			Rot:        ship.Rot,
+			Dead:       ship.IsDead(),
+			Shields:    ship.Shields,
+			WeaponTime: player.weaponTimer,
+		},
+		Asteroids: []EnvAsteroid{},
+	}
+
+	timeScale := asteroidTimeScale(state)
+	for _, asteroid := range state.asteroids {
+		offset := wrappedOffset(ship.Pos, asteroid.Pos)
+		vel := asteroidVelocity(asteroid, timeScale)
+		entities.Asteroids = append(entities.Asteroids, EnvAsteroid{
+			OffsetX: offset.X,
+			OffsetY: offset.Y,
+			VelX:    vel.X,
+			VelY:    vel.Y,
+			Radius:  float32(asteroid.Hitbox),
+			Health:  asteroid.Health,
+		})
+	}
+	slices.SortFunc(entities.Asteroids, func(a EnvAsteroid, b EnvAsteroid) int {
+		return cmp.Compare(a.OffsetX*a.OffsetX+a.OffsetY*a.OffsetY, b.OffsetX*b.OffsetX+b.OffsetY*b.OffsetY)
+	})
+	return entities
+}
+
+// Returns the sensor vector: how far each ray gets before hitting an
+// asteroid, as a fraction of the ray length, followed by the ship's velocity
+// along and across its heading in pixels per tick. The rays turn with the
+// ship, so the first always points straight ahead.
+func observeRays(state *GameState) []float32 {
+	ship := state.players[0].ship
+	sensors := make([]float32, NUM_RAYS+2)
+	for i := range NUM_RAYS {
+		dir := rl.Vector2Rotate(ship.Heading(), 2*math.Pi*float32(i)/NUM_RAYS)
+		sensors[i] = 1
+		for _, asteroid := range state.asteroids {
+			// The ray is cast as if the ship flew along it for a tick per
+			// pixel.
+			offset := wrappedOffset(asteroid.Pos, ship.Pos)
+			if hit, ok := timeToCollision(offset, dir, float32(asteroid.Hitbox)); ok {
+				sensors[i] = min(sensors[i], hit/RAY_LENGTH)
+			}
+		}
+	}
+
+	vel := shipVelocity(ship)
+	heading := ship.Heading()
+	sensors[NUM_RAYS] = rl.Vector2DotProduct(vel, heading)
+	sensors[NUM_RAYS+1] = rl.Vector2DotProduct(vel, rl.Vector2{X: -heading.Y, Y: heading.X})
+	return sensors
+}
+
+// Serves newline-delimited JSON requests from `reader` until it closes,
+// writing a response line for each.
+func serveEnvironment(env *Environment, reader io.Reader, writer io.Writer) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1<<20)
+	output := bufio.NewWriter(writer)
+	encoder := json.NewEncoder(output)
+
+	for scanner.Scan() {
+		var request EnvRequest
+		response := EnvResponse{}
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			response.Error = err.Error()
+		} else {
+			response = env.Handle(request)
+		}
+		if err := encoder.Encode(response); err != nil {
+			return err
+		}
+		if err := output.Flush(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Runs the game as a reinforcement learning environment without a window.
+// Requests are read from stdin and answered on stdout, or over TCP when a
+// port is given, one connection at a time as the game is global.
+func runEnv(args []string) error {
+	flags := flag.NewFlagSet("env", flag.ExitOnError)
+	port := flags.Int("port", 0, "TCP port to listen on, or 0 to use stdin and stdout")
+	modeName := flags.String("mode", Classic.Key(), "game mode to play: "+modeKeys())
+	difficultyName := flags.String("difficulty", "normal", "difficulty preset: easy, normal, hard or arcade")
+	frameSkip := flags.Int("frame-skip", 4, "ticks that each step holds its action for")
+	maxTicks := flags.Int("max-ticks", 5*60*constants.TICK_RATE, "ticks before an episode is truncated")
+	flags.Parse(args)
+
+	mode, ok := ParseMode(*modeName)
+	if !ok {
+		return fmt.Errorf("unknown mode %q", *modeName)
+	}
+	// The daily challenge always has the same seed, versus needs an
+	// opponent and the zen tools need a mouse.
+	if mode == Daily || mode == Versus || mode == Zen {
+		return fmt.Errorf("%s can't be used as an environment", strings.ToLower(mode.String()))
+	}
+	difficulty, ok := config.ParseDifficulty(*difficultyName)
+	if !ok {
+		return fmt.Errorf("unknown difficulty %q", *difficultyName)
+	}
+	config.SetDifficulty(difficulty)
+	if *frameSkip < 1 {
+		return fmt.Errorf("frame skip must be at least 1")
+	}
+	if *maxTicks < 1 {
+		return fmt.Errorf("max ticks must be at least 1")
+	}
+
+	// The tuning is loaded once, so that it can't change under an episode.
+	if _, err := config.NewWatcher(constants.TUNING_FILE); err != nil {
+		log.Printf("using default tuning: %v", err)
+	}
+	input.MuteFeedback(true)
+
+	if *port == 0 {
+		return serveEnvironment(NewEnvironment(mode, *frameSkip, *maxTicks), os.Stdin, os.Stdout)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	log.Printf("serving %s environment on %s", mode, listener.Addr())
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		log.Printf("agent connected from %s", conn.RemoteAddr())
+		err = serveEnvironment(NewEnvironment(mode, *frameSkip, *maxTicks), conn, conn)
+		conn.Close()
+		if err != nil {
+			log.Printf("agent at %s: %v", conn.RemoteAddr(), err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestActionControls(t *testing.T) {
+	seen := map[[4]float32]int{}
+	for action := range NUM_ENV_ACTIONS {
+		controls := actionControls(action)
+		key := [4]float32{controls.Rotate, controls.Thrust}
+		if controls.Fire {
+			key[2] = 1
+		}
+		if controls.Hyperspace {
+			key[3] = 1
+		}
+		if other, ok := seen[key]; ok {
+			t.Errorf("actions %d and %d give the same controls %+v", other, action, controls)
+		}
+		seen[key] = action
+	}
+	if !actionControls(HYPERSPACE_ACTION).Hyperspace {
+		t.Error("the last action isn't hyperspace")
+	}
+}
+
+// Sends the request lines to a new environment and returns the responses.
+func serveLines(t *testing.T, env *Environment, lines ...string) []EnvResponse {
+	t.Helper()
+	output := bytes.Buffer{}
+	if err := serveEnvironment(env, strings.NewReader(strings.Join(lines, "\n")), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := []EnvResponse{}
+	decoder := json.NewDecoder(&output)
+	for decoder.More() {
+		response := EnvResponse{}
+		if err := decoder.Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, response)
+	}
+	if len(responses) != len(lines) {
+		t.Fatalf("%d responses to %d requests", len(responses), len(lines))
+	}
+	return responses
+}
+
+func TestEnvProtocol(t *testing.T) {
+	newTestState(t, 1)
+	responses := serveLines(t, NewEnvironment(Classic, 4, 1000),
+		`{"type": "spec"}`,
+		`{"type": "step", "action": 0}`,
+		`{"type": "reset", "seed": 5, "observation": "entities"}`,
+		`{"type": "step", "action": 7}`,
+		`{"type": "step", "controls": {"rotate": 3, "thrust": 1}}`,
+		`{"type": "step", "action": 13}`,
+		`{"type": "step"}`,
+		`{"type": "reset", "observation": "pixels"}`,
+		`{"type": "jump"}`,
+		`not json`,
+	)
+
+	if spec := responses[0].Spec; spec == nil || spec.Actions != NUM_ENV_ACTIONS || spec.RaysSize != NUM_RAYS+2 || spec.FrameSkip != 4 {
+		t.Errorf("spec = %+v", spec)
+	}
+	for i, response := range responses[2:5] {
+		if response.Error != "" {
+			t.Errorf("request %d failed: %s", i+3, response.Error)
+		}
+	}
+	if responses[3].Info.Tick != 4 || responses[4].Info.Tick != 8 {
+		t.Errorf("ticks after two steps are %d and %d, want the frame skip each", responses[3].Info.Tick, responses[4].Info.Tick)
+	}
+	observation, ok := responses[2].Observation.(map[string]any)
+	if _, hasShip := observation["ship"]; !ok || !hasShip {
+		t.Errorf("reset observation = %v, want the entities", responses[2].Observation)
+	}
+
+	wantErrors := map[int]string{
+		1: "reset before stepping",
+		5: "action must be",
+		6: "needs an action or controls",
+		7: "unknown observation",
+		8: "unknown request type",
+		9: "invalid character",
+	}
+	for i, want := range wantErrors {
+		if !strings.Contains(responses[i].Error, want) {
+			t.Errorf("request %d error = %q, want %q", i+1, responses[i].Error, want)
+		}
+	}
+}
+
+func TestEnvIsDeterministic(t *testing.T) {
+	newTestState(t, 1)
+	requests := []string{`{"type": "reset", "seed": 9}`}
+	for i := range 100 {
+		requests = append(requests, fmt.Sprintf(`{"type": "step", "action": %d}`, i%NUM_ENV_ACTIONS))
+	}
+
+	first := serveLines(t, NewEnvironment(Classic, 2, 1000), requests...)
+	second := serveLines(t, NewEnvironment(Classic, 2, 1000), requests...)
+	for i := range first {
+		a, _ := json.Marshal(first[i])
+		b, _ := json.Marshal(second[i])
+		if !bytes.Equal(a, b) {
+			t.Fatalf("responses to request %d differ:\n%s\n%s", i+1, a, b)
+		}
+	}
+}
+
+func TestEnvTruncates(t *testing.T) {
+	newTestState(t, 1)
+	env := NewEnvironment(Zen, 4, 10)
+	env.Reset(1, "")
+
+	for step := range 3 {
+		response := env.Step(actionControls(1))
+		if want := step == 2; response.Truncated != want {
+			t.Errorf("step %d truncated = %v, want %v", step+1, response.Truncated, want)
+		}
+	}
+	if env.tick != 10 {
+		t.Errorf("stopped after %d ticks, want the limit of 10", env.tick)
+	}
+}
+
+func TestObserveRays(t *testing.T) {
+	state := newPilotTestState(t, rl.Vector2{X: 400, Y: 300})
+	i := addMovingAsteroid(&state, rl.Vector2{X: 400, Y: 400}, rl.Vector2{})
+	radius := float32(state.asteroids[i].Hitbox)
+
+	sensors := observeRays(&state)
+	if len(sensors) != NUM_RAYS+2 {
+		t.Fatalf("%d sensors, want %d", len(sensors), NUM_RAYS+2)
+	}
+	if want := (100 - radius) / RAY_LENGTH; math.Abs(float64(sensors[0]-want)) > 1e-3 {
+		t.Errorf("ray ahead reads %v, want %v", sensors[0], want)
+	}
+	if sensors[NUM_RAYS/2] != 1 {
+		t.Errorf("ray behind reads %v, want nothing seen", sensors[NUM_RAYS/2])
+	}
+}
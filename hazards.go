@@ -0,0 +1,56 @@
+package main
+
+import (
+	"asteroids/internal/entities"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Pulls the ships, asteroids and bullets towards the hazards and destroys
+// anything that crosses a black hole's event horizon. Nothing is scored for
+// asteroids lost to a black hole. The boss is too massive to be affected.
+func updateHazards(state *GameState) {
+	if len(state.hazards) == 0 {
+		return
+	}
+
+	for i := range state.players {
+		player := &state.players[i]
+		if player.ship.IsDead() {
+			continue
+		}
+
+		player.ship.Drift = rl.Vector2Add(player.ship.Drift, entities.Gravity(state.hazards, player.ship.Pos))
+		if entities.Swallowed(state.hazards, player.ship.Pos) {
+			killShip(state, player)
+
+			// Respawn the ship where it started, rather than inside the black
+			// hole.
+			player.ship.Pos = playerSpawn(player.index, len(state.players), state.mode)
+			player.ship.Drift = rl.Vector2{}
+		}
+	}
+
+	timeScale := asteroidTimeScale(state)
+	for i := len(state.asteroids) - 1; i >= 0; i-- {
+		asteroid := &state.asteroids[i]
+		entities.BendCourse(&asteroid.Vel, &asteroid.Dir, rl.Vector2Scale(entities.Gravity(state.hazards, asteroid.Pos), timeScale))
+		if entities.Swallowed(state.hazards, asteroid.Pos) {
+			state.asteroids = append(state.asteroids[:i], state.asteroids[i+1:]...)
+		}
+	}
+
+	for i := len(state.bullets) - 1; i >= 0; i-- {
+		bullet := &state.bullets[i]
+		entities.BendCourse(&bullet.Vel, &bullet.Dir, entities.Gravity(state.hazards, bullet.Start))
+		if entities.Swallowed(state.hazards, bullet.Start) {
+			state.bullets = append(state.bullets[:i], state.bullets[i+1:]...)
+		}
+	}
+
+	for i := len(state.powerUps) - 1; i >= 0; i-- {
+		if entities.Swallowed(state.hazards, state.powerUps[i].Pos) {
+			state.powerUps = append(state.powerUps[:i], state.powerUps[i+1:]...)
+		}
+	}
+}
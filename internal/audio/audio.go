@@ -0,0 +1,98 @@
+// Package which synthesises and plays the game's sound effects. Sounds are
+// generated at start up so that the game does not need any asset files.
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	SAMPLE_RATE = 44100
+	SAMPLE_SIZE = 16 // Bits per sample.
+)
+
+type Effect int
+
+const (
+	Chime Effect = iota // Played when an extra life is awarded.
+)
+
+// A single sine tone within a sound effect.
+type note struct {
+	frequency float64 // Frequency of the tone in Hz.
+	start     float64 // Seconds from the start of the effect.
+	duration  float64 // Seconds until the tone has decayed.
+}
+
+var effectNotes = map[Effect][]note{
+	Chime: {
+		{frequency: 1318.5, start: 0, duration: 0.35},   // E6
+		{frequency: 1975.5, start: 0.12, duration: 0.5}, // B6
+	},
+}
+
+var sounds = map[Effect]rl.Sound{}
+
+// Opens the audio device and synthesises every sound effect. The game still
+// runs without sound if no audio device is available.
+func Init() {
+	rl.InitAudioDevice()
+	if !rl.IsAudioDeviceReady() {
+		return
+	}
+
+	for effect, notes := range effectNotes {
+		sounds[effect] = rl.LoadSoundFromWave(synthesise(notes))
+	}
+}
+
+// Unloads the sound effects and closes the audio device.
+func Close() {
+	for effect, sound := range sounds {
+		rl.UnloadSound(sound)
+		delete(sounds, effect)
+	}
+	if rl.IsAudioDeviceReady() {
+		rl.CloseAudioDevice()
+	}
+}
+
+// Plays a sound effect. Does nothing if audio is unavailable.
+func Play(effect Effect) {
+	if sound, ok := sounds[effect]; ok {
+		rl.PlaySound(sound)
+	}
+}
+
+// Mixes the notes into a mono 16-bit wave. Each note decays exponentially so
+// that it sounds like a bell.
+func synthesise(notes []note) rl.Wave {
+	length := 0.0
+	for _, n := range notes {
+		length = max(length, n.start+n.duration)
+	}
+
+	frames := int(length * SAMPLE_RATE)
+	data := make([]byte, frames*SAMPLE_SIZE/8)
+	for i := 0; i < frames; i++ {
+		t := float64(i) / SAMPLE_RATE
+
+		sample := 0.0
+		for _, n := range notes {
+			if t < n.start || t > n.start+n.duration {
+				continue
+			}
+			local := t - n.start
+			envelope := math.Exp(-5 * local / n.duration)
+			sample += math.Sin(2*math.Pi*n.frequency*local) * envelope
+		}
+
+		sample = max(-1, min(sample/float64(len(notes)), 1))
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(sample*math.MaxInt16)))
+	}
+
+	return rl.NewWave(uint32(frames), SAMPLE_RATE, SAMPLE_SIZE, 1, data)
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"math"
+	"strings"
+)
+
+type Difficulty int
+
+const (
+	Easy Difficulty = iota
+	Normal
+	Hard
+	Arcade
+
+	NUM_DIFFICULTIES = iota
+)
+
+func (difficulty Difficulty) String() string {
+	return [...]string{"Easy", "Normal", "Hard", "Arcade"}[difficulty]
+}
+
+// Returns the difficulty with the given name, ignoring case.
+func ParseDifficulty(name string) (Difficulty, bool) {
+	for difficulty := range Difficulty(NUM_DIFFICULTIES) {
+		if strings.EqualFold(name, difficulty.String()) {
+			return difficulty, true
+		}
+	}
+	return Normal, false
+}
+
+// Returns the difficulty's preset from the loaded tuning.
+func (difficulty Difficulty) Tuning() DifficultyTuning {
+	table := current.Difficulties
+	switch difficulty {
+	case Easy:
+		return table.Easy
+	case Normal:
+		return table.Normal
+	case Hard:
+		return table.Hard
+	case Arcade:
+		return table.Arcade
+	}
+	panic("unreachable: unknown difficulty")
+}
+
+// Multipliers applied on top of the difficulty by the adaptive difficulty.
+type Adjustment struct {
+	SpawnRate float32
+	Speed     float32
+}
+
+var NO_ADJUSTMENT = Adjustment{SpawnRate: 1, Speed: 1}
+
+var (
+	difficulty = Normal
+	adaptive   = false
+	adjustment = NO_ADJUSTMENT
+	fixedRules = false
+
+	// The loaded tuning with the difficulty and adjustment applied.
+	effective = current.withDifficulty(difficulty.Tuning(), adjustment)
+)
+
+// Returns the difficulty currently being played.
+func CurrentDifficulty() Difficulty {
+	if fixedRules {
+		return Normal
+	}
+	return difficulty
+}
+
+func SetDifficulty(d Difficulty) {
+	difficulty = d
+	refresh()
+}
+
+// Returns true if the adaptive difficulty is enabled.
+func IsAdaptive() bool {
+	return adaptive && !fixedRules
+}
+
+func SetAdaptive(enabled bool) {
+	adaptive = enabled
+}
+
+// Returns the adaptive adjustment currently applied.
+func CurrentAdjustment() Adjustment {
+	return adjustment
+}
+
+func SetAdjustment(a Adjustment) {
+	adjustment = a
+	refresh()
+}
+
+// Switches to the fixed rules, which are the default tuning on normal
+// difficulty without any adjustment. The loaded tuning and chosen difficulty
+// are ignored until the fixed rules are switched off, so that every player
+// plays a seeded game under the same rules.
+func SetFixedRules(enabled bool) {
+	fixedRules = enabled
+	refresh()
+}
+
+// Recalculates the tuning used by the game after any of its inputs change.
+func refresh() {
+	if fixedRules {
+		rules := Default()
+		effective = rules.withDifficulty(rules.Difficulties.Normal, NO_ADJUSTMENT)
+		return
+	}
+	effective = current.withDifficulty(difficulty.Tuning(), adjustment)
+}
+
+// Returns a copy of the tuning with a difficulty preset and adjustment
+// applied. A higher spawn rate shortens the spawn interval.
+func (t Tuning) withDifficulty(preset DifficultyTuning, a Adjustment) Tuning {
+	t.AsteroidSpawnInterval *= preset.SpawnInterval / a.SpawnRate
+
+	t.Asteroids.Small = scaleAsteroid(t.Asteroids.Small, preset.Speed.Small*a.Speed, preset.Health)
+	t.Asteroids.Medium = scaleAsteroid(t.Asteroids.Medium, preset.Speed.Medium*a.Speed, preset.Health)
+	t.Asteroids.Large = scaleAsteroid(t.Asteroids.Large, preset.Speed.Large*a.Speed, preset.Health)
+
+	t.Lives.Starting = preset.Lives
+	t.Lives.Max = max(t.Lives.Max, preset.Lives)
+	return t
+}
+
+func scaleAsteroid(asteroid AsteroidTuning, speed float32, health float32) AsteroidTuning {
+	asteroid.Speed *= speed
+	asteroid.Health = max(1, int(math.Round(float64(asteroid.Health)*float64(health))))
+	return asteroid
+}
@@ -0,0 +1,92 @@
+package config
+
+import "testing"
+
+// Puts the difficulty and its adjustments back to the defaults once the test
+// is over.
+func resetDifficulty(t *testing.T) {
+	t.Cleanup(func() {
+		Set(Default())
+		SetFixedRules(false)
+		SetAdaptive(false)
+		SetAdjustment(NO_ADJUSTMENT)
+		SetDifficulty(Normal)
+	})
+}
+
+func TestParseDifficulty(t *testing.T) {
+	for difficulty := range Difficulty(NUM_DIFFICULTIES) {
+		if parsed, ok := ParseDifficulty(difficulty.String()); !ok || parsed != difficulty {
+			t.Errorf("ParseDifficulty(%q) = %v, %v", difficulty, parsed, ok)
+		}
+	}
+	if _, ok := ParseDifficulty("hard"); !ok {
+		t.Error("ParseDifficulty() is case sensitive")
+	}
+	if _, ok := ParseDifficulty("nightmare"); ok {
+		t.Error("ParseDifficulty() accepted an unknown difficulty")
+	}
+}
+
+func TestDifficultyScalesTuning(t *testing.T) {
+	resetDifficulty(t)
+	Set(Default())
+	base := Default()
+
+	SetDifficulty(Hard)
+	hard := Get()
+	preset := Hard.Tuning()
+	if want := base.AsteroidSpawnInterval * preset.SpawnInterval; hard.AsteroidSpawnInterval != want {
+		t.Errorf("hard spawn interval %v, want %v", hard.AsteroidSpawnInterval, want)
+	}
+	if want := base.Asteroids.Large.Speed * preset.Speed.Large; hard.Asteroids.Large.Speed != want {
+		t.Errorf("hard large asteroid speed %v, want %v", hard.Asteroids.Large.Speed, want)
+	}
+	if hard.Lives.Starting != preset.Lives {
+		t.Errorf("hard starts with %d lives, want %d", hard.Lives.Starting, preset.Lives)
+	}
+
+	// Arcade has no asteroid health, but asteroids still take a hit to break.
+	SetDifficulty(Arcade)
+	if health := Get().Asteroids.Large.Health; health != 1 {
+		t.Errorf("arcade large asteroid health %d, want 1", health)
+	}
+}
+
+func TestAdjustmentScalesTuning(t *testing.T) {
+	resetDifficulty(t)
+	Set(Default())
+	base := Get()
+
+	SetAdjustment(Adjustment{SpawnRate: 2, Speed: 1.5})
+	adjusted := Get()
+	if want := base.AsteroidSpawnInterval / 2; adjusted.AsteroidSpawnInterval != want {
+		t.Errorf("spawn interval %v with double the spawn rate, want %v", adjusted.AsteroidSpawnInterval, want)
+	}
+	if want := base.Asteroids.Small.Speed * 1.5; adjusted.Asteroids.Small.Speed != want {
+		t.Errorf("small asteroid speed %v, want %v", adjusted.Asteroids.Small.Speed, want)
+	}
+}
+
+func TestFixedRulesIgnoreSettings(t *testing.T) {
+	resetDifficulty(t)
+	tuning := Default()
+	tuning.AsteroidSpawnInterval = 100
+	Set(tuning)
+	SetDifficulty(Easy)
+	SetAdaptive(true)
+	SetAdjustment(Adjustment{SpawnRate: 2, Speed: 2})
+
+	SetFixedRules(true)
+	if CurrentDifficulty() != Normal || IsAdaptive() {
+		t.Errorf("difficulty %v and adaptive %v under the fixed rules, want normal and off", CurrentDifficulty(), IsAdaptive())
+	}
+	if got, want := Get().AsteroidSpawnInterval, Default().AsteroidSpawnInterval; got != want {
+		t.Errorf("spawn interval %v under the fixed rules, want the default %v", got, want)
+	}
+
+	SetFixedRules(false)
+	if CurrentDifficulty() != Easy || Get().AsteroidSpawnInterval == Default().AsteroidSpawnInterval {
+		t.Error("the chosen difficulty and tuning didn't come back after the fixed rules")
+	}
+}
@@ -0,0 +1,733 @@
+// Package which holds the gameplay tuning values. Tuning is loaded from an
+// external JSON file and can be reloaded while the game is running so that
+// balancing changes show up without restarting.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Ship physics values used by `entities.UpdateShip`.
+type ShipTuning struct {
+	RotationSpeed float32 `json:"rotation_speed"`
+	Accel         float32 `json:"accel"`
+	Decel         float32 `json:"decel"`
+	MinVel        float32 `json:"min_vel"`
+	MaxVel        float32 `json:"max_vel"`
+	Drag          float32 `json:"drag"`
+}
+
+// Values for a single asteroid size.
+type AsteroidTuning struct {
+	MinRadius float64 `json:"min_radius"` // Minimum radius of the generated polygon.
+	MaxRadius float64 `json:"max_radius"` // Maximum radius of the generated polygon.
+	Speed     float32 `json:"speed"`
+	Hitbox    int     `json:"hitbox"`
+	Health    int     `json:"health"`
+	Score     uint64  `json:"score"`
+}
+
+// Asteroid size table, one entry per `entities.AsteroidSize`.
+type AsteroidTable struct {
+	Small  AsteroidTuning `json:"small"`
+	Medium AsteroidTuning `json:"medium"`
+	Large  AsteroidTuning `json:"large"`
+}
+
+// Starting lives and the scores at which extra lives are awarded.
+type LivesTuning struct {
+	Starting   uint8    `json:"starting"`
+	Max        uint8    `json:"max"`        // Extra lives are not awarded past this many lives.
+	Thresholds []uint64 `json:"thresholds"` // Scores at which the first extra lives are awarded.
+	Every      uint64   `json:"every"`      // Points between extra lives after the last threshold. 0 disables.
+}
+
+// Spawn weight and multipliers for a single asteroid material. The
+// multipliers are applied on top of the asteroid size table.
+type MaterialTuning struct {
+	Weight float32 `json:"weight"` // Relative chance of a spawned asteroid using this material.
+	Health float32 `json:"health"` // Health multiplier.
+	Score  float32 `json:"score"`  // Score multiplier.
+}
+
+// Material table, one entry per `entities.Material`.
+type MaterialTable struct {
+	Rock      MaterialTuning `json:"rock"`
+	Metallic  MaterialTuning `json:"metallic"`
+	Explosive MaterialTuning `json:"explosive"`
+	Ice       MaterialTuning `json:"ice"`
+	Magnetic  MaterialTuning `json:"magnetic"`
+	Crystal   MaterialTuning `json:"crystal"`
+}
+
+// Fire rate, damage, projectile speed and heat profile of a single weapon.
+// Heat is measured as a fraction of the overheat limit, so a weapon overheats
+// once its heat reaches 1.
+type WeaponTuning struct {
+	Cooldown float32 `json:"cooldown"` // Seconds between shots, or between damage ticks for beams.
+	Damage   int     `json:"damage"`   // Damage per hit, or the damage of a fully charged shot.
+	Speed    float32 `json:"speed"`    // Projectile speed. Unused by beams.
+	Heat     float32 `json:"heat"`     // Heat added by each shot or beam tick.
+	Cooling  float32 `json:"cooling"`  // Heat dissipated per second.
+}
+
+// Weapon table, one entry per `entities.WeaponKind`.
+type WeaponTable struct {
+	Blaster WeaponTuning `json:"blaster"`
+	Spread  WeaponTuning `json:"spread"`
+	Laser   WeaponTuning `json:"laser"`
+	Homing  WeaponTuning `json:"homing"`
+	Charge  WeaponTuning `json:"charge"`
+}
+
+// Drop rate and durations of the power-ups dropped by asteroids.
+type PowerUpTuning struct {
+	DropChance     float32 `json:"drop_chance"`     // Chance in [0, 1] that a destroyed asteroid drops a pickup.
+	Lifetime       float32 `json:"lifetime"`        // Seconds before an uncollected pickup expires.
+	EffectDuration float32 `json:"effect_duration"` // Seconds that a timed power-up lasts.
+	MaxShields     int     `json:"max_shields"`     // Hits absorbed by a fully charged shield.
+}
+
+// How often boss encounters happen and how tough the boss is.
+type BossTuning struct {
+	Interval        float32 `json:"interval"`          // Seconds of play between boss encounters.
+	WeakPointHealth int     `json:"weak_point_health"` // Health of each of the boss' weak points.
+	Score           uint64  `json:"score"`             // Bonus score for defeating the boss.
+}
+
+// How combos raise the score multiplier and the bonuses for accuracy and for
+// surviving without dying. Game modes can have combo tuning of their own,
+// which starts from this block so that a mode only needs to set what it
+// changes.
+type ComboTuning struct {
+	Enabled           bool    `json:"enabled"`
+	Window            float32 `json:"window"`             // Seconds after a hit in which the next hit continues the combo.
+	HitsPerStep       int     `json:"hits_per_step"`      // Hits needed to raise the multiplier by one.
+	MaxMultiplier     uint64  `json:"max_multiplier"`     // Highest multiplier a combo can reach.
+	AccuracyShots     int     `json:"accuracy_shots"`     // Shots fired between accuracy checks.
+	AccuracyThreshold float32 `json:"accuracy_threshold"` // Fraction in [0, 1] of those shots that must hit for the bonus.
+	AccuracyBonus     uint64  `json:"accuracy_bonus"`     // Bonus for passing an accuracy check.
+	StreakInterval    float32 `json:"streak_interval"`    // Seconds survived between streak bonuses.
+	StreakBonus       uint64  `json:"streak_bonus"`       // Bonus for the first streak, multiplied by the streak count after that.
+
+	Modes map[string]ComboTuning `json:"modes,omitempty"` // Combo tuning of particular game modes, keyed by mode, e.g. "time-attack".
+}
+
+// A hazard placed at a fixed position in the playfield.
+type HazardPlacement struct {
+	Kind string  `json:"kind"` // Either "gravity_well" or "black_hole".
+	X    float32 `json:"x"`
+	Y    float32 `json:"y"`
+}
+
+// Number of hazards placed at random positions.
+type HazardCounts struct {
+	GravityWells int `json:"gravity_wells"`
+	BlackHoles   int `json:"black_holes"`
+}
+
+// Where gravity wells and black holes are placed at the start of a game and
+// how strongly they pull.
+type HazardTuning struct {
+	Placements        []HazardPlacement       `json:"placements"`          // Hazards at fixed positions.
+	GravityWells      int                     `json:"gravity_wells"`       // Gravity wells at random positions in modes without counts of their own.
+	BlackHoles        int                     `json:"black_holes"`         // Black holes at random positions in modes without counts of their own.
+	Modes             map[string]HazardCounts `json:"modes"`               // Random hazards in particular game modes, keyed by mode, e.g. "survival".
+	Seed              uint64                  `json:"seed"`                // Seed for the random positions, 0 for a new layout every game.
+	WellStrength      float32                 `json:"well_strength"`       // Pull of a gravity well at its centre, in pixels per frame squared.
+	BlackHoleStrength float32                 `json:"black_hole_strength"` // Pull of a black hole at its centre, in pixels per frame squared.
+}
+
+// Length of a time attack game and the time lost on every death.
+type TimeAttackTuning struct {
+	Duration     float32 `json:"duration"`      // Seconds that the player has to score.
+	DeathPenalty float32 `json:"death_penalty"` // Seconds lost every time the ship dies.
+}
+
+// How quickly endless survival escalates and how it is scored.
+type SurvivalTuning struct {
+	SpawnGrowth     float32 `json:"spawn_growth"`      // Spawn rate multiplier gained every minute.
+	SpeedGrowth     float32 `json:"speed_growth"`      // Asteroid speed multiplier gained every minute.
+	MaxSpawnRate    float32 `json:"max_spawn_rate"`    // Highest spawn rate multiplier.
+	MaxSpeed        float32 `json:"max_speed"`         // Highest asteroid speed multiplier.
+	StormInterval   float32 `json:"storm_interval"`    // Seconds between storms.
+	StormSize       int     `json:"storm_size"`        // Asteroids in the first storm.
+	StormGrowth     int     `json:"storm_growth"`      // Extra asteroids in each storm after the first.
+	PointsPerSecond float32 `json:"points_per_second"` // Score for every second survived.
+}
+
+// Multipliers for a single asteroid size.
+type SizeScale struct {
+	Small  float32 `json:"small"`
+	Medium float32 `json:"medium"`
+	Large  float32 `json:"large"`
+}
+
+// Changes made to the rest of the tuning by a difficulty preset.
+type DifficultyTuning struct {
+	SpawnInterval float32   `json:"spawn_interval"` // Multiplier of the asteroid spawn interval.
+	Speed         SizeScale `json:"speed"`          // Multipliers of the asteroid speeds.
+	Health        float32   `json:"health"`         // Multiplier of the asteroid health, which is never below 1.
+	Lives         uint8     `json:"lives"`          // Starting lives.
+}
+
+// Difficulty preset table, one entry per `Difficulty`.
+type DifficultyTable struct {
+	Easy   DifficultyTuning `json:"easy"`
+	Normal DifficultyTuning `json:"normal"`
+	Hard   DifficultyTuning `json:"hard"`
+	Arcade DifficultyTuning `json:"arcade"`
+}
+
+// How the adaptive difficulty rates the player and how far it can adjust the
+// spawn rate and asteroid speed.
+type AdaptiveTuning struct {
+	Interval     float32 `json:"interval"`      // Seconds between adjustments.
+	SurvivalGoal float32 `json:"survival_goal"` // Seconds alive that count as full marks for survival.
+	DeathPenalty float32 `json:"death_penalty"` // Rating lost for each death since the last adjustment.
+	TargetLow    float32 `json:"target_low"`    // Ratings below this make the game easier.
+	TargetHigh   float32 `json:"target_high"`   // Ratings above this make the game harder.
+	Step         float32 `json:"step"`          // Change in the adjustment each interval.
+	MinScale     float32 `json:"min_scale"`     // Lowest spawn rate and speed multiplier.
+	MaxScale     float32 `json:"max_scale"`     // Highest spawn rate and speed multiplier.
+}
+
+// Rules for local co-op with more than one ship.
+type CoopTuning struct {
+	FriendlyFire bool    `json:"friendly_fire"` // Whether players' projectiles can destroy each other's ships.
+	ReviveRadius float32 `json:"revive_radius"` // Distance from a downed ship that a partner has to stay within.
+	ReviveTime   float32 `json:"revive_time"`   // Seconds a partner has to stay nearby to revive a downed ship.
+}
+
+// Rules for versus matches between local players.
+type VersusTuning struct {
+	WinCondition string  `json:"win_condition"` // "last_standing" or "kills".
+	KillTarget   int     `json:"kill_target"`   // Kills needed to win a round when playing for kills.
+	RoundsToWin  int     `json:"rounds_to_win"` // Rounds needed to win the match.
+	RespawnTime  float32 `json:"respawn_time"`  // Seconds that a ship stays dead when playing for kills.
+	RoundBreak   float32 `json:"round_break"`   // Seconds that the scoreboard is shown for between rounds.
+	Cover        int     `json:"cover"`         // Large asteroids placed around the playfield at the start of every round.
+}
+
+type Tuning struct {
+	Ship                  ShipTuning       `json:"ship"`
+	Asteroids             AsteroidTable    `json:"asteroids"`
+	Materials             MaterialTable    `json:"materials"`
+	Lives                 LivesTuning      `json:"lives"`
+	PowerUps              PowerUpTuning    `json:"power_ups"`
+	Weapons               WeaponTable      `json:"weapons"`
+	Boss                  BossTuning       `json:"boss"`
+	Hazards               HazardTuning     `json:"hazards"`
+	Combo                 ComboTuning      `json:"combo"`
+	Difficulties          DifficultyTable  `json:"difficulties"`
+	Adaptive              AdaptiveTuning   `json:"adaptive"`
+	TimeAttack            TimeAttackTuning `json:"time_attack"`
+	Survival              SurvivalTuning   `json:"survival"`
+	Coop                  CoopTuning       `json:"coop"`
+	Versus                VersusTuning     `json:"versus"`
+	AsteroidSpawnInterval float32          `json:"asteroid_spawn_interval"` // Seconds between asteroid spawns.
+}
+
+// The tuning currently used by the game, as it was loaded.
+var current = Default()
+
+// Keys which older tuning files may still have but which no longer do
+// anything. They are dropped when loading instead of being rejected as
+// unknown, so that files written by older versions keep working.
+var removedKeys = []string{
+	"bullet_cooldown", // Replaced by the per-weapon cooldowns.
+}
+
+// Returns the default tuning values. These are used when no tuning file exists
+// and for any values missing from the tuning file.
+func Default() Tuning {
+	return Tuning{
+		Ship: ShipTuning{
+			RotationSpeed: 0.05,
+			Accel:         0.10,
+			Decel:         0.01,
+			MinVel:        2.0,
+			MaxVel:        5.0,
+			Drag:          0.01,
+		},
+		Asteroids: AsteroidTable{
+			// Smaller asteroids are harder to hit, so they give more points.
+			Small:  AsteroidTuning{MinRadius: 0, MaxRadius: 0.5, Speed: 3, Hitbox: 10, Health: 1, Score: 100},
+			Medium: AsteroidTuning{MinRadius: 0.5, MaxRadius: 1, Speed: 2, Hitbox: 25, Health: 2, Score: 50},
+			Large:  AsteroidTuning{MinRadius: 1, MaxRadius: 1.5, Speed: 1, Hitbox: 40, Health: 3, Score: 20},
+		},
+		Materials: MaterialTable{
+			Rock:      MaterialTuning{Weight: 10, Health: 1, Score: 1},
+			Metallic:  MaterialTuning{Weight: 2, Health: 3, Score: 2},
+			Explosive: MaterialTuning{Weight: 1.5, Health: 1, Score: 1.5},
+			Ice:       MaterialTuning{Weight: 2, Health: 1, Score: 1.2},
+			Magnetic:  MaterialTuning{Weight: 1, Health: 2, Score: 2},
+			Crystal:   MaterialTuning{Weight: 1, Health: 2, Score: 1},
+		},
+		Lives: LivesTuning{
+			Starting:   3,
+			Max:        5,
+			Thresholds: []uint64{10000},
+			Every:      10000,
+		},
+		PowerUps: PowerUpTuning{
+			DropChance:     0.1,
+			Lifetime:       10,
+			EffectDuration: 8,
+			MaxShields:     3,
+		},
+		Weapons: WeaponTable{
+			Blaster: WeaponTuning{Cooldown: 0.15, Damage: 1, Speed: 4, Heat: 0.1, Cooling: 0.4},
+			Spread:  WeaponTuning{Cooldown: 0.4, Damage: 1, Speed: 4, Heat: 0.25, Cooling: 0.35},
+			Laser:   WeaponTuning{Cooldown: 0.15, Damage: 1, Heat: 0.06, Cooling: 0.3},
+			Homing:  WeaponTuning{Cooldown: 0.6, Damage: 2, Speed: 3, Heat: 0.3, Cooling: 0.3},
+			Charge:  WeaponTuning{Cooldown: 0.3, Damage: 5, Speed: 5, Heat: 0.4, Cooling: 0.5},
+		},
+		Boss: BossTuning{
+			Interval:        120,
+			WeakPointHealth: 10,
+			Score:           5000,
+		},
+		// Hazards are only placed in the modes which are built around
+		// surviving chaos or practising, so that classic plays as it always
+		// has.
+		Hazards: HazardTuning{
+			Placements:   []HazardPlacement{},
+			GravityWells: 0,
+			BlackHoles:   0,
+			Modes: map[string]HazardCounts{
+				"survival": {GravityWells: 1, BlackHoles: 1},
+				"zen":      {GravityWells: 1, BlackHoles: 1},
+			},
+			WellStrength:      0.08,
+			BlackHoleStrength: 0.15,
+		},
+		Combo: ComboTuning{
+			Modes:             map[string]ComboTuning{},
+			Enabled:           true,
+			Window:            1.5,
+			HitsPerStep:       5,
+			MaxMultiplier:     8,
+			AccuracyShots:     20,
+			AccuracyThreshold: 0.6,
+			AccuracyBonus:     500,
+			StreakInterval:    60,
+			StreakBonus:       1000,
+		},
+		Difficulties: DifficultyTable{
+			Easy:   DifficultyTuning{SpawnInterval: 1.5, Speed: SizeScale{0.75, 0.75, 0.75}, Health: 0.5, Lives: 5},
+			Normal: DifficultyTuning{SpawnInterval: 1, Speed: SizeScale{1, 1, 1}, Health: 1, Lives: 3},
+			Hard:   DifficultyTuning{SpawnInterval: 0.7, Speed: SizeScale{1.3, 1.2, 1.2}, Health: 1.5, Lives: 2},
+			// Arcade is fast and frantic, but every rock breaks in one hit.
+			Arcade: DifficultyTuning{SpawnInterval: 0.5, Speed: SizeScale{1.5, 1.5, 1.5}, Health: 0, Lives: 3},
+		},
+		Adaptive: AdaptiveTuning{
+			Interval:     20,
+			SurvivalGoal: 90,
+			DeathPenalty: 0.3,
+			TargetLow:    0.35,
+			TargetHigh:   0.65,
+			Step:         0.1,
+			MinScale:     0.6,
+			MaxScale:     1.6,
+		},
+		TimeAttack: TimeAttackTuning{
+			Duration:     180,
+			DeathPenalty: 10,
+		},
+		Survival: SurvivalTuning{
+			SpawnGrowth:     0.25,
+			SpeedGrowth:     0.1,
+			MaxSpawnRate:    4,
+			MaxSpeed:        2,
+			StormInterval:   45,
+			StormSize:       8,
+			StormGrowth:     2,
+			PointsPerSecond: 10,
+		},
+		Coop: CoopTuning{
+			FriendlyFire: false,
+			ReviveRadius: 60,
+			ReviveTime:   3,
+		},
+		Versus: VersusTuning{
+			WinCondition: "last_standing",
+			KillTarget:   5,
+			RoundsToWin:  3,
+			RespawnTime:  2,
+			RoundBreak:   5,
+			Cover:        4,
+		},
+		AsteroidSpawnInterval: 2.5,
+	}
+}
+
+// Keys of the game modes which the tuning can set values for, matching the
+// `--mode` flag.
+var MODE_KEYS = []string{"classic", "time-attack", "survival", "daily", "zen", "versus"}
+
+// Returns the combo tuning of a game mode, given by its key.
+func (c ComboTuning) ForMode(mode string) ComboTuning {
+	if combo, ok := c.Modes[mode]; ok {
+		return combo
+	}
+	return c
+}
+
+// Checks the combo tuning found at `path` in the tuning file.
+func (c ComboTuning) validate(path string) error {
+	var errs []error
+	if c.Window <= 0 || c.HitsPerStep <= 0 || c.MaxMultiplier < 1 {
+		errs = append(errs, fmt.Errorf("%[1]s.window and %[1]s.hits_per_step must be positive and %[1]s.max_multiplier >= 1", path))
+	}
+	if c.AccuracyShots <= 0 || c.AccuracyThreshold < 0 || c.AccuracyThreshold > 1 {
+		errs = append(errs, fmt.Errorf("%[1]s.accuracy_shots must be positive and %[1]s.accuracy_threshold in [0, 1]", path))
+	}
+	if c.StreakInterval <= 0 {
+		errs = append(errs, fmt.Errorf("%s.streak_interval must be positive", path))
+	}
+	return errors.Join(errs...)
+}
+
+// Reads the combo block, filling in each mode's combo tuning from the block
+// itself before applying the values that the mode sets. Unknown keys are
+// rejected, as they are in the rest of the tuning file.
+func (c *ComboTuning) UnmarshalJSON(data []byte) error {
+	// The plain type has the same fields without this method.
+	type plain ComboTuning
+	var block struct {
+		plain
+		Modes map[string]json.RawMessage `json:"modes"`
+	}
+	block.plain = plain(*c)
+	block.plain.Modes = nil
+	if err := decodeStrict(data, &block); err != nil {
+		return err
+	}
+
+	modes := c.Modes
+	if block.Modes != nil {
+		modes = map[string]ComboTuning{}
+		for mode, data := range block.Modes {
+			combo := plain(block.plain)
+			if err := decodeStrict(data, &combo); err != nil {
+				return fmt.Errorf("combo.modes.%s: %w", mode, err)
+			}
+			if combo.Modes != nil {
+				return fmt.Errorf("combo.modes.%s can't have modes of its own", mode)
+			}
+			modes[mode] = ComboTuning(combo)
+		}
+	}
+
+	*c = ComboTuning(block.plain)
+	c.Modes = modes
+	return nil
+}
+
+// Decodes JSON into `v`, rejecting unknown keys.
+func decodeStrict(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// Returns the number of hazards placed at random positions in a game mode,
+// given by its key.
+func (h HazardTuning) Counts(mode string) HazardCounts {
+	if counts, ok := h.Modes[mode]; ok {
+		return counts
+	}
+	return HazardCounts{GravityWells: h.GravityWells, BlackHoles: h.BlackHoles}
+}
+
+// Returns the score at which the nth extra life (starting from 0) is awarded,
+// or false if no more extra lives are awarded.
+func (l LivesTuning) ExtraLifeThreshold(n int) (uint64, bool) {
+	if n < len(l.Thresholds) {
+		return l.Thresholds[n], true
+	}
+	if l.Every == 0 {
+		return 0, false
+	}
+
+	last := uint64(0)
+	if len(l.Thresholds) > 0 {
+		last = l.Thresholds[len(l.Thresholds)-1]
+	}
+	return last + uint64(n-len(l.Thresholds)+1)*l.Every, true
+}
+
+// Returns the tuning currently used by the game, with the difficulty and any
+// adaptive adjustment applied.
+func Get() Tuning {
+	return effective
+}
+
+// Returns the tuning as it was loaded, before the difficulty is applied.
+func Base() Tuning {
+	return current
+}
+
+// Replaces the tuning currently used by the game.
+func Set(tuning Tuning) {
+	current = tuning
+	refresh()
+}
+
+// Checks that the tuning values are within sensible ranges. An invalid tuning
+// is never applied to the game.
+func (t Tuning) Validate() error {
+	var errs []error
+
+	if t.Ship.RotationSpeed <= 0 {
+		errs = append(errs, errors.New("ship.rotation_speed must be positive"))
+	}
+	if t.Ship.Accel < 0 || t.Ship.Decel < 0 || t.Ship.Decel >= 1 {
+		errs = append(errs, errors.New("ship.accel must be >= 0 and ship.decel in [0, 1)"))
+	}
+	if t.Ship.MinVel < 0 || t.Ship.MaxVel <= 0 || t.Ship.MinVel > t.Ship.MaxVel {
+		errs = append(errs, errors.New("ship.min_vel must be in [0, ship.max_vel]"))
+	}
+	if t.Ship.Drag < 0 || t.Ship.Drag >= 1 {
+		errs = append(errs, errors.New("ship.drag must be in [0, 1)"))
+	}
+
+	sizes := map[string]AsteroidTuning{
+		"small":  t.Asteroids.Small,
+		"medium": t.Asteroids.Medium,
+		"large":  t.Asteroids.Large,
+	}
+	for _, name := range []string{"small", "medium", "large"} {
+		a := sizes[name]
+		if a.MinRadius < 0 || a.MaxRadius <= 0 || a.MinRadius > a.MaxRadius {
+			errs = append(errs, fmt.Errorf("asteroids.%s radius must satisfy 0 <= min_radius <= max_radius", name))
+		}
+		if a.Speed <= 0 || a.Hitbox <= 0 || a.Health <= 0 {
+			errs = append(errs, fmt.Errorf("asteroids.%s speed, hitbox and health must be positive", name))
+		}
+	}
+
+	materials := map[string]MaterialTuning{
+		"rock":      t.Materials.Rock,
+		"metallic":  t.Materials.Metallic,
+		"explosive": t.Materials.Explosive,
+		"ice":       t.Materials.Ice,
+		"magnetic":  t.Materials.Magnetic,
+		"crystal":   t.Materials.Crystal,
+	}
+	totalWeight := float32(0)
+	for _, name := range []string{"rock", "metallic", "explosive", "ice", "magnetic", "crystal"} {
+		m := materials[name]
+		if m.Weight < 0 || m.Health <= 0 || m.Score < 0 {
+			errs = append(errs, fmt.Errorf("materials.%s weight and score must be >= 0 and health positive", name))
+		}
+		totalWeight += m.Weight
+	}
+	if totalWeight <= 0 {
+		errs = append(errs, errors.New("at least one material must have a positive weight"))
+	}
+
+	if t.Lives.Starting == 0 || t.Lives.Max < t.Lives.Starting {
+		errs = append(errs, errors.New("lives.starting must be in [1, lives.max]"))
+	}
+	for i := 1; i < len(t.Lives.Thresholds); i++ {
+		if t.Lives.Thresholds[i] <= t.Lives.Thresholds[i-1] {
+			errs = append(errs, errors.New("lives.thresholds must be in increasing order"))
+			break
+		}
+	}
+
+	if t.PowerUps.DropChance < 0 || t.PowerUps.DropChance > 1 {
+		errs = append(errs, errors.New("power_ups.drop_chance must be in [0, 1]"))
+	}
+	if t.PowerUps.Lifetime <= 0 || t.PowerUps.EffectDuration <= 0 {
+		errs = append(errs, errors.New("power_ups.lifetime and power_ups.effect_duration must be positive"))
+	}
+	if t.PowerUps.MaxShields < 0 {
+		errs = append(errs, errors.New("power_ups.max_shields must be >= 0"))
+	}
+
+	weapons := map[string]WeaponTuning{
+		"blaster": t.Weapons.Blaster,
+		"spread":  t.Weapons.Spread,
+		"laser":   t.Weapons.Laser,
+		"homing":  t.Weapons.Homing,
+		"charge":  t.Weapons.Charge,
+	}
+	for _, name := range []string{"blaster", "spread", "laser", "homing", "charge"} {
+		w := weapons[name]
+		if w.Cooldown < 0 || w.Damage <= 0 || w.Speed < 0 {
+			errs = append(errs, fmt.Errorf("weapons.%s cooldown and speed must be >= 0 and damage positive", name))
+		}
+		if w.Heat < 0 || w.Cooling <= 0 {
+			errs = append(errs, fmt.Errorf("weapons.%s heat must be >= 0 and cooling positive", name))
+		}
+	}
+
+	if t.Boss.Interval <= 0 || t.Boss.WeakPointHealth <= 0 {
+		errs = append(errs, errors.New("boss.interval and boss.weak_point_health must be positive"))
+	}
+
+	for i, placement := range t.Hazards.Placements {
+		if placement.Kind != "gravity_well" && placement.Kind != "black_hole" {
+			errs = append(errs, fmt.Errorf("hazards.placements[%d].kind must be \"gravity_well\" or \"black_hole\"", i))
+		}
+	}
+	if t.Hazards.GravityWells < 0 || t.Hazards.BlackHoles < 0 {
+		errs = append(errs, errors.New("hazards.gravity_wells and hazards.black_holes must be >= 0"))
+	}
+	for mode, counts := range t.Hazards.Modes {
+		if !slices.Contains(MODE_KEYS, mode) {
+			errs = append(errs, fmt.Errorf("hazards.modes.%s is not a game mode, expected one of %s", mode, strings.Join(MODE_KEYS, ", ")))
+		}
+		if counts.GravityWells < 0 || counts.BlackHoles < 0 {
+			errs = append(errs, fmt.Errorf("hazards.modes.%s gravity_wells and black_holes must be >= 0", mode))
+		}
+	}
+	if t.Hazards.WellStrength < 0 || t.Hazards.BlackHoleStrength < 0 {
+		errs = append(errs, errors.New("hazards.well_strength and hazards.black_hole_strength must be >= 0"))
+	}
+
+	errs = append(errs, t.Combo.validate("combo"))
+	for mode, combo := range t.Combo.Modes {
+		if !slices.Contains(MODE_KEYS, mode) {
+			errs = append(errs, fmt.Errorf("combo.modes.%s is not a game mode, expected one of %s", mode, strings.Join(MODE_KEYS, ", ")))
+		}
+		errs = append(errs, combo.validate("combo.modes."+mode))
+	}
+
+	difficulties := map[string]DifficultyTuning{
+		"easy":   t.Difficulties.Easy,
+		"normal": t.Difficulties.Normal,
+		"hard":   t.Difficulties.Hard,
+		"arcade": t.Difficulties.Arcade,
+	}
+	for _, name := range []string{"easy", "normal", "hard", "arcade"} {
+		d := difficulties[name]
+		if d.SpawnInterval <= 0 || d.Speed.Small <= 0 || d.Speed.Medium <= 0 || d.Speed.Large <= 0 {
+			errs = append(errs, fmt.Errorf("difficulties.%s spawn_interval and speed must be positive", name))
+		}
+		if d.Health < 0 || d.Lives < 1 {
+			errs = append(errs, fmt.Errorf("difficulties.%s health must be >= 0 and lives >= 1", name))
+		}
+	}
+
+	if t.Adaptive.Interval <= 0 || t.Adaptive.SurvivalGoal <= 0 || t.Adaptive.Step < 0 {
+		errs = append(errs, errors.New("adaptive.interval and adaptive.survival_goal must be positive and adaptive.step >= 0"))
+	}
+	if t.Adaptive.TargetLow > t.Adaptive.TargetHigh {
+		errs = append(errs, errors.New("adaptive.target_low must be <= adaptive.target_high"))
+	}
+	if t.Adaptive.MinScale <= 0 || t.Adaptive.MinScale > 1 || t.Adaptive.MaxScale < 1 {
+		errs = append(errs, errors.New("adaptive.min_scale must be in (0, 1] and adaptive.max_scale >= 1"))
+	}
+
+	if t.TimeAttack.Duration <= 0 || t.TimeAttack.DeathPenalty < 0 {
+		errs = append(errs, errors.New("time_attack.duration must be positive and time_attack.death_penalty >= 0"))
+	}
+
+	if t.Survival.SpawnGrowth < 0 || t.Survival.SpeedGrowth < 0 || t.Survival.MaxSpawnRate < 1 || t.Survival.MaxSpeed < 1 {
+		errs = append(errs, errors.New("survival growth must be >= 0 and survival.max_spawn_rate and survival.max_speed >= 1"))
+	}
+	if t.Survival.StormInterval <= 0 || t.Survival.StormSize < 0 || t.Survival.StormGrowth < 0 || t.Survival.PointsPerSecond < 0 {
+		errs = append(errs, errors.New("survival.storm_interval must be positive and the storm sizes and points_per_second >= 0"))
+	}
+
+	if t.Coop.ReviveRadius <= 0 || t.Coop.ReviveTime < 0 {
+		errs = append(errs, errors.New("coop.revive_radius must be positive and coop.revive_time >= 0"))
+	}
+
+	if t.Versus.WinCondition != "last_standing" && t.Versus.WinCondition != "kills" {
+		errs = append(errs, errors.New("versus.win_condition must be \"last_standing\" or \"kills\""))
+	}
+	if t.Versus.KillTarget <= 0 || t.Versus.RoundsToWin <= 0 || t.Versus.RespawnTime <= 0 {
+		errs = append(errs, errors.New("versus.kill_target, versus.rounds_to_win and versus.respawn_time must be positive"))
+	}
+	if t.Versus.RoundBreak < 0 || t.Versus.Cover < 0 {
+		errs = append(errs, errors.New("versus.round_break and versus.cover must be >= 0"))
+	}
+
+	if t.AsteroidSpawnInterval <= 0 {
+		errs = append(errs, errors.New("asteroid_spawn_interval must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Reads and validates a tuning file. Values missing from the file fall back to
+// their defaults and unknown keys are rejected so that typos are caught, apart
+// from keys that older versions used to write.
+func Load(path string) (Tuning, error) {
+	tuning := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return tuning, err
+	}
+
+	keys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return tuning, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for _, key := range removedKeys {
+		delete(keys, key)
+	}
+	if data, err = json.Marshal(keys); err != nil {
+		return tuning, err
+	}
+
+	if err := decodeStrict(data, &tuning); err != nil {
+		return tuning, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	if err := tuning.Validate(); err != nil {
+		return tuning, err
+	}
+
+	return tuning, nil
+}
+
+// Writes the tuning to a file as indented JSON.
+func Save(path string, tuning Tuning) error {
+	data, err := json.MarshalIndent(tuning, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Returns a human readable line for every value that differs between the two
+// tunings, e.g. "ship.max_vel: 5 -> 6".
+func Diff(old Tuning, new Tuning) []string {
+	changes := []string{}
+	diffValues("", reflect.ValueOf(old), reflect.ValueOf(new), &changes)
+	return changes
+}
+
+// Recursively walks the two structs and records the values which differ using
+// their JSON names.
+func diffValues(prefix string, old reflect.Value, new reflect.Value, changes *[]string) {
+	if old.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+			*changes = append(*changes, fmt.Sprintf("%s: %v -> %v", prefix, old.Interface(), new.Interface()))
+		}
+		return
+	}
+
+	for i := 0; i < old.NumField(); i++ {
+		name := strings.Split(old.Type().Field(i).Tag.Get("json"), ",")[0]
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		diffValues(name, old.Field(i), new.Field(i), changes)
+	}
+}
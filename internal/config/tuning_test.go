@@ -0,0 +1,274 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Writes `contents` to a tuning file in a temporary directory and returns its
+// path.
+func writeTuning(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tuning.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// The tuning file as the first version of the watcher wrote it, before the
+// bullet cooldown was replaced by the weapon table.
+const oldTuningFile = `{
+  "ship": {
+    "rotation_speed": 0.05,
+    "accel": 0.1,
+    "decel": 0.01,
+    "min_vel": 2,
+    "max_vel": 5,
+    "drag": 0.01
+  },
+  "asteroids": {
+    "small": {"min_radius": 0, "max_radius": 0.5, "speed": 3, "hitbox": 10, "health": 1, "score": 100},
+    "medium": {"min_radius": 0.5, "max_radius": 1, "speed": 2, "hitbox": 25, "health": 2, "score": 50},
+    "large": {"min_radius": 1, "max_radius": 1.5, "speed": 1, "hitbox": 40, "health": 3, "score": 20}
+  },
+  "asteroid_spawn_interval": 2,
+  "bullet_cooldown": 1
+}
+`
+
+func TestLoadOldTuningFile(t *testing.T) {
+	tuning, err := Load(writeTuning(t, oldTuningFile))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if tuning.AsteroidSpawnInterval != 2 {
+		t.Errorf("asteroid_spawn_interval = %v, want 2", tuning.AsteroidSpawnInterval)
+	}
+	if tuning.Weapons != Default().Weapons {
+		t.Errorf("weapons = %+v, want the defaults", tuning.Weapons)
+	}
+}
+
+func TestWatcherReloadsOldTuningFile(t *testing.T) {
+	path := writeTuning(t, oldTuningFile)
+	watcher, err := NewWatcher(path)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	defer Set(Default())
+
+	// Touching the file makes the watcher reload it.
+	if err := os.Chtimes(path, watcher.modTime.Add(1), watcher.modTime.Add(1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watcher.Poll(WATCH_INTERVAL); err != nil {
+		t.Errorf("Poll() error = %v", err)
+	}
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("Default().Validate() = %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{"empty object keeps defaults", `{}`, false},
+		{"partial file", `{"ship": {"max_vel": 6}}`, false},
+		{"unknown key", `{"ship": {"max_velocity": 6}}`, true},
+		{"unknown top level key", `{"bullet_speed": 6}`, true},
+		{"invalid value", `{"ship": {"rotation_speed": -1}}`, true},
+		{"not json", `ship.max_vel = 6`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Load(writeTuning(t, test.file))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Load() error = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadKeepsMissingDefaults(t *testing.T) {
+	tuning, err := Load(writeTuning(t, `{"ship": {"max_vel": 6}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Default()
+	want.Ship.MaxVel = 6
+	if diff := Diff(want, tuning); len(diff) > 0 {
+		t.Errorf("Load() differs from the defaults: %v", diff)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuning.json")
+	if err := Save(path, Default()); err != nil {
+		t.Fatal(err)
+	}
+	tuning, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := Diff(Default(), tuning); len(diff) > 0 {
+		t.Errorf("round trip changed %v", diff)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	edited := Default()
+	edited.Ship.MaxVel = 6
+	edited.Asteroids.Small.Score = 150
+
+	changes := Diff(Default(), edited)
+	want := []string{"ship.max_vel: 5 -> 6", "asteroids.small.score: 100 -> 150"}
+	if len(changes) != len(want) {
+		t.Fatalf("Diff() = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Diff()[%d] = %q, want %q", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuning.json")
+	watcher, err := NewWatcher(path)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	defer Set(Default())
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("default tuning file not written: %v", err)
+	}
+
+	edit := func(contents string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		watcher.modTime = watcher.modTime.Add(-1)
+	}
+
+	// Nothing is reloaded before the poll interval has passed.
+	edit(`{"ship": {"max_vel": 6}}`)
+	if changes, _ := watcher.Poll(WATCH_INTERVAL / 2); changes != nil {
+		t.Errorf("Poll() before the interval = %v", changes)
+	}
+	changes, err := watcher.Poll(WATCH_INTERVAL / 2)
+	if err != nil || len(changes) != 1 || Base().Ship.MaxVel != 6 {
+		t.Errorf("Poll() = %v, %v with max_vel %v, want the edit applied", changes, err, Base().Ship.MaxVel)
+	}
+
+	// Invalid edits are reported and the current tuning is kept.
+	edit(`{"ship": {"max_vel": -1}}`)
+	if _, err := watcher.Poll(WATCH_INTERVAL); err == nil {
+		t.Error("Poll() accepted an invalid edit")
+	}
+	if Base().Ship.MaxVel != 6 {
+		t.Errorf("max_vel = %v after an invalid edit, want 6", Base().Ship.MaxVel)
+	}
+}
+
+func TestExtraLifeThreshold(t *testing.T) {
+	tests := []struct {
+		name  string
+		lives LivesTuning
+		n     int
+		want  uint64
+		ok    bool
+	}{
+		{"first threshold", LivesTuning{Thresholds: []uint64{5000, 20000}, Every: 10000}, 0, 5000, true},
+		{"last threshold", LivesTuning{Thresholds: []uint64{5000, 20000}, Every: 10000}, 1, 20000, true},
+		{"every after the thresholds", LivesTuning{Thresholds: []uint64{5000, 20000}, Every: 10000}, 3, 40000, true},
+		{"every without thresholds", LivesTuning{Every: 10000}, 2, 30000, true},
+		{"no more without every", LivesTuning{Thresholds: []uint64{5000}}, 1, 0, false},
+	}
+	for _, test := range tests {
+		got, ok := test.lives.ExtraLifeThreshold(test.n)
+		if got != test.want || ok != test.ok {
+			t.Errorf("%s: ExtraLifeThreshold(%d) = %d, %v, want %d, %v", test.name, test.n, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestHazardCounts(t *testing.T) {
+	hazards := Default().Hazards
+	if counts := hazards.Counts("classic"); counts != (HazardCounts{}) {
+		t.Errorf("classic has %+v hazards by default, want none", counts)
+	}
+	if counts := hazards.Counts("survival"); counts.GravityWells == 0 || counts.BlackHoles == 0 {
+		t.Errorf("survival has %+v hazards by default, want some", counts)
+	}
+
+	hazards.GravityWells = 2
+	if counts := hazards.Counts("classic"); counts.GravityWells != 2 {
+		t.Errorf("classic has %+v hazards, want the counts for every mode", counts)
+	}
+}
+
+func TestLoadHazardModes(t *testing.T) {
+	tuning, err := Load(writeTuning(t, `{"hazards": {"modes": {"classic": {"black_holes": 3}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts := tuning.Hazards.Counts("classic"); counts != (HazardCounts{BlackHoles: 3}) {
+		t.Errorf("classic has %+v hazards, want 3 black holes", counts)
+	}
+
+	if _, err := Load(writeTuning(t, `{"hazards": {"modes": {"classic": {"black_holes": -1}}}}`)); err == nil {
+		t.Error("Load() accepted a negative hazard count")
+	}
+	if _, err := Load(writeTuning(t, `{"hazards": {"modes": {"survial": {"black_holes": 1}}}}`)); err == nil {
+		t.Error("Load() accepted hazard counts for a misspelled mode")
+	}
+}
+
+func TestComboForMode(t *testing.T) {
+	combo := Default().Combo
+	combo.Modes = map[string]ComboTuning{"zen": {Enabled: false}}
+	if got := combo.ForMode("zen"); got.Enabled {
+		t.Error("zen uses the main combo tuning, want its own")
+	}
+	if got := combo.ForMode("classic"); !got.Enabled || got.Window != combo.Window {
+		t.Errorf("classic got %+v, want the main combo tuning", got)
+	}
+}
+
+func TestLoadComboModes(t *testing.T) {
+	tuning, err := Load(writeTuning(t, `{"combo": {"window": 3, "modes": {"time-attack": {"max_multiplier": 8}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	combo := tuning.Combo.ForMode("time-attack")
+	if combo.MaxMultiplier != 8 {
+		t.Errorf("time-attack max multiplier is %d, want 8", combo.MaxMultiplier)
+	}
+	if combo.Window != 3 || combo.HitsPerStep != Default().Combo.HitsPerStep {
+		t.Errorf("time-attack got %+v, want the values it doesn't set from the combo block", combo)
+	}
+	if combo := tuning.Combo.ForMode("classic"); combo.MaxMultiplier != Default().Combo.MaxMultiplier {
+		t.Errorf("classic max multiplier is %d, want the default", combo.MaxMultiplier)
+	}
+
+	for name, contents := range map[string]string{
+		"an unknown key":   `{"combo": {"modes": {"zen": {"multiplier": 2}}}}`,
+		"an invalid value": `{"combo": {"modes": {"zen": {"window": 0}}}}`,
+		"nested modes":     `{"combo": {"modes": {"zen": {"modes": {}}}}}`,
+		"a misspelled key": `{"combo": {"modes": {"time_attack": {"window": 2}}}}`,
+	} {
+		if _, err := Load(writeTuning(t, contents)); err == nil {
+			t.Errorf("Load() accepted a combo mode with %s", name)
+		}
+	}
+}
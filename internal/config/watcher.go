@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// How often the tuning file is checked for changes, in seconds.
+const WATCH_INTERVAL = 0.5
+
+// Watches a tuning file by polling its modification time and reloads the
+// tuning whenever the file changes.
+type Watcher struct {
+	Path    string
+	modTime time.Time
+	timer   float32
+}
+
+// Creates a watcher for the tuning file at `path`. If the file does not exist
+// yet, it is created with the default tuning so there is something to edit.
+// Otherwise the file is loaded and applied straight away.
+func NewWatcher(path string) (*Watcher, error) {
+	watcher := &Watcher{Path: path}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := Save(path, Default()); err != nil {
+			return watcher, err
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return watcher, err
+	}
+	watcher.modTime = info.ModTime()
+
+	tuning, err := Load(path)
+	if err != nil {
+		return watcher, err
+	}
+	Set(tuning)
+
+	return watcher, nil
+}
+
+// Advances the poll timer by `dt` seconds and reloads the tuning file if it
+// has been modified. Returns the list of values that changed, or an error if
+// the edited file is invalid, in which case the current tuning is kept.
+func (w *Watcher) Poll(dt float32) ([]string, error) {
+	w.timer += dt
+	if w.timer < WATCH_INTERVAL {
+		return nil, nil
+	}
+	w.timer = 0
+
+	info, err := os.Stat(w.Path)
+	if err != nil || info.ModTime().Equal(w.modTime) {
+		return nil, nil
+	}
+	w.modTime = info.ModTime()
+
+	tuning, err := Load(w.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := Diff(Base(), tuning)
+	Set(tuning)
+
+	return changes, nil
+}
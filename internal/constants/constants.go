@@ -4,14 +4,29 @@ const (
 	SCREEN_WIDTH  = 1280 // 1280
 	SCREEN_HEIGHT = 960  // 960
 
+	// Simulation ticks per second. The game advances by exactly one tick every
+	// frame so that a game plays out the same given the same inputs.
+	TICK_RATE = 120
+
 	// Drawing parameters
 	THICKNESS = 2.0
 	SCALE     = 38.0
 
 	// Spawn parameters
-	ASTEROID_SPAWN_INTERVAL = 2.5 // Asteroids spawn every 2.5 seconds
-	SPAWN_MARGIN            = 100
+	SPAWN_MARGIN = 100
 
 	// Bullet constants
 	BULLET_LENGTH = 30
+
+	// Gameplay tuning file which is watched and reloaded while the game runs.
+	TUNING_FILE = "tuning.json"
+
+	// Persisted key bindings.
+	BINDINGS_FILE = "bindings.json"
+
+	// Best scores of each game mode.
+	SCORES_FILE = "highscores.json"
+
+	// Directory holding the result and replay of every daily challenge played.
+	DAILY_DIR = "daily"
 )
@@ -1,26 +1,17 @@
 package entities
 
 import (
+	"asteroids/internal/config"
 	"asteroids/internal/constants"
 	"asteroids/internal/utils"
 	"math"
-	"math/rand/v2"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
 	SPAWN_MARGIN = constants.SPAWN_MARGIN
-
-	// Hitbox sizes for the asteroids.
-	SMALL_HITBOX = 10
-	MED_HITBOX   = 25
-	LARGE_HITBOX = 40
-
-	// Scores that the asteroids will give when destroyed.
-	SMALL_SCORE = 100 // Smaller asteroids are harder to hit, so they give more points.
-	MED_SCORE   = 50
-	LARGE_SCORE = 20
+	STORM_SPREAD = 0.35 // Largest angle in radians that storm asteroids stray from heading straight in.
 )
 
 type AsteroidSize int
@@ -31,7 +22,12 @@ const (
 	Large
 )
 
+func (size AsteroidSize) String() string {
+	return [...]string{"Small", "Medium", "Large"}[size]
+}
+
 type Asteroid struct {
+	ID     uint32       // Unique identifier of the asteroid.
 	Pos    rl.Vector2   // Position of the asteroid.
 	Vel    rl.Vector2   // Velocity of the asteroid.
 	Dir    rl.Vector2   // This will point towards the ship's location when it first spawned.
@@ -40,6 +36,8 @@ type Asteroid struct {
 	Hitbox int          // Radius of the hitbox of the asteroid. Hitbox is in the shape of a circle.
 	Health int          // Health of the asteroid. Asteroids will break into smaller asteroids when health reaches 0.
 	Score  uint64       // Score that the player will receive when the asteroid is destroyed.
+
+	Material Material // What the asteroid is made of.
 }
 
 // Generates a random n-sided polygon shape generating randomized points around
@@ -60,10 +58,10 @@ func generateAsteroidShape(
 		targetAngle := angleStep * float64(i)
 
 		// Add some random angle variation for the irregularity.
-		angle := targetAngle + (rand.Float64()-0.5)*angleStep*0.25
+		angle := targetAngle + (utils.Rand.Float64()-0.5)*angleStep*0.25
 
 		// Pick a random radius between the given minimum and maximum radius.
-		radius := minRadius + rand.Float64()*(maxRadius-minRadius)
+		radius := minRadius + utils.Rand.Float64()*(maxRadius-minRadius)
 
 		// Convert into cartesian coordinates.
 		x := math.Cos(angle) * radius
@@ -74,137 +72,214 @@ func generateAsteroidShape(
 	return points
 }
 
-func newAsteroid(pos rl.Vector2, dir rl.Vector2, size AsteroidSize) Asteroid {
-	var minRadius, maxRadius float64
-	var velocity rl.Vector2
-	var hitbox, health int
-	var score uint64
+// Whether the asteroids' hitboxes are drawn around their outlines.
+var ShowHitboxes = true
+
+// Identifier given to the next asteroid that is created.
+var nextAsteroidID uint32 = 1
 
+// Returns the identifier that the next asteroid will be given. It is saved
+// along with the game so that a rewound game hands out the same identifiers.
+func NextAsteroidID() uint32 {
+	return nextAsteroidID
+}
+
+func SetNextAsteroidID(id uint32) {
+	nextAsteroidID = id
+}
+
+func newAsteroid(pos rl.Vector2, dir rl.Vector2, size AsteroidSize, material Material) Asteroid {
 	// Defining variables based on the size of the asteroid. Larger asteroids
-	// will have more health but less speed etc.
+	// will have more health but less speed etc. The size table comes from the
+	// tuning so it can be rebalanced while the game is running.
+	var tuning config.AsteroidTuning
+	table := config.Get().Asteroids
 	switch size {
 	case Small:
-		minRadius, maxRadius = 0, 0.5
-		velocity = rl.Vector2{X: 3, Y: 3}
-		hitbox = SMALL_HITBOX
-		health = 1
-		score = SMALL_SCORE
+		tuning = table.Small
 	case Medium:
-		minRadius, maxRadius = 0.5, 1
-		velocity = rl.Vector2{X: 2, Y: 2}
-		hitbox = MED_HITBOX
-		health = 2
-		score = MED_SCORE
+		tuning = table.Medium
 	case Large:
-		minRadius, maxRadius = 1, 1.5
-		velocity = rl.Vector2{X: 1, Y: 1}
-		hitbox = LARGE_HITBOX
-		health = 3
-		score = LARGE_SCORE
+		tuning = table.Large
 	}
 
 	// Generates points for the polygon shape of the asteroid.
 	const DEFAULT_NUM_SIDES = 11
-	points := generateAsteroidShape(DEFAULT_NUM_SIDES, minRadius, maxRadius)
+	points := generateAsteroidShape(DEFAULT_NUM_SIDES, tuning.MinRadius, tuning.MaxRadius)
+
+	// The material scales the health and score of the size table.
+	materialTuning := material.Tuning()
+	health := max(1, int(math.Round(float64(tuning.Health)*float64(materialTuning.Health))))
+	score := uint64(math.Round(float64(tuning.Score) * float64(materialTuning.Score)))
+
+	nextAsteroidID++
 
 	return Asteroid{
+		ID:     nextAsteroidID - 1,
 		Pos:    pos,
-		Vel:    velocity,
+		Vel:    rl.Vector2{X: tuning.Speed, Y: tuning.Speed},
 		Dir:    dir,
 		Points: points,
-		Hitbox: hitbox,
+		Hitbox: tuning.Hitbox,
 		Health: health,
 		Score:  score,
 		Size:   size,
+
+		Material: material,
 	}
 }
 
+// Edge of the window that asteroids can spawn from.
+type Edge int
+
+const (
+	Top Edge = iota
+	Bottom
+	Left
+	Right
+
+	NUM_EDGES = iota
+)
+
+func (edge Edge) String() string {
+	return [...]string{"Top", "Bottom", "Left", "Right"}[edge]
+}
+
+// Returns one of the window's edges at random.
+func RandomEdge() Edge {
+	return Edge(utils.Rand.IntN(NUM_EDGES))
+}
+
+// Returns the unit vector pointing from the edge into the window.
+func (edge Edge) Inwards() rl.Vector2 {
+	return [...]rl.Vector2{{X: 0, Y: 1}, {X: 0, Y: -1}, {X: 1, Y: 0}, {X: -1, Y: 0}}[edge]
+}
+
 // Generates spawn point coordinates for the asteroids. Spawn point coordinates
 // are contained to coordinates that are outside of the window dimensions. This
 // is so that the asteroids can spawn outside and float into view.
-func generateAsteroidSpawn() rl.Vector2 {
-	// Generate a random zone for the asteroid to spawn in.
-	zone := rand.IntN(4)
-
-	const (
-		// Defining zones for the asteroids to spawn in.
-		TOP   = 0
-		BOT   = 1
-		LEFT  = 2
-		RIGHT = 3
-	)
-
-	switch zone {
-	case TOP:
+func generateAsteroidSpawn(edge Edge) rl.Vector2 {
+	switch edge {
+	case Top:
 		x := utils.RandInRange(0, constants.SCREEN_WIDTH)
 		y := utils.RandInRange(-SPAWN_MARGIN, 0)
 		return rl.Vector2{X: x, Y: y}
-	case BOT:
+	case Bottom:
 		x := utils.RandInRange(0, constants.SCREEN_WIDTH)
 		y := utils.RandInRange(constants.SCREEN_HEIGHT, constants.SCREEN_HEIGHT+SPAWN_MARGIN)
 		return rl.Vector2{X: x, Y: y}
-	case LEFT:
+	case Left:
 		x := utils.RandInRange(-SPAWN_MARGIN, 0)
 		y := utils.RandInRange(0, constants.SCREEN_HEIGHT)
 		return rl.Vector2{X: x, Y: y}
-	case RIGHT:
+	case Right:
 		x := utils.RandInRange(constants.SCREEN_WIDTH, constants.SCREEN_WIDTH+SPAWN_MARGIN)
 		y := utils.RandInRange(0, constants.SCREEN_HEIGHT)
 		return rl.Vector2{X: x, Y: y}
 	default:
-		panic("unreachable: unexpected zone value")
+		panic("unreachable: unexpected edge value")
 	}
 }
 
-// Draws the asteroid at any given `pos`.
+// Draws the asteroid at any given `pos`. The outline is styled after the
+// asteroid's material.
 func DrawAsteroid(asteroid Asteroid) {
-	rl.DrawCircleLinesV(asteroid.Pos, float32(asteroid.Hitbox), rl.Yellow)
-	utils.DrawLines(asteroid.Pos, constants.SCALE, constants.THICKNESS, 0.0, asteroid.Points)
+	thickness := float32(constants.THICKNESS)
+	if asteroid.Material == Metallic {
+		thickness *= 1.5
+	}
+
+	if ShowHitboxes {
+		rl.DrawCircleLinesV(asteroid.Pos, float32(asteroid.Hitbox), rl.Yellow)
+	}
+	utils.DrawLinesColor(asteroid.Pos, constants.SCALE, thickness, 0.0, asteroid.Points, asteroid.Material.color())
+	drawMaterial(asteroid)
 }
 
 // Spawns an asteroid and returns the Asteroid struct to be appended into the
 // game state. Takes in the ship's position as the asteroid drifts towards
-// the ship when it spawns.
+// the ship when it spawns. The material is picked randomly using the spawn
+// weights from the tuning.
 func SpawnAsteroid(shipPos rl.Vector2, size AsteroidSize) Asteroid {
-	spawnPoint := generateAsteroidSpawn()
+	spawnPoint := generateAsteroidSpawn(RandomEdge())
+
+	asteroid := newAsteroid(
+		spawnPoint,
+		rl.Vector2Normalize(rl.Vector2Subtract(shipPos, spawnPoint)),
+		randomSize(size),
+		randomMaterial(),
+	)
+
+	return asteroid
+}
+
+// Spawns an asteroid of the given size at `pos`, drifting in a random
+// direction.
+func SpawnAsteroidAt(pos rl.Vector2, size AsteroidSize) Asteroid {
+	angle := utils.Rand.Float64() * 2 * math.Pi
+	dir := rl.Vector2{X: float32(math.Cos(angle)), Y: float32(math.Sin(angle))}
+	return newAsteroid(pos, dir, size, randomMaterial())
+}
 
-	// Randomly generate a size for the asteroid. This will only happen if no size
-	// is given.
-	// 40% chance for large, 40% chance for medium, 20% chance for small.
+// Randomly generate a size for the asteroid. This will only happen if no size
+// is given.
+// 40% chance for large, 40% chance for medium, 20% chance for small.
+func randomSize(size AsteroidSize) AsteroidSize {
 	if size == -1 {
 		size = Large
-		if rand.Float64() < 0.4 {
+		if utils.Rand.Float64() < 0.4 {
 			size = Medium
-		} else if rand.Float64() < 0.2 {
+		} else if utils.Rand.Float64() < 0.2 {
 			size = Small
 		}
 	}
+	return size
+}
 
-	asteroid := newAsteroid(
-		spawnPoint,
-		rl.Vector2Normalize(rl.Vector2Subtract(shipPos, spawnPoint)),
-		size,
-	)
-
-	return asteroid
+// Spawns a storm of asteroids which all come from the same edge and sweep
+// across the window, rather than heading for the ship.
+func SpawnStorm(edge Edge, count int) []Asteroid {
+	storm := []Asteroid{}
+	for range count {
+		spread := utils.RandInRange(-STORM_SPREAD, STORM_SPREAD)
+		dir := rl.Vector2Rotate(edge.Inwards(), spread)
+		storm = append(storm, newAsteroid(generateAsteroidSpawn(edge), dir, randomSize(-1), randomMaterial()))
+	}
+	return storm
 }
 
+// Returns the pieces that an asteroid breaks into when destroyed. Pieces keep
+// the material of the asteroid they came from. Ice shatters into many small,
+// fast shards instead of splitting in two.
 func SplitAsteroid(asteroid Asteroid) []Asteroid {
+	if asteroid.Material == Ice && asteroid.Size != Small {
+		shards := []Asteroid{}
+		for i := 0; i < ICE_SHARDS; i++ {
+			angle := (float64(i) + utils.Rand.Float64()*0.5) * 2 * math.Pi / ICE_SHARDS
+			dir := rl.Vector2{X: float32(math.Cos(angle)), Y: float32(math.Sin(angle))}
+
+			shard := newAsteroid(asteroid.Pos, dir, Small, Ice)
+			shard.Vel = rl.Vector2Scale(shard.Vel, ICE_SHARD_SPEED)
+			shards = append(shards, shard)
+		}
+		return shards
+	}
+
 	switch asteroid.Size {
 	case Large:
 		// Create two medium asteroids when a large asteroid is destroyed.
 		mediumAsteroids := []Asteroid{
 			// Asteroids will float in a random direction.
-			newAsteroid(asteroid.Pos, rl.Vector2{X: rand.Float32(), Y: rand.Float32()}, Medium),
-			newAsteroid(asteroid.Pos, rl.Vector2{X: rand.Float32(), Y: rand.Float32()}, Medium),
+			newAsteroid(asteroid.Pos, rl.Vector2{X: utils.Rand.Float32(), Y: utils.Rand.Float32()}, Medium, asteroid.Material),
+			newAsteroid(asteroid.Pos, rl.Vector2{X: utils.Rand.Float32(), Y: utils.Rand.Float32()}, Medium, asteroid.Material),
 		}
 		return mediumAsteroids
 	case Medium:
 		// Create two small asteroids when a medium asteroid is destroyed.
 		// Asteroids will float in a random direction.
 		smallAsteroids := []Asteroid{
-			newAsteroid(asteroid.Pos, rl.Vector2{X: rand.Float32(), Y: rand.Float32()}, Small),
-			newAsteroid(asteroid.Pos, rl.Vector2{X: rand.Float32(), Y: rand.Float32()}, Small),
+			newAsteroid(asteroid.Pos, rl.Vector2{X: utils.Rand.Float32(), Y: utils.Rand.Float32()}, Small, asteroid.Material),
+			newAsteroid(asteroid.Pos, rl.Vector2{X: utils.Rand.Float32(), Y: utils.Rand.Float32()}, Small, asteroid.Material),
 		}
 		return smallAsteroids
 	default:
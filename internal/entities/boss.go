@@ -0,0 +1,292 @@
+package entities
+
+import (
+	"asteroids/internal/constants"
+	"asteroids/internal/utils"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	BOSS_CORE_RADIUS       = 60
+	BOSS_RING_RADIUS       = 115 // Distance of the armour segments from the core.
+	BOSS_SEGMENTS          = 8   // Armour segments in the first phase. Later phases have fewer.
+	BOSS_WEAK_POINTS       = 4
+	BOSS_WEAK_POINT_RADIUS = 14
+	BOSS_WEAK_POINT_OFFSET = BOSS_CORE_RADIUS // Weak points sit on the rim of the core so that shots reach them before the core.
+	BOSS_PHASES            = 3
+	BOSS_SPEED             = 0.6
+	BOSS_SPIN              = 0.004 // Radians per frame in the first phase.
+
+	BOSS_MINION_INTERVAL = 4.0 // Seconds between minion spawns in the first phase.
+	BOSS_BURST_INTERVAL  = 5.0 // Seconds between radial bursts from the second phase.
+	BOSS_BURST_DEBRIS    = 12  // Debris fired by each radial burst.
+)
+
+// Armour plate orbiting the boss' core. Segments block shots and break into
+// debris when destroyed.
+type BossSegment struct {
+	Asteroid Asteroid
+	Offset   rl.Vector2 // Position relative to the core before the boss' rotation.
+}
+
+// Destructible weak point on the rim of the boss' core. The boss is defeated
+// once all of its weak points are destroyed.
+type WeakPoint struct {
+	Offset rl.Vector2 // Position relative to the core before the boss' rotation.
+	Health int
+}
+
+// A huge multi-segment asteroid which shows up periodically. It is armoured
+// by a ring of asteroid segments, attacks by launching minions and radial
+// bursts of debris, and gets more aggressive with each phase.
+type Boss struct {
+	Active     bool
+	Pos        rl.Vector2
+	Rot        float32
+	Points     []rl.Vector2 // Polygon of the core.
+	Segments   []BossSegment
+	WeakPoints []WeakPoint
+	MaxHealth  int
+	Phase      int // Starts at 1 and goes up to `BOSS_PHASES` as the boss is damaged.
+
+	entered     bool // Whether the boss has come on screen and started chasing the ship.
+	minionTimer float32
+	burstTimer  float32
+}
+
+// Creates a boss just above the top of the window. Each weak point has the
+// given health.
+func NewBoss(weakPointHealth int) Boss {
+	boss := Boss{
+		Active:    true,
+		Pos:       rl.Vector2{X: constants.SCREEN_WIDTH / 2, Y: -BOSS_RING_RADIUS - SPAWN_MARGIN},
+		Points:    generateAsteroidShape(16, 1.4, 1.7),
+		MaxHealth: weakPointHealth * BOSS_WEAK_POINTS,
+		Phase:     1,
+	}
+
+	for i := 0; i < BOSS_WEAK_POINTS; i++ {
+		angle := float64(i) * 2 * math.Pi / BOSS_WEAK_POINTS
+		boss.WeakPoints = append(boss.WeakPoints, WeakPoint{
+			Offset: rl.Vector2{X: float32(math.Cos(angle)) * BOSS_WEAK_POINT_OFFSET, Y: float32(math.Sin(angle)) * BOSS_WEAK_POINT_OFFSET},
+			Health: weakPointHealth,
+		})
+	}
+	growArmour(&boss)
+
+	return boss
+}
+
+// Builds a fresh ring of armour segments. Each phase has fewer segments,
+// leaving bigger gaps to shoot through.
+func growArmour(boss *Boss) {
+	count := BOSS_SEGMENTS - 2*(boss.Phase-1)
+	boss.Segments = []BossSegment{}
+	for i := 0; i < count; i++ {
+		angle := float64(i) * 2 * math.Pi / float64(count)
+		offset := rl.Vector2{
+			X: float32(math.Cos(angle)) * BOSS_RING_RADIUS,
+			Y: float32(math.Sin(angle)) * BOSS_RING_RADIUS,
+		}
+		segment := newAsteroid(rl.Vector2Add(boss.Pos, offset), rl.Vector2{}, Medium, Metallic)
+		boss.Segments = append(boss.Segments, BossSegment{Asteroid: segment, Offset: offset})
+	}
+}
+
+// Returns the remaining health of all weak points combined.
+func (boss Boss) Health() int {
+	health := 0
+	for _, weakPoint := range boss.WeakPoints {
+		health += max(0, weakPoint.Health)
+	}
+	return health
+}
+
+// Returns true once every weak point has been destroyed.
+func (boss Boss) IsDefeated() bool {
+	return boss.Health() == 0
+}
+
+// Returns the position of a weak point after the boss' rotation.
+func (boss Boss) WeakPointPos(weakPoint WeakPoint) rl.Vector2 {
+	return rl.Vector2Add(boss.Pos, rl.Vector2Rotate(weakPoint.Offset, boss.Rot))
+}
+
+// Returns every part of the boss that can be hit as an asteroid shaped hitbox,
+// for use with `CastBeam` and collision checks, starting with the core.
+// Destroyed weak points are left out.
+func (boss Boss) Colliders() []Asteroid {
+	colliders := []Asteroid{{Pos: boss.Pos, Hitbox: BOSS_CORE_RADIUS}}
+	for _, segment := range boss.Segments {
+		colliders = append(colliders, segment.Asteroid)
+	}
+	for _, weakPoint := range boss.WeakPoints {
+		if weakPoint.Health > 0 {
+			colliders = append(colliders, Asteroid{Pos: boss.WeakPointPos(weakPoint), Hitbox: BOSS_WEAK_POINT_RADIUS})
+		}
+	}
+	return colliders
+}
+
+// Returns true if a circle touches the boss' core or armour.
+func (boss Boss) Collides(pos rl.Vector2, radius float32) bool {
+	if rl.CheckCollisionCircles(pos, radius, boss.Pos, BOSS_CORE_RADIUS) {
+		return true
+	}
+	for _, segment := range boss.Segments {
+		if rl.CheckCollisionCircles(pos, radius, segment.Asteroid.Pos, float32(segment.Asteroid.Hitbox)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Moves the boss and runs its attack patterns. Returns the minions and debris
+// that it launched this frame.
+func UpdateBoss(boss *Boss, shipPos rl.Vector2, dt float32) []Asteroid {
+	launched := []Asteroid{}
+
+	// The boss enters from the top of the window, then slowly follows the
+	// ship while staying on screen. It speeds up with each phase.
+	target := rl.Vector2{X: constants.SCREEN_WIDTH / 2, Y: constants.SCREEN_HEIGHT * 0.3}
+	if boss.Pos.Y >= target.Y-1 {
+		boss.entered = true
+	}
+	if boss.entered {
+		target = shipPos
+	}
+	speed := BOSS_SPEED * float32(boss.Phase)
+	boss.Pos = rl.Vector2MoveTowards(boss.Pos, target, speed)
+	boss.Pos.X = rl.Clamp(boss.Pos.X, BOSS_RING_RADIUS, constants.SCREEN_WIDTH-BOSS_RING_RADIUS)
+	if boss.entered {
+		boss.Pos.Y = rl.Clamp(boss.Pos.Y, BOSS_RING_RADIUS, constants.SCREEN_HEIGHT-BOSS_RING_RADIUS)
+	}
+	boss.Rot += BOSS_SPIN * float32(boss.Phase)
+
+	for i := range boss.Segments {
+		boss.Segments[i].Asteroid.Pos = rl.Vector2Add(boss.Pos, rl.Vector2Rotate(boss.Segments[i].Offset, boss.Rot))
+	}
+
+	// Minions are launched at the ship in every phase, more often in later
+	// phases.
+	boss.minionTimer += dt * float32(boss.Phase)
+	if boss.minionTimer >= BOSS_MINION_INTERVAL {
+		boss.minionTimer = 0
+		dir := rl.Vector2Normalize(rl.Vector2Subtract(shipPos, boss.Pos))
+		launched = append(launched, newAsteroid(boss.Pos, dir, Medium, randomMaterial()))
+	}
+
+	// From the second phase, the boss fires radial bursts of small debris.
+	if boss.Phase >= 2 {
+		boss.burstTimer += dt
+		if boss.burstTimer >= BOSS_BURST_INTERVAL/float32(boss.Phase-1) {
+			boss.burstTimer = 0
+			launched = append(launched, radialBurst(*boss)...)
+		}
+	}
+
+	return launched
+}
+
+// Returns a ring of small, fast debris flying outwards from the boss.
+func radialBurst(boss Boss) []Asteroid {
+	debris := []Asteroid{}
+	offset := utils.Rand.Float64() * 2 * math.Pi
+	for i := 0; i < BOSS_BURST_DEBRIS; i++ {
+		angle := offset + float64(i)*2*math.Pi/BOSS_BURST_DEBRIS
+		dir := rl.Vector2{X: float32(math.Cos(angle)), Y: float32(math.Sin(angle))}
+		pos := rl.Vector2Add(boss.Pos, rl.Vector2Scale(dir, BOSS_CORE_RADIUS))
+		debris = append(debris, newAsteroid(pos, dir, Small, Rock))
+	}
+	return debris
+}
+
+// Damages whatever part of the boss a shot at `pos` hits. Armour segments are
+// checked first as they shield the core, then the weak points. The rest of the
+// core absorbs shots without taking damage. Returns whether anything was hit
+// and the debris shed by destroyed segments or phase transitions.
+func HitBoss(boss *Boss, pos rl.Vector2, radius float32, damage int) (bool, []Asteroid) {
+	for i := range boss.Segments {
+		segment := &boss.Segments[i].Asteroid
+		if !rl.CheckCollisionCircles(pos, radius, segment.Pos, float32(segment.Hitbox)) {
+			continue
+		}
+
+		segment.Health -= damage
+		if segment.Health > 0 {
+			return true, nil
+		}
+
+		debris := SplitAsteroid(*segment)
+		boss.Segments = append(boss.Segments[:i], boss.Segments[i+1:]...)
+		return true, debris
+	}
+
+	for i := range boss.WeakPoints {
+		weakPoint := &boss.WeakPoints[i]
+		if weakPoint.Health <= 0 ||
+			!rl.CheckCollisionCircles(pos, radius, boss.WeakPointPos(*weakPoint), BOSS_WEAK_POINT_RADIUS) {
+			continue
+		}
+
+		weakPoint.Health -= damage
+		return true, advancePhase(boss)
+	}
+
+	return rl.CheckCollisionCircles(pos, radius, boss.Pos, BOSS_CORE_RADIUS), nil
+}
+
+// Moves the boss onto its next phase once its health drops below the phase's
+// threshold. The old armour is shed as debris and a smaller ring grows back.
+func advancePhase(boss *Boss) []Asteroid {
+	phase := 1 + int(float32(BOSS_PHASES)*(1-float32(boss.Health())/float32(boss.MaxHealth)))
+	if phase <= boss.Phase || boss.IsDefeated() {
+		return nil
+	}
+
+	boss.Phase = min(phase, BOSS_PHASES)
+	debris := ShedArmour(boss)
+	growArmour(boss)
+	return debris
+}
+
+// Breaks every armour segment into debris and removes them from the boss.
+func ShedArmour(boss *Boss) []Asteroid {
+	debris := []Asteroid{}
+	for _, segment := range boss.Segments {
+		pieces := SplitAsteroid(segment.Asteroid)
+		for i := range pieces {
+			// Debris flies away from the core.
+			pieces[i].Dir = rl.Vector2Normalize(rl.Vector2Subtract(segment.Asteroid.Pos, boss.Pos))
+		}
+		debris = append(debris, pieces...)
+	}
+	boss.Segments = []BossSegment{}
+	return debris
+}
+
+// Draws the boss' core, weak points and armour.
+func DrawBoss(boss Boss) {
+	if !boss.Active {
+		return
+	}
+
+	utils.DrawLinesColor(boss.Pos, constants.SCALE, constants.THICKNESS*2, boss.Rot, boss.Points, rl.Maroon)
+
+	pulse := float32(0.5 + 0.5*math.Sin(rl.GetTime()*6))
+	for _, weakPoint := range boss.WeakPoints {
+		pos := boss.WeakPointPos(weakPoint)
+		if weakPoint.Health <= 0 {
+			rl.DrawCircleLinesV(pos, BOSS_WEAK_POINT_RADIUS, rl.DarkGray)
+			continue
+		}
+		rl.DrawCircleV(pos, BOSS_WEAK_POINT_RADIUS*(0.6+0.2*pulse), rl.Fade(rl.Red, 0.6+0.4*pulse))
+		rl.DrawCircleLinesV(pos, BOSS_WEAK_POINT_RADIUS, rl.Red)
+	}
+
+	for _, segment := range boss.Segments {
+		DrawAsteroid(segment.Asteroid)
+	}
+}
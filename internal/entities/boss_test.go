@@ -0,0 +1,113 @@
+package entities
+
+import (
+	"asteroids/internal/constants"
+	"math"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Returns a boss which has already come on screen at `pos`.
+func enteredBoss(pos rl.Vector2) Boss {
+	boss := NewBoss(3)
+	boss.Pos = pos
+	boss.entered = true
+	UpdateBoss(&boss, pos, 0)
+	return boss
+}
+
+func TestHitBossCoreAbsorbsShots(t *testing.T) {
+	boss := enteredBoss(rl.Vector2{X: 400, Y: 400})
+	boss.Segments = nil
+	health := boss.Health()
+
+	// Between two weak points, where only the core can be hit.
+	between := rl.Vector2Rotate(rl.Vector2{X: BOSS_CORE_RADIUS - 5}, boss.Rot+math.Pi/BOSS_WEAK_POINTS)
+	hit, debris := HitBoss(&boss, rl.Vector2Add(boss.Pos, between), 2, 1)
+	if !hit || debris != nil {
+		t.Errorf("HitBoss() on the core = %v, %v, want the shot absorbed", hit, debris)
+	}
+	if boss.Health() != health {
+		t.Errorf("core hit took the boss from %d to %d health", health, boss.Health())
+	}
+
+	if hit, _ := HitBoss(&boss, rl.Vector2{X: 10, Y: 10}, 2, 1); hit {
+		t.Error("HitBoss() hit a shot nowhere near the boss")
+	}
+}
+
+func TestHitBossWeakPointsReachableFromOutside(t *testing.T) {
+	boss := enteredBoss(rl.Vector2{X: 400, Y: 400})
+	boss.Segments = nil
+	health := boss.Health()
+
+	// A shot flying straight at a weak point reaches it before the core.
+	weakPoint := boss.WeakPointPos(boss.WeakPoints[0])
+	dir := rl.Vector2Normalize(rl.Vector2Subtract(boss.Pos, weakPoint))
+	pos := rl.Vector2Subtract(weakPoint, rl.Vector2Scale(dir, 100))
+	for range 100 {
+		if hit, _ := HitBoss(&boss, pos, BULLET_RADIUS, 1); hit {
+			break
+		}
+		pos = rl.Vector2Add(pos, rl.Vector2Scale(dir, 4))
+	}
+	if boss.Health() != health-1 {
+		t.Errorf("boss health = %d, want the weak point damaged to %d", boss.Health(), health-1)
+	}
+}
+
+func TestHitBossArmourShieldsCore(t *testing.T) {
+	boss := enteredBoss(rl.Vector2{X: 400, Y: 400})
+	segments := len(boss.Segments)
+	segment := boss.Segments[0].Asteroid
+	segment.Health = 1
+	boss.Segments[0].Asteroid = segment
+
+	hit, debris := HitBoss(&boss, segment.Pos, 2, 1)
+	if !hit || len(debris) == 0 || len(boss.Segments) != segments-1 {
+		t.Errorf("HitBoss() on a segment = %v with %d debris and %d segments left", hit, len(debris), len(boss.Segments))
+	}
+}
+
+func TestBossStaysOnScreen(t *testing.T) {
+	corners := []rl.Vector2{
+		{X: 0, Y: 0},
+		{X: constants.SCREEN_WIDTH, Y: 0},
+		{X: 0, Y: constants.SCREEN_HEIGHT},
+		{X: constants.SCREEN_WIDTH, Y: constants.SCREEN_HEIGHT},
+	}
+	for _, ship := range corners {
+		boss := NewBoss(3)
+		for range 5000 {
+			UpdateBoss(&boss, ship, 0)
+		}
+		if !boss.entered {
+			t.Fatalf("boss never came on screen chasing a ship at %v", ship)
+		}
+		if boss.Pos.X < BOSS_RING_RADIUS || boss.Pos.X > constants.SCREEN_WIDTH-BOSS_RING_RADIUS ||
+			boss.Pos.Y < BOSS_RING_RADIUS || boss.Pos.Y > constants.SCREEN_HEIGHT-BOSS_RING_RADIUS {
+			t.Errorf("boss chasing a ship at %v ended up at %v, off screen", ship, boss.Pos)
+		}
+	}
+}
+
+func TestBossPhases(t *testing.T) {
+	boss := enteredBoss(rl.Vector2{X: 400, Y: 400})
+	boss.Segments = nil
+
+	for !boss.IsDefeated() {
+		for i := range boss.WeakPoints {
+			if boss.WeakPoints[i].Health > 0 {
+				HitBoss(&boss, boss.WeakPointPos(boss.WeakPoints[i]), 1, 1)
+				break
+			}
+		}
+		if boss.Phase < 1 || boss.Phase > BOSS_PHASES {
+			t.Fatalf("phase = %d, want 1 to %d", boss.Phase, BOSS_PHASES)
+		}
+	}
+	if boss.Phase != BOSS_PHASES {
+		t.Errorf("defeated boss is on phase %d, want %d", boss.Phase, BOSS_PHASES)
+	}
+}
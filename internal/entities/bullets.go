@@ -2,34 +2,104 @@ package entities
 
 import (
 	"asteroids/internal/constants"
-	"math"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+const (
+	BULLET_RADIUS = 2 // Small radius used for the bullet tip's hitbox.
+)
+
+type ProjectileKind int
+
+const (
+	Shot ProjectileKind = iota
+	Missile
+	ChargedShot
+)
+
 type Bullet struct {
 	Start rl.Vector2 // Start coordinates of the bullet.
 	End   rl.Vector2 // End coordinates of the bullet.
 	Vel   rl.Vector2 // Velocity of the bullet.
 	Dir   rl.Vector2 // The direction that the bullet is traveling in.
+
+	Kind   ProjectileKind
+	Damage int     // Health taken from an asteroid on hit.
+	Radius float32 // Radius of the bullet's hitbox around its tip.
+
+	Piercing bool     // Piercing bullets pass through the asteroids they hit.
+	HitIDs   []uint32 // Asteroids already hit by a piercing bullet or deflecting the bullet.
+	Scored   bool     // Whether the bullet has damaged an asteroid, counting towards accuracy.
+
+	Owner int // Index of the player who fired the bullet.
 }
 
-func NewBullet(pos rl.Vector2, rotation float32) Bullet {
-	// Calculate the direction that the bullet is traveling towards. Rotation
-	// is based on the direction that it was fired.
-	direction := rl.Vector2{
-		X: float32(-math.Sin(float64(rotation))),
-		Y: float32(math.Cos(float64(rotation))),
+// Returns true if the bullet has already hit the asteroid. Only piercing
+// bullets survive a hit, so this stops them hitting the same asteroid twice.
+func (bullet Bullet) HasHit(asteroid Asteroid) bool {
+	for _, id := range bullet.HitIDs {
+		if id == asteroid.ID {
+			return true
+		}
 	}
+	return false
+}
+
+// Creates a projectile at `pos` travelling along the `aim` vector. The aim
+// does not need to be normalised.
+func NewProjectile(kind ProjectileKind, pos rl.Vector2, aim rl.Vector2, speed float32, damage int) Bullet {
+	direction := rl.Vector2Normalize(aim)
 
 	return Bullet{
-		Start: pos,
-		End:   rl.Vector2Add(pos, rl.Vector2Scale(direction, constants.BULLET_LENGTH)),
-		Vel:   rl.Vector2{X: 4, Y: 4},
-		Dir:   direction,
+		Start:  pos,
+		End:    rl.Vector2Add(pos, rl.Vector2Scale(direction, constants.BULLET_LENGTH)),
+		Vel:    rl.Vector2{X: speed, Y: speed},
+		Dir:    direction,
+		Kind:   kind,
+		Damage: damage,
+		Radius: BULLET_RADIUS,
 	}
 }
 
+// Creates a blaster bullet at `pos` travelling along the `aim` vector.
+func NewBullet(pos rl.Vector2, aim rl.Vector2) Bullet {
+	tuning := Blaster.Tuning()
+	return NewProjectile(Shot, pos, aim, tuning.Speed, tuning.Damage)
+}
+
+// Turns a homing missile towards the nearest asteroid. Missiles turn at a
+// limited rate so they curve onto their target.
+func SteerMissile(bullet *Bullet, asteroids []Asteroid) {
+	if bullet.Kind != Missile || len(asteroids) == 0 {
+		return
+	}
+
+	nearest := asteroids[0].Pos
+	for _, asteroid := range asteroids[1:] {
+		if rl.Vector2Distance(bullet.Start, asteroid.Pos) < rl.Vector2Distance(bullet.Start, nearest) {
+			nearest = asteroid.Pos
+		}
+	}
+
+	desired := rl.Vector2Normalize(rl.Vector2Subtract(nearest, bullet.Start))
+	bullet.Dir = rl.Vector2Normalize(rl.Vector2Add(bullet.Dir, rl.Vector2Scale(desired, MISSILE_TURN_RATE)))
+}
+
 func DrawBullet(bullet Bullet) {
-	rl.DrawLineV(bullet.Start, bullet.End, rl.RayWhite)
+	color := rl.RayWhite
+	if bullet.Piercing {
+		color = rl.Purple
+	}
+
+	switch bullet.Kind {
+	case Missile:
+		rl.DrawLineEx(bullet.Start, bullet.End, 3, rl.Orange)
+		rl.DrawCircleV(bullet.Start, 3, color)
+	case ChargedShot:
+		rl.DrawCircleLinesV(bullet.Start, bullet.Radius, color)
+		rl.DrawCircleV(bullet.Start, bullet.Radius/2, rl.SkyBlue)
+	default:
+		rl.DrawLineV(bullet.Start, bullet.End, color)
+	}
 }
@@ -0,0 +1,160 @@
+package entities
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/utils"
+	"math"
+	"math/rand/v2"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	GRAVITY_WELL_RANGE = 300 // Distance at which gravity wells start pulling.
+	BLACK_HOLE_RANGE   = 350 // Distance at which black holes start pulling.
+	EVENT_HORIZON      = 24  // Anything closer than this to a black hole is destroyed.
+
+	HAZARD_EDGE_MARGIN = 120 // Random hazards are kept this far from the edges of the playfield.
+	HAZARD_SAFE_RADIUS = 250 // Random hazards are kept this far from where the ship spawns.
+)
+
+type HazardKind int
+
+const (
+	GravityWell HazardKind = iota
+	BlackHole
+)
+
+// A fixed point in the playfield which pulls the ship, asteroids and bullets
+// towards it.
+type Hazard struct {
+	Kind HazardKind
+	Pos  rl.Vector2
+}
+
+// Returns the distance at which the hazard starts pulling.
+func (hazard Hazard) Range() float32 {
+	if hazard.Kind == BlackHole {
+		return BLACK_HOLE_RANGE
+	}
+	return GRAVITY_WELL_RANGE
+}
+
+// Returns the pull of the hazard at its centre from the current tuning.
+func (hazard Hazard) Strength() float32 {
+	if hazard.Kind == BlackHole {
+		return config.Get().Hazards.BlackHoleStrength
+	}
+	return config.Get().Hazards.WellStrength
+}
+
+// Returns the acceleration towards the hazard of something at `pos`. The pull
+// gets stronger the closer it is to the hazard.
+func (hazard Hazard) Pull(pos rl.Vector2) rl.Vector2 {
+	toHazard := rl.Vector2Subtract(hazard.Pos, pos)
+	distance := rl.Vector2Length(toHazard)
+	if distance == 0 || distance > hazard.Range() {
+		return rl.Vector2{}
+	}
+
+	return rl.Vector2Scale(toHazard, hazard.Strength()*(1-distance/hazard.Range())/distance)
+}
+
+// Places the hazards for a new game of the mode with the given key. Random
+// hazards are placed away from the edges and the ship's spawn point, using the
+// tuning's seed if it is set so that the same layout can be played again.
+func PlaceHazards(tuning config.HazardTuning, mode string) []Hazard {
+	hazards := []Hazard{}
+	for _, placement := range tuning.Placements {
+		kind := GravityWell
+		if placement.Kind == "black_hole" {
+			kind = BlackHole
+		}
+		hazards = append(hazards, Hazard{Kind: kind, Pos: rl.Vector2{X: placement.X, Y: placement.Y}})
+	}
+
+	seed := tuning.Seed
+	if seed == 0 {
+		seed = utils.Rand.Uint64()
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	center := rl.Vector2{X: constants.SCREEN_WIDTH / 2, Y: constants.SCREEN_HEIGHT / 2}
+	randomPos := func() rl.Vector2 {
+		for {
+			pos := rl.Vector2{
+				X: HAZARD_EDGE_MARGIN + rng.Float32()*(constants.SCREEN_WIDTH-2*HAZARD_EDGE_MARGIN),
+				Y: HAZARD_EDGE_MARGIN + rng.Float32()*(constants.SCREEN_HEIGHT-2*HAZARD_EDGE_MARGIN),
+			}
+			if rl.Vector2Distance(pos, center) >= HAZARD_SAFE_RADIUS {
+				return pos
+			}
+		}
+	}
+
+	counts := tuning.Counts(mode)
+	for range counts.GravityWells {
+		hazards = append(hazards, Hazard{Kind: GravityWell, Pos: randomPos()})
+	}
+	for range counts.BlackHoles {
+		hazards = append(hazards, Hazard{Kind: BlackHole, Pos: randomPos()})
+	}
+	return hazards
+}
+
+// Returns the total acceleration from every hazard on something at `pos`.
+func Gravity(hazards []Hazard, pos rl.Vector2) rl.Vector2 {
+	total := rl.Vector2{}
+	for _, hazard := range hazards {
+		total = rl.Vector2Add(total, hazard.Pull(pos))
+	}
+	return total
+}
+
+// Returns true if `pos` is inside the event horizon of a black hole.
+func Swallowed(hazards []Hazard, pos rl.Vector2) bool {
+	for _, hazard := range hazards {
+		if hazard.Kind == BlackHole && rl.Vector2Distance(hazard.Pos, pos) < EVENT_HORIZON {
+			return true
+		}
+	}
+	return false
+}
+
+// Accelerates something moving with a speed and unit direction, as used by
+// asteroids and bullets, so its course curves towards the acceleration.
+func BendCourse(vel *rl.Vector2, dir *rl.Vector2, accel rl.Vector2) {
+	velocity := rl.Vector2Add(rl.Vector2Multiply(*vel, *dir), accel)
+	speed := rl.Vector2Length(velocity)
+	if speed == 0 {
+		return
+	}
+
+	*dir = rl.Vector2Scale(velocity, 1/speed)
+	*vel = rl.Vector2{X: speed, Y: speed}
+}
+
+// Draws the hazard as rings which drift inwards to show the pull. Black holes
+// also draw their event horizon.
+func DrawHazard(hazard Hazard) {
+	color := rl.SkyBlue
+	if hazard.Kind == BlackHole {
+		color = rl.Purple
+	}
+
+	const rings = 4
+	phase := float32(math.Mod(rl.GetTime()*0.5, 1))
+	for i := range rings {
+		progress := (float32(i) + 1 - phase) / rings
+		rl.DrawCircleLinesV(hazard.Pos, hazard.Range()*progress, rl.Fade(color, 0.35*(1-progress)))
+	}
+
+	if hazard.Kind == BlackHole {
+		rl.DrawCircleV(hazard.Pos, EVENT_HORIZON, rl.Black)
+		rl.DrawCircleLinesV(hazard.Pos, EVENT_HORIZON, rl.Orange)
+		rl.DrawCircleLinesV(hazard.Pos, EVENT_HORIZON+4, rl.Fade(rl.Orange, 0.4))
+	} else {
+		rl.DrawCircleLinesV(hazard.Pos, 6, color)
+	}
+}
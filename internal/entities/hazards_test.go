@@ -0,0 +1,85 @@
+package entities
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"slices"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestHazardPull(t *testing.T) {
+	well := Hazard{Kind: GravityWell, Pos: rl.Vector2{X: 400, Y: 400}}
+
+	near := well.Pull(rl.Vector2{X: 500, Y: 400})
+	far := well.Pull(rl.Vector2{X: 600, Y: 400})
+	if near.X >= 0 || near.Y != 0 {
+		t.Errorf("pulled along %v, want towards the well", near)
+	}
+	if rl.Vector2Length(near) <= rl.Vector2Length(far) {
+		t.Errorf("pull of %v close by and %v further away, want it stronger closer in", near, far)
+	}
+
+	for _, pos := range []rl.Vector2{well.Pos, {X: 400 + GRAVITY_WELL_RANGE + 1, Y: 400}} {
+		if pull := well.Pull(pos); pull != (rl.Vector2{}) {
+			t.Errorf("pulled along %v at %v, want no pull", pull, pos)
+		}
+	}
+}
+
+func TestSwallowed(t *testing.T) {
+	pos := rl.Vector2{X: 400, Y: 400}
+	inside := rl.Vector2{X: 400 + EVENT_HORIZON/2, Y: 400}
+
+	if !Swallowed([]Hazard{{Kind: BlackHole, Pos: pos}}, inside) {
+		t.Error("something inside the event horizon wasn't swallowed")
+	}
+	if Swallowed([]Hazard{{Kind: BlackHole, Pos: pos}}, rl.Vector2{X: 400 + EVENT_HORIZON, Y: 400}) {
+		t.Error("something outside the event horizon was swallowed")
+	}
+	if Swallowed([]Hazard{{Kind: GravityWell, Pos: pos}}, inside) {
+		t.Error("a gravity well swallowed something")
+	}
+}
+
+func TestBendCourse(t *testing.T) {
+	vel := rl.Vector2{X: 3, Y: 3}
+	dir := rl.Vector2{X: 1}
+	BendCourse(&vel, &dir, rl.Vector2{Y: 4})
+
+	if vel != (rl.Vector2{X: 5, Y: 5}) || rl.Vector2Distance(dir, rl.Vector2{X: 0.6, Y: 0.8}) > 1e-6 {
+		t.Errorf("speed %v along %v, want 5 along (0.6, 0.8)", vel, dir)
+	}
+}
+
+func TestPlaceHazards(t *testing.T) {
+	tuning := config.Default().Hazards
+	tuning.Placements = []config.HazardPlacement{{Kind: "black_hole", X: 100, Y: 200}}
+	tuning.GravityWells = 3
+	tuning.BlackHoles = 2
+	tuning.Seed = 7
+
+	hazards := PlaceHazards(tuning, "classic")
+	if len(hazards) != 6 {
+		t.Fatalf("placed %d hazards, want 6", len(hazards))
+	}
+	if hazards[0] != (Hazard{Kind: BlackHole, Pos: rl.Vector2{X: 100, Y: 200}}) {
+		t.Errorf("first hazard is %+v, want the fixed placement", hazards[0])
+	}
+
+	center := rl.Vector2{X: constants.SCREEN_WIDTH / 2, Y: constants.SCREEN_HEIGHT / 2}
+	for _, hazard := range hazards[1:] {
+		if rl.Vector2Distance(hazard.Pos, center) < HAZARD_SAFE_RADIUS {
+			t.Errorf("hazard at %v is too close to the spawn point", hazard.Pos)
+		}
+		if hazard.Pos.X < HAZARD_EDGE_MARGIN || hazard.Pos.X > constants.SCREEN_WIDTH-HAZARD_EDGE_MARGIN ||
+			hazard.Pos.Y < HAZARD_EDGE_MARGIN || hazard.Pos.Y > constants.SCREEN_HEIGHT-HAZARD_EDGE_MARGIN {
+			t.Errorf("hazard at %v is too close to the edge", hazard.Pos)
+		}
+	}
+
+	if again := PlaceHazards(tuning, "classic"); !slices.Equal(hazards, again) {
+		t.Error("the same seed placed the hazards differently")
+	}
+}
@@ -0,0 +1,187 @@
+package entities
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/utils"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	DEFLECT_CHANCE = 0.3 // Chance that a metallic asteroid deflects a bullet.
+
+	EXPLOSION_RADIUS   = 120 // Radius in which exploding asteroids damage their neighbours.
+	EXPLOSION_DAMAGE   = 2
+	EXPLOSION_DURATION = 0.4 // Seconds that the explosion ring is drawn for.
+
+	ICE_SHARDS      = 5   // Shards created when an ice asteroid shatters.
+	ICE_SHARD_SPEED = 1.8 // Speed multiplier of ice shards.
+
+	MAGNET_RANGE    = 250 // Distance at which magnetic asteroids start pulling the ship.
+	MAGNET_STRENGTH = 1.2 // Pull on the ship when it is touching the asteroid.
+
+	GEM_SCORE = 150 // Score given by each gem dropped by a crystal asteroid.
+)
+
+// What an asteroid is made of. Materials other than rock have special
+// behaviours and their own outline style.
+type Material int
+
+const (
+	Rock Material = iota
+	Metallic
+	Explosive
+	Ice
+	Magnetic
+	Crystal
+
+	NUM_MATERIALS = iota
+)
+
+func (material Material) String() string {
+	return [...]string{"Rock", "Metallic", "Explosive", "Ice", "Magnetic", "Crystal"}[material]
+}
+
+// Returns the spawn weight and multipliers of the material from the tuning.
+func (material Material) Tuning() config.MaterialTuning {
+	materials := config.Get().Materials
+	return [...]config.MaterialTuning{
+		materials.Rock,
+		materials.Metallic,
+		materials.Explosive,
+		materials.Ice,
+		materials.Magnetic,
+		materials.Crystal,
+	}[material]
+}
+
+func (material Material) color() rl.Color {
+	return [...]rl.Color{rl.RayWhite, rl.LightGray, rl.Orange, rl.SkyBlue, rl.Purple, rl.Lime}[material]
+}
+
+// Picks a random material using the spawn weights from the tuning.
+func randomMaterial() Material {
+	total := float32(0)
+	for material := Material(0); material < NUM_MATERIALS; material++ {
+		total += material.Tuning().Weight
+	}
+
+	pick := utils.Rand.Float32() * total
+	for material := Material(0); material < NUM_MATERIALS; material++ {
+		pick -= material.Tuning().Weight
+		if pick < 0 {
+			return material
+		}
+	}
+	return Rock
+}
+
+// Returns true if a metallic asteroid deflects a bullet instead of taking
+// damage. Charged shots are too heavy to be deflected.
+func Deflects(asteroid Asteroid, bullet Bullet) bool {
+	return asteroid.Material == Metallic && bullet.Kind != ChargedShot && utils.Rand.Float32() < DEFLECT_CHANCE
+}
+
+// Bounces a bullet off the surface of an asteroid.
+func DeflectBullet(bullet *Bullet, asteroid Asteroid) {
+	normal := rl.Vector2Normalize(rl.Vector2Subtract(bullet.Start, asteroid.Pos))
+	bullet.Dir = rl.Vector2Reflect(bullet.Dir, normal)
+	bullet.HitIDs = append(bullet.HitIDs, asteroid.ID)
+}
+
+// Returns how far a magnetic asteroid pulls something at `pos` this frame.
+// The pull gets stronger the closer it is to the asteroid.
+func MagneticPull(asteroid Asteroid, pos rl.Vector2) rl.Vector2 {
+	if asteroid.Material != Magnetic {
+		return rl.Vector2{}
+	}
+
+	toAsteroid := rl.Vector2Subtract(asteroid.Pos, pos)
+	distance := rl.Vector2Length(toAsteroid)
+	if distance == 0 || distance > MAGNET_RANGE {
+		return rl.Vector2{}
+	}
+
+	return rl.Vector2Scale(toAsteroid, MAGNET_STRENGTH*(1-distance/MAGNET_RANGE)/distance)
+}
+
+// Returns the IDs of the asteroids caught in the blast of an exploding
+// asteroid.
+func ExplosionTargets(exploding Asteroid, asteroids []Asteroid) []uint32 {
+	targets := []uint32{}
+	for _, asteroid := range asteroids {
+		if asteroid.ID != exploding.ID &&
+			rl.CheckCollisionCircles(exploding.Pos, EXPLOSION_RADIUS, asteroid.Pos, float32(asteroid.Hitbox)) {
+			targets = append(targets, asteroid.ID)
+		}
+	}
+	return targets
+}
+
+// Returns the gems dropped by a destroyed crystal asteroid. Larger crystals
+// drop more gems.
+func CrystalGems(asteroid Asteroid) []PowerUp {
+	gems := []PowerUp{}
+	if asteroid.Material != Crystal {
+		return gems
+	}
+
+	for i := 0; i <= int(asteroid.Size); i++ {
+		gems = append(gems, NewPowerUp(asteroid.Pos, Gem))
+	}
+	return gems
+}
+
+// Expanding ring drawn where an explosive asteroid was destroyed.
+type Explosion struct {
+	Pos   rl.Vector2
+	Timer float32 // Seconds left before the explosion disappears.
+}
+
+func NewExplosion(pos rl.Vector2) Explosion {
+	return Explosion{Pos: pos, Timer: EXPLOSION_DURATION}
+}
+
+func DrawExplosion(explosion Explosion) {
+	progress := 1 - explosion.Timer/EXPLOSION_DURATION
+	rl.DrawCircleLinesV(explosion.Pos, EXPLOSION_RADIUS*progress, rl.Fade(rl.Orange, 1-progress))
+	rl.DrawCircleLinesV(explosion.Pos, EXPLOSION_RADIUS*progress*0.7, rl.Fade(rl.Yellow, 1-progress))
+}
+
+// Draws the material specific details on top of the asteroid's outline.
+func drawMaterial(asteroid Asteroid) {
+	color := asteroid.Material.color()
+	points := asteroid.Points
+
+	switch asteroid.Material {
+	case Metallic:
+		// A second plated outline just inside the first.
+		inner := make([]rl.Vector2, len(points))
+		for i, point := range points {
+			inner[i] = rl.Vector2Scale(point, 0.75)
+		}
+		utils.DrawLinesColor(asteroid.Pos, constants.SCALE, constants.THICKNESS, 0, inner, color)
+	case Explosive:
+		// A pulsing core.
+		pulse := float32(0.5 + 0.5*math.Sin(rl.GetTime()*8))
+		rl.DrawCircleV(asteroid.Pos, float32(asteroid.Hitbox)*0.25, rl.Fade(rl.Red, 0.3+0.5*pulse))
+	case Ice:
+		// Facets running from the centre to every other corner.
+		for i := 0; i < len(points); i += 2 {
+			end := rl.Vector2Add(asteroid.Pos, rl.Vector2Scale(points[i], constants.SCALE))
+			rl.DrawLineV(asteroid.Pos, end, rl.Fade(color, 0.5))
+		}
+	case Magnetic:
+		// A faint ring showing the range of the pull.
+		rl.DrawCircleLinesV(asteroid.Pos, MAGNET_RANGE, rl.Fade(color, 0.15))
+	case Crystal:
+		// A star joining every third corner.
+		for i := range points {
+			start := rl.Vector2Add(asteroid.Pos, rl.Vector2Scale(points[i], constants.SCALE))
+			end := rl.Vector2Add(asteroid.Pos, rl.Vector2Scale(points[(i+3)%len(points)], constants.SCALE))
+			rl.DrawLineV(start, end, rl.Fade(color, 0.4))
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	POPUP_DURATION  = 1.0 // Seconds that a popup is shown for.
+	POPUP_RISE      = 40  // Distance that a popup floats upwards over its lifetime.
+	POPUP_FONT_SIZE = 20
+)
+
+// Text which floats up from where points were scored and fades out.
+type Popup struct {
+	Pos   rl.Vector2
+	Text  string
+	Color rl.Color
+	Timer float32 // Seconds left before the popup disappears.
+}
+
+func NewPopup(pos rl.Vector2, text string, color rl.Color) Popup {
+	return Popup{Pos: pos, Text: text, Color: color, Timer: POPUP_DURATION}
+}
+
+func DrawPopup(popup Popup) {
+	progress := 1 - popup.Timer/POPUP_DURATION
+	width := rl.MeasureText(popup.Text, POPUP_FONT_SIZE)
+	rl.DrawText(
+		popup.Text,
+		int32(popup.Pos.X)-width/2,
+		int32(popup.Pos.Y-POPUP_RISE*progress),
+		POPUP_FONT_SIZE,
+		rl.Fade(popup.Color, 1-progress),
+	)
+}
@@ -0,0 +1,133 @@
+package entities
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/utils"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	POWERUP_HITBOX = 14
+	POWERUP_SPEED  = 0.5
+)
+
+type PowerUpKind int
+
+const (
+	RapidFire PowerUpKind = iota
+	SpreadShot
+	PiercingRounds
+	ShieldRecharge
+	ExtraLife
+	TimeSlow
+
+	// Number of power-ups which can be dropped by any asteroid. Kinds after
+	// this are only dropped by specific asteroids.
+	NUM_POWERUP_KINDS = iota
+)
+
+// Bonus score dropped by crystal asteroids.
+const Gem = PowerUpKind(NUM_POWERUP_KINDS)
+
+func (kind PowerUpKind) String() string {
+	switch kind {
+	case RapidFire:
+		return "Rapid Fire"
+	case SpreadShot:
+		return "Spread Shot"
+	case PiercingRounds:
+		return "Piercing Rounds"
+	case ShieldRecharge:
+		return "Shield Recharge"
+	case ExtraLife:
+		return "Extra Life"
+	case TimeSlow:
+		return "Time Slow"
+	case Gem:
+		return "Gem"
+	default:
+		panic("unreachable: unexpected power-up kind")
+	}
+}
+
+// Returns true if the power-up lasts for a while once collected, rather than
+// taking effect immediately.
+func (kind PowerUpKind) IsTimed() bool {
+	return kind != ShieldRecharge && kind != ExtraLife && kind != Gem
+}
+
+// Letter drawn inside the pickup so the player can tell them apart.
+func (kind PowerUpKind) symbol() string {
+	return [...]string{"R", "S", "P", "H", "1", "T", "$"}[kind]
+}
+
+func (kind PowerUpKind) color() rl.Color {
+	return [...]rl.Color{rl.Orange, rl.SkyBlue, rl.Purple, rl.Green, rl.Gold, rl.Pink, rl.Lime}[kind]
+}
+
+// A pickup dropped by a destroyed asteroid. It drifts slowly and expires if
+// it is not collected.
+type PowerUp struct {
+	Pos   rl.Vector2
+	Dir   rl.Vector2 // Direction that the pickup drifts in.
+	Kind  PowerUpKind
+	Timer float32 // Seconds left before the pickup expires.
+}
+
+// Randomly drops a pickup at `pos` based on the drop chance in the tuning.
+// Returns false if nothing was dropped.
+func MaybeDropPowerUp(pos rl.Vector2) (PowerUp, bool) {
+	if utils.Rand.Float32() >= config.Get().PowerUps.DropChance {
+		return PowerUp{}, false
+	}
+
+	return NewPowerUp(pos, PowerUpKind(utils.Rand.IntN(NUM_POWERUP_KINDS))), true
+}
+
+// Creates a pickup at `pos` which drifts in a random direction.
+func NewPowerUp(pos rl.Vector2, kind PowerUpKind) PowerUp {
+	angle := utils.Rand.Float64() * 2 * math.Pi
+	return PowerUp{
+		Pos:   pos,
+		Dir:   rl.Vector2{X: float32(math.Cos(angle)), Y: float32(math.Sin(angle))},
+		Kind:  kind,
+		Timer: config.Get().PowerUps.Lifetime,
+	}
+}
+
+// Drifts the pickup and counts down its lifetime. Pickups wrap around the
+// window like the ship so they cannot drift out of reach.
+func UpdatePowerUp(powerUp *PowerUp, dt float32) {
+	powerUp.Pos = rl.Vector2Add(powerUp.Pos, rl.Vector2Scale(powerUp.Dir, POWERUP_SPEED))
+	powerUp.Pos.X = float32(math.Mod(float64(powerUp.Pos.X)+constants.SCREEN_WIDTH, constants.SCREEN_WIDTH))
+	powerUp.Pos.Y = float32(math.Mod(float64(powerUp.Pos.Y)+constants.SCREEN_HEIGHT, constants.SCREEN_HEIGHT))
+	powerUp.Timer -= dt
+}
+
+// Returns true once the pickup's lifetime has run out.
+func (powerUp PowerUp) IsExpired() bool {
+	return powerUp.Timer <= 0
+}
+
+// Draws the pickup as a circle with its symbol inside. The pickup blinks
+// during the last couple of seconds before it expires.
+func DrawPowerUp(powerUp PowerUp) {
+	if powerUp.Timer < 2 && int(powerUp.Timer*6)%2 == 0 {
+		return
+	}
+
+	color := powerUp.Kind.color()
+	rl.DrawCircleLinesV(powerUp.Pos, POWERUP_HITBOX, color)
+
+	symbol := powerUp.Kind.symbol()
+	rl.DrawText(
+		symbol,
+		int32(powerUp.Pos.X)-rl.MeasureText(symbol, 16)/2,
+		int32(powerUp.Pos.Y)-8,
+		16,
+		color,
+	)
+}
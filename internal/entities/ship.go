@@ -1,7 +1,9 @@
 package entities
 
 import (
+	"asteroids/internal/config"
 	"asteroids/internal/constants"
+	"asteroids/internal/input"
 	"asteroids/internal/utils"
 	"math"
 
@@ -10,14 +12,7 @@ import (
 
 const (
 	SHIP_HITBOX_RADIUS = 15
-
-	// Ship movement constants
-	ROTATION_SPEED = 0.05
-	ACCEL          = 0.10
-	DECEL          = 0.01
-	MIN_VEL        = 2.0
-	MAX_VEL        = 5.0
-	DRAG           = 0.01
+	SHIP_DRIFT_DAMPING = 0.05 // Fraction of the ship's drift lost every frame.
 )
 
 type Ship struct {
@@ -25,6 +20,11 @@ type Ship struct {
 	Vel        rl.Vector2 // Initial velocity of the ship
 	Rot        float32    // Rotation angle of the ship
 	DeathTimer float32    // Death timer for the ship
+	Thrusting  bool       // Whether the ship is thrusting forward this frame
+	Shields    int        // Number of asteroid hits the shield can still absorb
+	Weapon     WeaponKind // Currently selected weapon
+	Drift      rl.Vector2 // Velocity from outside forces such as gravity wells
+	Color      rl.Color   // Colour of the ship's outline, which tells players apart
 }
 
 // Returns true/false whether the ship is dead or not.
@@ -32,6 +32,14 @@ func (ship Ship) IsDead() bool {
 	return ship.DeathTimer > 0
 }
 
+// Returns the unit vector that the ship is facing.
+func (ship Ship) Heading() rl.Vector2 {
+	return rl.Vector2{
+		X: float32(-math.Sin(float64(ship.Rot))),
+		Y: float32(math.Cos(float64(ship.Rot))),
+	}
+}
+
 // Initialises a new Ship struct. Position defaults to the middle of the window,
 // velocity defaults to 2 and rotation defaults to 0.0 which is facing upwards.
 func NewShip() Ship {
@@ -40,23 +48,30 @@ func NewShip() Ship {
 		Vel:        rl.Vector2{X: 2, Y: 2},
 		Rot:        0,
 		DeathTimer: 0,
+		Color:      rl.RayWhite,
 	}
 }
 
+// Polygon of the base ship without thrusters.
+var shipLines = []rl.Vector2{
+	{X: -0.4, Y: -0.5},
+	{X: 0.0, Y: 0.5},
+	{X: 0.4, Y: -0.5},
+	{X: 0.2, Y: -0.4},
+	{X: -0.2, Y: -0.4},
+}
+
 // Draws the base ship without thrusters.
-func drawShip(pos rl.Vector2, scale float32, thickness float32, rotation float32) {
-	shipLines := []rl.Vector2{
-		{X: -0.4, Y: -0.5},
-		{X: 0.0, Y: 0.5},
-		{X: 0.4, Y: -0.5},
-		{X: 0.2, Y: -0.4},
-		{X: -0.2, Y: -0.4},
-	}
+func drawShip(pos rl.Vector2, scale float32, thickness float32, rotation float32, color rl.Color) {
+	utils.DrawLinesColor(pos, scale, thickness, rotation, shipLines, color)
+}
 
-	utils.DrawLines(pos, scale, thickness, rotation, shipLines)
+// Draws a small ship pointing upwards, used for the lives counter on the HUD.
+func DrawShipIcon(pos rl.Vector2, size float32, thickness float32, color rl.Color) {
+	utils.DrawLinesColor(pos, size, thickness, math.Pi, shipLines, color)
 }
 
-func drawShipWithThrusters(pos rl.Vector2, scale float32, thickness float32, rotation float32) {
+func drawShipWithThrusters(pos rl.Vector2, scale float32, thickness float32, rotation float32, color rl.Color) {
 	shipWithThrusters := []rl.Vector2{
 		{X: -0.4, Y: -0.5},
 		{X: 0.0, Y: 0.5},
@@ -71,53 +86,82 @@ func drawShipWithThrusters(pos rl.Vector2, scale float32, thickness float32, rot
 		{X: -0.4, Y: -0.5},
 	}
 
-	utils.DrawLines(pos, scale, thickness, rotation, shipWithThrusters)
+	utils.DrawLinesColor(pos, scale, thickness, rotation, shipWithThrusters, color)
 }
 
-// Updates the ship depending on whether its dead and movement keys.
-func UpdateShip(ship *Ship) {
+// Updates the ship depending on whether its dead and the controls for this
+// frame.
+func UpdateShip(ship *Ship, controls input.Controls) {
+	ship.Thrusting = false
 	if ship.IsDead() {
 		return
 	}
 
-	// Side movements only handle the direction that the ship is facing.
-	if rl.IsKeyDown(rl.KeyA) {
-		ship.Rot -= ROTATION_SPEED
+	// Ship movement values are read from the tuning so they can be changed
+	// while the game is running.
+	tuning := config.Get().Ship
+
+	// Weapons can be switched even while the ship is moving.
+	if controls.NextWeapon {
+		ship.Weapon = ship.Weapon.Cycle(1)
 	}
-	if rl.IsKeyDown(rl.KeyD) {
-		ship.Rot += ROTATION_SPEED
+	if controls.PrevWeapon {
+		ship.Weapon = ship.Weapon.Cycle(-1)
 	}
 
-	// Handle forward and backward movements for the ship.
-	if rl.IsKeyDown(rl.KeyW) {
-		ship.Vel = rl.Vector2ClampValue(
-			rl.Vector2Scale(ship.Vel, 1.0+ACCEL),
-			MIN_VEL,
-			MAX_VEL,
-		)
+	// Hyperspace teleports the ship to a random location in the window.
+	if controls.Hyperspace {
+		ship.Pos = rl.Vector2{
+			X: utils.RandInRange(0, constants.SCREEN_WIDTH),
+			Y: utils.RandInRange(0, constants.SCREEN_HEIGHT),
+		}
 	}
-	if rl.IsKeyDown(rl.KeyS) {
-		ship.Vel = rl.Vector2ClampValue(
-			rl.Vector2Scale(ship.Vel, 1.0-DECEL),
-			0,
-			MAX_VEL,
-		)
+
+	if controls.Mode == input.TwinStick {
+		// The ship moves directly in the direction of the movement stick and
+		// turns to face where it is going. Aiming is handled separately.
+		if speed := rl.Vector2Length(controls.Move); speed > 0 {
+			ship.Thrusting = true
+			ship.Rot = float32(math.Atan2(float64(-controls.Move.X), float64(controls.Move.Y)))
+			ship.Vel = rl.Vector2{X: tuning.MaxVel * min(speed, 1), Y: tuning.MaxVel * min(speed, 1)}
+		}
+	} else {
+		// Side movements only handle the direction that the ship is facing.
+		ship.Rot += controls.Rotate * tuning.RotationSpeed
+
+		// Handle forward and backward movements for the ship.
+		if controls.Thrust > 0 {
+			ship.Thrusting = true
+			ship.Vel = rl.Vector2ClampValue(
+				rl.Vector2Scale(ship.Vel, 1.0+tuning.Accel*controls.Thrust),
+				tuning.MinVel,
+				tuning.MaxVel,
+			)
+		}
+		if controls.Reverse {
+			ship.Vel = rl.Vector2ClampValue(
+				rl.Vector2Scale(ship.Vel, 1.0-tuning.Decel),
+				0,
+				tuning.MaxVel,
+			)
+		}
 	}
 
 	// Calculate the ship's velocity after accounting for drag. Creates that
 	// floating through space feel.
-	ship.Vel = rl.Vector2Scale(ship.Vel, 1.0-DRAG)
+	ship.Vel = rl.Vector2Scale(ship.Vel, 1.0-tuning.Drag)
 
 	// Updating the ship's position after accounting for all velocity changes.
-	shipDirection := rl.Vector2{
-		X: float32(-math.Sin(float64(ship.Rot))),
-		Y: float32(math.Cos(float64(ship.Rot))),
-	}
 	ship.Pos = rl.Vector2Add(
 		ship.Pos,
-		rl.Vector2Multiply(ship.Vel, shipDirection),
+		rl.Vector2Multiply(ship.Vel, ship.Heading()),
 	)
 
+	// Outside forces push the ship on top of its own movement and slowly die
+	// off once the ship escapes them.
+	ship.Pos = rl.Vector2Add(ship.Pos, ship.Drift)
+	ship.Drift = rl.Vector2Scale(ship.Drift, 1.0-SHIP_DRIFT_DAMPING)
+
 	// Handle out of bounds movements of the ship. The ship going out of bounds
 	// will simply teleport the ship to the opposite side of where it was going.
 	ship.Pos.X = float32(math.Mod(float64(ship.Pos.X), float64(constants.SCREEN_WIDTH)))
@@ -133,10 +177,16 @@ func RenderShip(ship *Ship) {
 			constants.SCALE,
 			constants.THICKNESS,
 			ship.Rot,
+			ship.Color,
 		)
 
-		if rl.IsKeyDown(rl.KeyW) {
-			drawShipWithThrusters(ship.Pos, constants.SCALE, constants.THICKNESS, ship.Rot)
+		if ship.Thrusting {
+			drawShipWithThrusters(ship.Pos, constants.SCALE, constants.THICKNESS, ship.Rot, ship.Color)
+		}
+
+		// The shield is drawn as a ring around the ship.
+		if ship.Shields > 0 {
+			rl.DrawCircleLinesV(ship.Pos, SHIP_HITBOX_RADIUS+8, rl.Fade(rl.Green, 0.4+0.2*float32(ship.Shields)))
 		}
 	}
 }
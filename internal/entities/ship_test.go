@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/input"
+	"math"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestTwinStickMovesAlongStick(t *testing.T) {
+	tests := []struct {
+		name string
+		move rl.Vector2
+	}{
+		{"right", rl.Vector2{X: 1}},
+		{"up", rl.Vector2{Y: -1}},
+		{"diagonal", rl.Vector2Normalize(rl.Vector2{X: -1, Y: 1})},
+	}
+	for _, test := range tests {
+		ship := NewShip()
+		ship.Rot = 1
+		start := ship.Pos
+
+		UpdateShip(&ship, input.Controls{Mode: input.TwinStick, Move: test.move})
+		moved := rl.Vector2Normalize(rl.Vector2Subtract(ship.Pos, start))
+		if rl.Vector2Distance(moved, test.move) > 1e-3 {
+			t.Errorf("%s: moved towards %v, want %v", test.name, moved, test.move)
+		}
+		if heading := ship.Heading(); rl.Vector2Distance(heading, test.move) > 1e-3 {
+			t.Errorf("%s: facing %v, want the way it is going", test.name, heading)
+		}
+	}
+}
+
+func TestTwinStickSpeedFollowsStick(t *testing.T) {
+	tuning := config.Get().Ship
+	for _, push := range []float32{0.5, 1} {
+		ship := NewShip()
+		start := ship.Pos
+		UpdateShip(&ship, input.Controls{Mode: input.TwinStick, Move: rl.Vector2{X: push}})
+
+		want := tuning.MaxVel * push * (1 - tuning.Drag)
+		if got := rl.Vector2Distance(start, ship.Pos); math.Abs(float64(got-want)) > 1e-3 {
+			t.Errorf("moved %v with the stick pushed %v, want %v", got, push, want)
+		}
+	}
+}
+
+func TestTwinStickCoastsWithoutStick(t *testing.T) {
+	ship := NewShip()
+	ship.Rot = 1
+	vel := ship.Vel
+
+	UpdateShip(&ship, input.Controls{Mode: input.TwinStick})
+	if ship.Rot != 1 || ship.Thrusting {
+		t.Errorf("rotation %v and thrusting %v with the stick let go, want the ship left alone", ship.Rot, ship.Thrusting)
+	}
+	if want := rl.Vector2Scale(vel, 1-config.Get().Ship.Drag); ship.Vel != want {
+		t.Errorf("velocity %v, want %v after drag", ship.Vel, want)
+	}
+}
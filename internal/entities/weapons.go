@@ -0,0 +1,137 @@
+package entities
+
+import (
+	"asteroids/internal/config"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	SPREAD_WEAPON_BULLETS = 5   // Bullets fired by each spread shot.
+	SPREAD_WEAPON_ANGLE   = 0.2 // Radians between the bullets of a spread shot.
+
+	LASER_RANGE = 700 // Length of the laser beam when it does not hit anything.
+
+	MISSILE_TURN_RATE = 0.06 // How quickly homing missiles steer towards their target.
+
+	CHARGE_MAX_TIME = 1.5 // Seconds of holding fire until a charge shot is fully charged.
+)
+
+type WeaponKind int
+
+const (
+	Blaster WeaponKind = iota
+	Spread
+	Laser
+	Homing
+	Charge
+
+	NUM_WEAPONS = iota
+)
+
+func (kind WeaponKind) String() string {
+	switch kind {
+	case Blaster:
+		return "Blaster"
+	case Spread:
+		return "Spread"
+	case Laser:
+		return "Laser"
+	case Homing:
+		return "Homing Missiles"
+	case Charge:
+		return "Charge Shot"
+	default:
+		panic("unreachable: unexpected weapon kind")
+	}
+}
+
+// Returns the fire rate, damage and speed of the weapon from the tuning.
+func (kind WeaponKind) Tuning() config.WeaponTuning {
+	weapons := config.Get().Weapons
+	switch kind {
+	case Spread:
+		return weapons.Spread
+	case Laser:
+		return weapons.Laser
+	case Homing:
+		return weapons.Homing
+	case Charge:
+		return weapons.Charge
+	default:
+		return weapons.Blaster
+	}
+}
+
+// Returns the weapon `step` places after this one, wrapping around.
+func (kind WeaponKind) Cycle(step int) WeaponKind {
+	return WeaponKind(((int(kind)+step)%NUM_WEAPONS + NUM_WEAPONS) % NUM_WEAPONS)
+}
+
+// Returns the directions of the bullets fired by a spread shot, fanned out
+// evenly around the aim.
+func SpreadAims(aim rl.Vector2, count int, angle float32) []rl.Vector2 {
+	aims := []rl.Vector2{}
+	for i := 0; i < count; i++ {
+		offset := (float32(i) - float32(count-1)/2) * angle
+		aims = append(aims, rl.Vector2Rotate(aim, offset))
+	}
+	return aims
+}
+
+// Returns the damage of a charge shot which was charged for `charge` seconds.
+// An uncharged shot does 1 damage and a full charge does the weapon's damage.
+func ChargeDamage(charge float32) int {
+	fraction := min(charge/CHARGE_MAX_TIME, 1)
+	maxDamage := Charge.Tuning().Damage
+	return 1 + int(math.Round(float64(fraction)*float64(maxDamage-1)))
+}
+
+// A continuous laser beam. Beams hit the first asteroid along their path
+// instantly rather than travelling like bullets.
+type Beam struct {
+	Active bool
+	Start  rl.Vector2
+	End    rl.Vector2 // Where the beam stops, either at an asteroid or at its full range.
+}
+
+// Casts a ray from `start` along the unit vector `dir` and returns the point
+// where it first hits an asteroid's hitbox, along with the index of that
+// asteroid. The index is -1 if nothing is hit within `length`.
+func CastBeam(start rl.Vector2, dir rl.Vector2, length float32, asteroids []Asteroid) (rl.Vector2, int) {
+	closest := length
+	hit := -1
+
+	for i, asteroid := range asteroids {
+		toCenter := rl.Vector2Subtract(asteroid.Pos, start)
+		along := rl.Vector2DotProduct(toCenter, dir)
+		if along < 0 {
+			continue
+		}
+
+		// Distance between the asteroid's centre and the ray, squared.
+		radius := float32(asteroid.Hitbox)
+		distanceSqr := rl.Vector2LengthSqr(toCenter) - along*along
+		if distanceSqr > radius*radius {
+			continue
+		}
+
+		entry := max(0, along-float32(math.Sqrt(float64(radius*radius-distanceSqr))))
+		if entry < closest {
+			closest = entry
+			hit = i
+		}
+	}
+
+	return rl.Vector2Add(start, rl.Vector2Scale(dir, closest)), hit
+}
+
+func DrawBeam(beam Beam) {
+	if !beam.Active {
+		return
+	}
+
+	rl.DrawLineEx(beam.Start, beam.End, 4, rl.Fade(rl.Red, 0.5))
+	rl.DrawLineEx(beam.Start, beam.End, 1.5, rl.RayWhite)
+}
@@ -0,0 +1,77 @@
+package entities
+
+import (
+	"math"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestCycleWeapons(t *testing.T) {
+	tests := []struct {
+		kind WeaponKind
+		step int
+		want WeaponKind
+	}{
+		{Blaster, 1, Spread},
+		{Charge, 1, Blaster},
+		{Blaster, -1, Charge},
+		{Laser, NUM_WEAPONS, Laser},
+	}
+	for _, test := range tests {
+		if got := test.kind.Cycle(test.step); got != test.want {
+			t.Errorf("%s.Cycle(%d) = %s, want %s", test.kind, test.step, got, test.want)
+		}
+	}
+}
+
+func TestSpreadAims(t *testing.T) {
+	aim := rl.Vector2{X: 1}
+	aims := SpreadAims(aim, 3, 0.5)
+	if len(aims) != 3 {
+		t.Fatalf("%d aims, want 3", len(aims))
+	}
+	if aims[1] != aim {
+		t.Errorf("middle aim %v, want straight along %v", aims[1], aim)
+	}
+	for i, want := range []float64{-0.5, 0, 0.5} {
+		if angle := math.Atan2(float64(aims[i].Y), float64(aims[i].X)); math.Abs(angle-want) > 1e-4 {
+			t.Errorf("aim %d is at %v radians, want %v", i, angle, want)
+		}
+	}
+}
+
+func TestChargeDamage(t *testing.T) {
+	maxDamage := Charge.Tuning().Damage
+	tests := []struct {
+		charge float32
+		want   int
+	}{
+		{0, 1},
+		{CHARGE_MAX_TIME, maxDamage},
+		{2 * CHARGE_MAX_TIME, maxDamage},
+	}
+	for _, test := range tests {
+		if got := ChargeDamage(test.charge); got != test.want {
+			t.Errorf("ChargeDamage(%v) = %d, want %d", test.charge, got, test.want)
+		}
+	}
+}
+
+func TestCastBeam(t *testing.T) {
+	start := rl.Vector2{X: 100, Y: 100}
+	near := newAsteroid(rl.Vector2{X: 300, Y: 100}, rl.Vector2{X: 1}, Medium, Rock)
+	far := newAsteroid(rl.Vector2{X: 500, Y: 100}, rl.Vector2{X: 1}, Medium, Rock)
+	behind := newAsteroid(rl.Vector2{X: 0, Y: 100}, rl.Vector2{X: 1}, Medium, Rock)
+	asteroids := []Asteroid{far, behind, near}
+
+	end, hit := CastBeam(start, rl.Vector2{X: 1}, LASER_RANGE, asteroids)
+	if want := (rl.Vector2{X: 300 - float32(near.Hitbox), Y: 100}); hit != 2 || end != want {
+		t.Errorf("CastBeam() = %v, %d, want the nearest asteroid ahead at %v", end, hit, want)
+	}
+
+	end, hit = CastBeam(start, rl.Vector2{Y: 1}, LASER_RANGE, asteroids)
+	if want := (rl.Vector2{X: 100, Y: 100 + LASER_RANGE}); hit != -1 || end != want {
+		t.Errorf("CastBeam() = %v, %d, want a miss ending at %v", end, hit, want)
+	}
+}
@@ -0,0 +1,280 @@
+// Package which maps the player's keys and gamepad onto game actions. Game code
+// asks for actions instead of polling raw keys so that controls can be rebound.
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type Action int
+
+const (
+	RotateLeft Action = iota
+	RotateRight
+	Thrust
+	Reverse
+	Fire
+	Hyperspace
+	NextWeapon
+	PrevWeapon
+	Confirm // Restarts the game from the game over screen.
+)
+
+// All actions in the order they are listed on the rebinding screen.
+var Actions = []Action{RotateLeft, RotateRight, Thrust, Reverse, Fire, Hyperspace, NextWeapon, PrevWeapon, Confirm}
+
+// Names used for the actions in the bindings file.
+var actionNames = map[Action]string{
+	RotateLeft:  "rotate_left",
+	RotateRight: "rotate_right",
+	Thrust:      "thrust",
+	Reverse:     "reverse",
+	Fire:        "fire",
+	Hyperspace:  "hyperspace",
+	NextWeapon:  "next_weapon",
+	PrevWeapon:  "prev_weapon",
+	Confirm:     "confirm",
+}
+
+func (action Action) String() string {
+	return actionNames[action]
+}
+
+// Maps every action onto a keyboard key. Actions bound to `rl.KeyNull` are
+// unbound.
+type Bindings map[Action]int32
+
+// A named set of bindings that the player can pick on the rebinding screen.
+type Preset struct {
+	Name     string
+	Bindings Bindings
+}
+
+var Presets = []Preset{
+	{
+		Name: "WASD",
+		Bindings: Bindings{
+			RotateLeft:  rl.KeyA,
+			RotateRight: rl.KeyD,
+			Thrust:      rl.KeyW,
+			Reverse:     rl.KeyS,
+			Fire:        rl.KeySpace,
+			Hyperspace:  rl.KeyLeftShift,
+			NextWeapon:  rl.KeyE,
+			PrevWeapon:  rl.KeyQ,
+			Confirm:     rl.KeyEnter,
+		},
+	},
+	{
+		// Mirrors the button panel of the arcade cabinet: two rotate buttons
+		// on the left, thrust, fire and hyperspace on the right.
+		Name: "Arcade",
+		Bindings: Bindings{
+			RotateLeft:  rl.KeyZ,
+			RotateRight: rl.KeyX,
+			Thrust:      rl.KeyPeriod,
+			Reverse:     rl.KeyNull,
+			Fire:        rl.KeySlash,
+			Hyperspace:  rl.KeySpace,
+			NextWeapon:  rl.KeyV,
+			PrevWeapon:  rl.KeyC,
+			Confirm:     rl.KeyEnter,
+		},
+	},
+	{
+		Name: "Arrow keys",
+		Bindings: Bindings{
+			RotateLeft:  rl.KeyLeft,
+			RotateRight: rl.KeyRight,
+			Thrust:      rl.KeyUp,
+			Reverse:     rl.KeyDown,
+			Fire:        rl.KeySpace,
+			Hyperspace:  rl.KeyRightShift,
+			NextWeapon:  rl.KeyX,
+			PrevWeapon:  rl.KeyZ,
+			Confirm:     rl.KeyEnter,
+		},
+	},
+	{
+		Name: "Left-handed",
+		Bindings: Bindings{
+			RotateLeft:  rl.KeyJ,
+			RotateRight: rl.KeyL,
+			Thrust:      rl.KeyI,
+			Reverse:     rl.KeyK,
+			Fire:        rl.KeyRightShift,
+			Hyperspace:  rl.KeyU,
+			NextWeapon:  rl.KeyO,
+			PrevWeapon:  rl.KeyY,
+			Confirm:     rl.KeyEnter,
+		},
+	},
+}
+
+// The bindings currently used by the game.
+var current = Presets[0].Bindings.Clone()
+
+// Returns the bindings currently used by the game.
+func Get() Bindings {
+	return current
+}
+
+// Replaces the bindings currently used by the game.
+func Set(bindings Bindings) {
+	current = bindings.Clone()
+	others = otherPlayerBindings(current)
+}
+
+// Returns a copy of the bindings which can be edited without affecting the
+// original.
+func (b Bindings) Clone() Bindings {
+	clone := Bindings{}
+	for action, key := range b {
+		clone[action] = key
+	}
+	return clone
+}
+
+// Returns every group of actions that share the same key. Unbound actions
+// never conflict.
+func (b Bindings) Conflicts() [][]Action {
+	byKey := map[int32][]Action{}
+	keys := []int32{}
+	for _, action := range Actions {
+		key := b[action]
+		if key == rl.KeyNull {
+			continue
+		}
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], action)
+	}
+
+	conflicts := [][]Action{}
+	for _, key := range keys {
+		if len(byKey[key]) > 1 {
+			conflicts = append(conflicts, byKey[key])
+		}
+	}
+	return conflicts
+}
+
+// Returns true if the action shares its key with another action.
+func (b Bindings) IsConflicting(action Action) bool {
+	for _, group := range b.Conflicts() {
+		for _, other := range group {
+			if other == action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the actions of `other` bound to a key which is also bound in these
+// bindings. Unbound actions never clash.
+func (b Bindings) Clashes(other Bindings) []Action {
+	keys := map[int32]bool{}
+	for _, action := range Actions {
+		keys[b[action]] = true
+	}
+
+	clashes := []Action{}
+	for _, action := range Actions {
+		key := other[action]
+		if key != rl.KeyNull && keys[key] {
+			clashes = append(clashes, action)
+		}
+	}
+	return clashes
+}
+
+// Returns an error listing every group of actions that share a key, or nil if
+// every action has a key of its own.
+func (b Bindings) Validate() error {
+	errs := []error{}
+	for _, group := range b.Conflicts() {
+		names := []string{}
+		for _, action := range group {
+			names = append(names, action.String())
+		}
+		errs = append(errs, fmt.Errorf("%s share %s", strings.Join(names, ", "), KeyName(b[group[0]])))
+	}
+	return errors.Join(errs...)
+}
+
+func (b Bindings) MarshalJSON() ([]byte, error) {
+	named := map[string]string{}
+	for _, action := range Actions {
+		named[action.String()] = KeyName(b[action])
+	}
+	return json.Marshal(named)
+}
+
+func (b *Bindings) UnmarshalJSON(data []byte) error {
+	named := map[string]string{}
+	if err := json.Unmarshal(data, &named); err != nil {
+		return err
+	}
+
+	*b = Presets[0].Bindings.Clone()
+	for _, action := range Actions {
+		name, ok := named[action.String()]
+		if !ok {
+			continue
+		}
+		key, ok := keyFromName(name)
+		if !ok {
+			return fmt.Errorf("unknown key %q for %s", name, action)
+		}
+		(*b)[action] = key
+	}
+	return nil
+}
+
+// Reads bindings from a file. Actions missing from the file keep their default
+// key. Bindings where actions share a key are rejected, the same as on the
+// rebinding screen.
+func Load(path string) (Bindings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := Bindings{}
+	if err := json.Unmarshal(data, &bindings); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := bindings.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return bindings, nil
+}
+
+// Writes the bindings to a file.
+func Save(path string, bindings Bindings) error {
+	data, err := json.MarshalIndent(bindings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Returns true while the key or gamepad button bound to the action is held
+// down.
+func IsDown(action Action) bool {
+	return playerDevice(0).isDown(action)
+}
+
+// Returns true on the frame the key or gamepad button bound to the action is
+// pressed.
+func IsPressed(action Action) bool {
+	return playerDevice(0).isPressed(action)
+}
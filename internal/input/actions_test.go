@@ -0,0 +1,86 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestPresetsHaveNoConflicts(t *testing.T) {
+	for _, preset := range Presets {
+		if err := preset.Bindings.Validate(); err != nil {
+			t.Errorf("%s preset: %v", preset.Name, err)
+		}
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	bindings := Presets[0].Bindings.Clone()
+	bindings[Fire] = bindings[Thrust]
+	bindings[Reverse] = rl.KeyNull
+	bindings[Hyperspace] = rl.KeyNull
+
+	conflicts := bindings.Conflicts()
+	if len(conflicts) != 1 || len(conflicts[0]) != 2 || conflicts[0][0] != Thrust || conflicts[0][1] != Fire {
+		t.Fatalf("conflicts = %v, want [[thrust fire]]", conflicts)
+	}
+	if !bindings.IsConflicting(Fire) || bindings.IsConflicting(Reverse) {
+		t.Errorf("fire should conflict and unbound actions shouldn't")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{"partial file keeps defaults", `{"fire": "F"}`, ""},
+		{"unknown key", `{"fire": "NOPE"}`, "unknown key"},
+		{"shared key", `{"fire": "W"}`, "thrust, fire share W"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bindings.json")
+			if err := os.WriteFile(path, []byte(test.file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			bindings, err := Load(path)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if bindings[Fire] != rl.KeyF || bindings[Thrust] != Presets[0].Bindings[Thrust] {
+				t.Errorf("Load() = %v", bindings)
+			}
+		})
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bindings.json")
+	for _, preset := range Presets {
+		if err := Save(path, preset.Bindings); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s preset: %v", preset.Name, err)
+		}
+		for _, action := range Actions {
+			if loaded[action] != preset.Bindings[action] {
+				t.Errorf("%s preset: %s = %s, want %s", preset.Name, action, KeyName(loaded[action]), KeyName(preset.Bindings[action]))
+			}
+		}
+	}
+}
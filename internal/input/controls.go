@@ -0,0 +1,154 @@
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Key which switches between the control modes. It is fixed so that it always
+// works regardless of the current bindings.
+const CONTROL_MODE_KEY = rl.KeyF2
+
+type ControlMode int
+
+const (
+	// The ship rotates and thrusts along its heading and fires forwards.
+	Classic ControlMode = iota
+	// The ship moves directly in screen space while aiming and firing is
+	// done independently with the mouse or the right stick.
+	TwinStick
+)
+
+func (mode ControlMode) String() string {
+	switch mode {
+	case TwinStick:
+		return "Twin-stick"
+	default:
+		return "Classic"
+	}
+}
+
+// The control mode currently used by the game.
+var mode = Classic
+
+// Returns the control mode currently used by the game.
+func Mode() ControlMode {
+	return mode
+}
+
+// Switches to the next control mode and returns it.
+func ToggleMode() ControlMode {
+	if mode == Classic {
+		mode = TwinStick
+	} else {
+		mode = Classic
+	}
+	return mode
+}
+
+// Snapshot of the ship controls for a single frame. The ship is driven by this
+// snapshot rather than by polling keys itself.
+type Controls struct {
+	Mode       ControlMode
+	Rotate     float32 // -1 rotates fully left, 1 rotates fully right.
+	Thrust     float32 // 0 to 1 forward thrust.
+	Reverse    bool
+	Move       rl.Vector2 // Twin-stick screen space movement, with a length of at most 1.
+	Aim        rl.Vector2 // Twin-stick aim direction. Zero when not aiming.
+	Fire       bool       // True while the fire action is held.
+	Hyperspace bool       // True on the frame the hyperspace action is pressed.
+	NextWeapon bool       // True on the frame the next weapon action is pressed.
+	PrevWeapon bool       // True on the frame the previous weapon action is pressed.
+}
+
+// Returns the controls as they are when held over for another tick, with the
+// one-off presses released.
+func (controls Controls) Held() Controls {
+	controls.Hyperspace = false
+	controls.NextWeapon = false
+	controls.PrevWeapon = false
+	return controls
+}
+
+// Reads the ship controls from the keyboard using the current bindings and
+// combines them with the connected gamepad. In twin-stick mode the ship aims
+// from `shipPos` towards the `cursor`, both in playfield coordinates.
+func Poll(shipPos rl.Vector2, cursor rl.Vector2) Controls {
+	return PollPlayer(0, shipPos, cursor)
+}
+
+// Reads the ship controls of a player in local multiplayer from their
+// keyboard bindings and gamepad. Only the first player aims with the mouse.
+func PollPlayer(player int, shipPos rl.Vector2, cursor rl.Vector2) Controls {
+	device := playerDevice(player)
+	if mode == TwinStick {
+		return device.pollTwinStick(shipPos, cursor)
+	}
+
+	controls := device.pollButtons()
+	pad := device.pollGamepadAxes()
+
+	controls.Rotate = max(-1, min(controls.Rotate+pad.Rotate, 1))
+	controls.Thrust = max(controls.Thrust, pad.Thrust)
+	controls.Reverse = controls.Reverse || pad.Reverse
+
+	return controls
+}
+
+// Reads the ship controls from the keyboard and the gamepad buttons.
+func (device device) pollButtons() Controls {
+	controls := Controls{
+		Reverse:    device.isDown(Reverse),
+		Fire:       device.isDown(Fire),
+		Hyperspace: device.isPressed(Hyperspace),
+		NextWeapon: device.isPressed(NextWeapon),
+		PrevWeapon: device.isPressed(PrevWeapon),
+	}
+
+	if device.isDown(RotateLeft) {
+		controls.Rotate -= 1
+	}
+	if device.isDown(RotateRight) {
+		controls.Rotate += 1
+	}
+	if device.isDown(Thrust) {
+		controls.Thrust = 1
+	}
+
+	return controls
+}
+
+// Reads the twin-stick controls. Movement comes from the thrust, reverse and
+// rotate actions or the left stick. Aiming comes from the right stick, which
+// also fires, or otherwise from the mouse.
+func (device device) pollTwinStick(shipPos rl.Vector2, cursor rl.Vector2) Controls {
+	controls := Controls{
+		Mode:       TwinStick,
+		Fire:       device.isDown(Fire) || device.mouse && rl.IsMouseButtonDown(rl.MouseButtonLeft),
+		Hyperspace: device.isPressed(Hyperspace),
+		NextWeapon: device.isPressed(NextWeapon) || device.mouse && rl.GetMouseWheelMove() < 0,
+		PrevWeapon: device.isPressed(PrevWeapon) || device.mouse && rl.GetMouseWheelMove() > 0,
+	}
+
+	if device.isDown(Thrust) {
+		controls.Move.Y -= 1
+	}
+	if device.isDown(Reverse) {
+		controls.Move.Y += 1
+	}
+	if device.isDown(RotateLeft) {
+		controls.Move.X -= 1
+	}
+	if device.isDown(RotateRight) {
+		controls.Move.X += 1
+	}
+	controls.Move = rl.Vector2ClampValue(rl.Vector2Add(controls.Move, device.gamepadStick(rl.GamepadAxisLeftX, rl.GamepadAxisLeftY)), 0, 1)
+
+	if aim := device.gamepadStick(rl.GamepadAxisRightX, rl.GamepadAxisRightY); rl.Vector2Length(aim) > 0 {
+		controls.Aim = rl.Vector2Normalize(aim)
+		controls.Fire = true
+	} else if toMouse := rl.Vector2Subtract(cursor, shipPos); device.mouse && rl.Vector2Length(toMouse) > 0 {
+		controls.Aim = rl.Vector2Normalize(toMouse)
+	}
+
+	return controls
+}
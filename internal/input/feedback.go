@@ -0,0 +1,77 @@
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Gameplay events which the player should feel, e.g. through gamepad rumble.
+type FeedbackEvent int
+
+const (
+	FeedbackDeath FeedbackEvent = iota
+	FeedbackExplosion
+	FeedbackExtraLife
+	FeedbackBossPhase
+)
+
+// Rumble strength of each motor in [0, 1] and how long it lasts in seconds.
+type rumble struct {
+	left     float32
+	right    float32
+	duration float32
+}
+
+var rumbleProfiles = map[FeedbackEvent]rumble{
+	FeedbackDeath:     {left: 1.0, right: 1.0, duration: 0.6},
+	FeedbackExplosion: {left: 0.2, right: 0.5, duration: 0.15},
+	FeedbackExtraLife: {left: 0.0, right: 0.3, duration: 0.3},
+	FeedbackBossPhase: {left: 0.8, right: 0.4, duration: 0.8},
+}
+
+// Functions which are called for every feedback event on top of the gamepad
+// rumble.
+var feedbackHandlers = []func(FeedbackEvent){}
+
+// Registers a function to be called for every feedback event.
+func OnFeedback(handler func(FeedbackEvent)) {
+	feedbackHandlers = append(feedbackHandlers, handler)
+}
+
+// Whether feedback events are ignored, e.g. while a rewound game is being
+// simulated again and its events have already been felt.
+var feedbackMuted = false
+
+// Mutes or unmutes feedback events and returns whether they were muted before,
+// so that the caller can put it back.
+func MuteFeedback(muted bool) bool {
+	wasMuted := feedbackMuted
+	feedbackMuted = muted
+	return wasMuted
+}
+
+// Stands in for the player of a feedback event which belongs to no one in
+// particular, such as a boss changing phase, and is felt by every player.
+const EVERY_PLAYER = -1
+
+// Sends a feedback event to the gamepad of the player it happened to, or to
+// every gamepad for `EVERY_PLAYER`, and to any registered handlers.
+func SendFeedback(player int, event FeedbackEvent) {
+	if feedbackMuted {
+		return
+	}
+
+	if profile, ok := rumbleProfiles[event]; ok {
+		for i := 0; i < MAX_PLAYERS; i++ {
+			if player != EVERY_PLAYER && player != i {
+				continue
+			}
+			if pad := playerDevice(i).pad; pad >= 0 {
+				rl.SetGamepadVibration(pad, profile.left, profile.right, profile.duration)
+			}
+		}
+	}
+
+	for _, handler := range feedbackHandlers {
+		handler(event)
+	}
+}
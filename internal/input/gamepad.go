@@ -0,0 +1,125 @@
+package input
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	MAX_GAMEPADS = 4
+
+	// Stick and trigger values below the deadzone are ignored so that worn
+	// sticks do not slowly rotate the ship.
+	GAMEPAD_DEADZONE = 0.2
+
+	// Exponent applied to the stick after the deadzone. Values above 1 give
+	// finer control near the centre of the stick.
+	GAMEPAD_RESPONSE_CURVE = 2.0
+)
+
+// Maps actions onto gamepad buttons. Rotation, thrust and reverse are also
+// read from the left stick and triggers.
+var GamepadButtons = map[Action]int32{
+	RotateLeft:  rl.GamepadButtonLeftFaceLeft,
+	RotateRight: rl.GamepadButtonLeftFaceRight,
+	Thrust:      rl.GamepadButtonLeftFaceUp,
+	Reverse:     rl.GamepadButtonLeftFaceDown,
+	Fire:        rl.GamepadButtonRightFaceDown,
+	Hyperspace:  rl.GamepadButtonRightFaceUp,
+	NextWeapon:  rl.GamepadButtonRightTrigger1,
+	PrevWeapon:  rl.GamepadButtonLeftTrigger1,
+	Confirm:     rl.GamepadButtonMiddleRight,
+}
+
+// The gamepad which drives the first player's ship. This is the first
+// connected gamepad; other players use the gamepad at their own index.
+type gamepadState struct {
+	index     int32
+	connected bool
+	name      string
+}
+
+var gamepad = gamepadState{}
+
+// Checks for gamepads being plugged in or removed. Returns a message
+// describing the change, or an empty string if nothing changed.
+func UpdateGamepads() string {
+	if gamepad.connected && rl.IsGamepadAvailable(gamepad.index) {
+		return ""
+	}
+
+	wasConnected := gamepad.connected
+	gamepad = gamepadState{}
+	for i := int32(0); i < MAX_GAMEPADS; i++ {
+		if rl.IsGamepadAvailable(i) {
+			gamepad = gamepadState{index: i, connected: true, name: rl.GetGamepadName(i)}
+			return "Gamepad connected: " + gamepad.name
+		}
+	}
+
+	if wasConnected {
+		return "Gamepad disconnected"
+	}
+	return ""
+}
+
+// Returns true if a gamepad is connected.
+func IsGamepadConnected() bool {
+	return gamepad.connected
+}
+
+// Applies the deadzone and response curve to a stick value in [-1, 1]. The
+// result is rescaled so that it still covers the full [-1, 1] range.
+func applyResponseCurve(value float32) float32 {
+	magnitude := math.Abs(float64(value))
+	if magnitude < GAMEPAD_DEADZONE {
+		return 0
+	}
+
+	magnitude = min((magnitude-GAMEPAD_DEADZONE)/(1-GAMEPAD_DEADZONE), 1)
+	return float32(math.Copysign(math.Pow(magnitude, GAMEPAD_RESPONSE_CURVE), float64(value)))
+}
+
+// Returns how far a trigger is pulled in [0, 1]. Triggers report -1 when
+// released and 1 when fully pulled.
+func (device device) gamepadTrigger(axis int32) float32 {
+	pull := (rl.GetGamepadAxisMovement(device.pad, axis) + 1) / 2
+	if pull < GAMEPAD_DEADZONE {
+		return 0
+	}
+	return min((pull-GAMEPAD_DEADZONE)/(1-GAMEPAD_DEADZONE), 1)
+}
+
+// Reads the analog ship controls from the device's gamepad, if it has one.
+// Gamepad buttons are handled along with the keyboard by `isDown`.
+func (device device) pollGamepadAxes() Controls {
+	if device.pad < 0 {
+		return Controls{}
+	}
+
+	return Controls{
+		Rotate:  applyResponseCurve(rl.GetGamepadAxisMovement(device.pad, rl.GamepadAxisLeftX)),
+		Thrust:  device.gamepadTrigger(rl.GamepadAxisRightTrigger),
+		Reverse: device.gamepadTrigger(rl.GamepadAxisLeftTrigger) > 0.5,
+	}
+}
+
+// Returns the position of a gamepad stick with a radial deadzone and the
+// response curve applied. Returns zero when the device has no gamepad.
+func (device device) gamepadStick(axisX int32, axisY int32) rl.Vector2 {
+	if device.pad < 0 {
+		return rl.Vector2{}
+	}
+
+	stick := rl.Vector2{
+		X: rl.GetGamepadAxisMovement(device.pad, axisX),
+		Y: rl.GetGamepadAxisMovement(device.pad, axisY),
+	}
+	length := rl.Vector2Length(stick)
+	if length == 0 {
+		return stick
+	}
+
+	return rl.Vector2Scale(stick, applyResponseCurve(length)/length)
+}
@@ -0,0 +1,49 @@
+package input
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyResponseCurve(t *testing.T) {
+	tests := []struct {
+		value float32
+		want  float32
+	}{
+		{0, 0},
+		{GAMEPAD_DEADZONE / 2, 0},
+		{-GAMEPAD_DEADZONE / 2, 0},
+		{GAMEPAD_DEADZONE, 0},
+		{0.6, 0.25},
+		{-0.6, -0.25},
+		{1, 1},
+		{-1, -1},
+		{1.2, 1}, // Sticks can report a little past the edge.
+	}
+	for _, test := range tests {
+		if got := applyResponseCurve(test.value); math.Abs(float64(got-test.want)) > 1e-4 {
+			t.Errorf("applyResponseCurve(%v) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestResponseCurveIsSmooth(t *testing.T) {
+	previous := float32(0)
+	for step := range 101 {
+		value := applyResponseCurve(float32(step) / 100)
+		if value < previous {
+			t.Fatalf("applyResponseCurve(%v) = %v, which is less than before", float32(step)/100, value)
+		}
+		previous = value
+	}
+}
+
+func TestNoGamepadGivesNoAxes(t *testing.T) {
+	device := device{pad: -1}
+	if controls := device.pollGamepadAxes(); controls != (Controls{}) {
+		t.Errorf("pollGamepadAxes() without a gamepad = %+v, want nothing", controls)
+	}
+	if stick := device.gamepadStick(0, 1); stick.X != 0 || stick.Y != 0 {
+		t.Errorf("gamepadStick() without a gamepad = %v, want the centre", stick)
+	}
+}
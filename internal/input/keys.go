@@ -0,0 +1,88 @@
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Names used for keys in the bindings file and on the rebinding screen.
+var keyNames = map[int32]string{
+	rl.KeyNull: "NONE",
+
+	rl.KeySpace:        "SPACE",
+	rl.KeyEnter:        "ENTER",
+	rl.KeyTab:          "TAB",
+	rl.KeyBackspace:    "BACKSPACE",
+	rl.KeyInsert:       "INSERT",
+	rl.KeyDelete:       "DELETE",
+	rl.KeyHome:         "HOME",
+	rl.KeyEnd:          "END",
+	rl.KeyPageUp:       "PAGE_UP",
+	rl.KeyPageDown:     "PAGE_DOWN",
+	rl.KeyRight:        "RIGHT",
+	rl.KeyLeft:         "LEFT",
+	rl.KeyDown:         "DOWN",
+	rl.KeyUp:           "UP",
+	rl.KeyLeftShift:    "LEFT_SHIFT",
+	rl.KeyLeftControl:  "LEFT_CONTROL",
+	rl.KeyLeftAlt:      "LEFT_ALT",
+	rl.KeyRightShift:   "RIGHT_SHIFT",
+	rl.KeyRightControl: "RIGHT_CONTROL",
+	rl.KeyRightAlt:     "RIGHT_ALT",
+
+	rl.KeyApostrophe:   "APOSTROPHE",
+	rl.KeyComma:        "COMMA",
+	rl.KeyMinus:        "MINUS",
+	rl.KeyPeriod:       "PERIOD",
+	rl.KeySlash:        "SLASH",
+	rl.KeySemicolon:    "SEMICOLON",
+	rl.KeyEqual:        "EQUAL",
+	rl.KeyLeftBracket:  "LEFT_BRACKET",
+	rl.KeyBackSlash:    "BACKSLASH",
+	rl.KeyRightBracket: "RIGHT_BRACKET",
+	rl.KeyGrave:        "GRAVE",
+
+	rl.KeyKp0:        "KP_0",
+	rl.KeyKp1:        "KP_1",
+	rl.KeyKp2:        "KP_2",
+	rl.KeyKp3:        "KP_3",
+	rl.KeyKp4:        "KP_4",
+	rl.KeyKp5:        "KP_5",
+	rl.KeyKp6:        "KP_6",
+	rl.KeyKp7:        "KP_7",
+	rl.KeyKp8:        "KP_8",
+	rl.KeyKp9:        "KP_9",
+	rl.KeyKpDecimal:  "KP_DECIMAL",
+	rl.KeyKpDivide:   "KP_DIVIDE",
+	rl.KeyKpMultiply: "KP_MULTIPLY",
+	rl.KeyKpSubtract: "KP_SUBTRACT",
+	rl.KeyKpAdd:      "KP_ADD",
+	rl.KeyKpEnter:    "KP_ENTER",
+}
+
+func init() {
+	// Letters and digits share their ASCII codes with the raylib key codes.
+	for key := int32(rl.KeyA); key <= rl.KeyZ; key++ {
+		keyNames[key] = string(rune(key))
+	}
+	for key := int32(rl.KeyZero); key <= rl.KeyNine; key++ {
+		keyNames[key] = string(rune(key))
+	}
+}
+
+// Returns the display name of a key.
+func KeyName(key int32) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// Returns the key with the given display name.
+func keyFromName(name string) (int32, bool) {
+	for key, keyName := range keyNames {
+		if keyName == name {
+			return key, true
+		}
+	}
+	return rl.KeyNull, false
+}
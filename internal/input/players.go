@@ -0,0 +1,125 @@
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Most ships that can share one screen in local multiplayer.
+const MAX_PLAYERS = 4
+
+// Keyboard bindings of the other players in local multiplayer. The first
+// player uses the current bindings. They are chosen so that they don't share
+// keys with each other or the default bindings, and none of them can confirm
+// so that menus stay with the first player. Some presets share keys with them,
+// which is sorted out by `otherPlayerBindings`.
+var PlayerBindings = []Bindings{
+	{
+		RotateLeft:  rl.KeyLeft,
+		RotateRight: rl.KeyRight,
+		Thrust:      rl.KeyUp,
+		Reverse:     rl.KeyDown,
+		Fire:        rl.KeyRightControl,
+		Hyperspace:  rl.KeyRightShift,
+		NextWeapon:  rl.KeyPeriod,
+		PrevWeapon:  rl.KeyComma,
+		Confirm:     rl.KeyNull,
+	},
+	{
+		RotateLeft:  rl.KeyJ,
+		RotateRight: rl.KeyL,
+		Thrust:      rl.KeyI,
+		Reverse:     rl.KeyK,
+		Fire:        rl.KeyU,
+		Hyperspace:  rl.KeyO,
+		NextWeapon:  rl.KeyP,
+		PrevWeapon:  rl.KeyY,
+		Confirm:     rl.KeyNull,
+	},
+	{
+		RotateLeft:  rl.KeyKp4,
+		RotateRight: rl.KeyKp6,
+		Thrust:      rl.KeyKp8,
+		Reverse:     rl.KeyKp5,
+		Fire:        rl.KeyKp0,
+		Hyperspace:  rl.KeyKpEnter,
+		NextWeapon:  rl.KeyKp9,
+		PrevWeapon:  rl.KeyKp7,
+		Confirm:     rl.KeyNull,
+	},
+}
+
+// Bindings of the second, third and fourth players for the current bindings.
+var others = otherPlayerBindings(current)
+
+// Hands out the bindings of the other players so that none of them shares a
+// key with the first player's bindings. The sets of `PlayerBindings` which
+// clash with them are given out last, with the clashing keys unbound, so
+// those players are left with their gamepad and whichever keys are free.
+func otherPlayerBindings(first Bindings) []Bindings {
+	bindings := []Bindings{}
+	clashing := []Bindings{}
+	for _, other := range PlayerBindings {
+		if len(first.Clashes(other)) == 0 {
+			bindings = append(bindings, other)
+		} else {
+			clashing = append(clashing, other)
+		}
+	}
+
+	for _, other := range clashing {
+		other = other.Clone()
+		for _, action := range first.Clashes(other) {
+			other[action] = rl.KeyNull
+		}
+		bindings = append(bindings, other)
+	}
+	return bindings
+}
+
+// The keyboard bindings and gamepad that one player's controls are read from.
+type device struct {
+	bindings Bindings
+	pad      int32 // Index of the gamepad, or -1 for none.
+	mouse    bool  // Whether the mouse aims and fires in twin-stick mode.
+}
+
+// Returns the device of a player. The first player uses the current bindings,
+// the first connected gamepad and the mouse. Every other player uses the
+// bindings handed out to them and the gamepad at their own index, unless the
+// first player is already using it.
+func playerDevice(player int) device {
+	if player == 0 {
+		device := device{bindings: current, pad: -1, mouse: true}
+		if gamepad.connected {
+			device.pad = gamepad.index
+		}
+		return device
+	}
+
+	device := device{bindings: others[player-1], pad: -1}
+	pad := int32(player)
+	if rl.IsGamepadAvailable(pad) && !(gamepad.connected && gamepad.index == pad) {
+		device.pad = pad
+	}
+	return device
+}
+
+func (device device) isDown(action Action) bool {
+	key := device.bindings[action]
+	if key != rl.KeyNull && rl.IsKeyDown(key) {
+		return true
+	}
+
+	button, ok := GamepadButtons[action]
+	return ok && device.pad >= 0 && rl.IsGamepadButtonDown(device.pad, button)
+}
+
+func (device device) isPressed(action Action) bool {
+	key := device.bindings[action]
+	if key != rl.KeyNull && rl.IsKeyPressed(key) {
+		return true
+	}
+
+	button, ok := GamepadButtons[action]
+	return ok && device.pad >= 0 && rl.IsGamepadButtonPressed(device.pad, button)
+}
@@ -0,0 +1,60 @@
+package input
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestPlayerBindingsHaveNoConflicts(t *testing.T) {
+	for i, bindings := range PlayerBindings {
+		if err := bindings.Validate(); err != nil {
+			t.Errorf("player %d: %v", i+2, err)
+		}
+		if bindings[Confirm] != rl.KeyNull {
+			t.Errorf("player %d can confirm", i+2)
+		}
+		for j, other := range PlayerBindings[:i] {
+			if clashes := other.Clashes(bindings); len(clashes) > 0 {
+				t.Errorf("players %d and %d share keys for %v", j+2, i+2, clashes)
+			}
+		}
+	}
+}
+
+func TestOtherPlayersDontClashWithPresets(t *testing.T) {
+	for _, preset := range Presets {
+		others := otherPlayerBindings(preset.Bindings)
+		if len(others) != MAX_PLAYERS-1 {
+			t.Fatalf("%s preset: bindings for %d other players, want %d", preset.Name, len(others), MAX_PLAYERS-1)
+		}
+		for i, bindings := range others {
+			if clashes := preset.Bindings.Clashes(bindings); len(clashes) > 0 {
+				t.Errorf("%s preset: player %d shares keys for %v", preset.Name, i+2, clashes)
+			}
+		}
+	}
+}
+
+func TestOtherPlayersSkipClashingBindings(t *testing.T) {
+	// The arrow keys preset clashes with the second player's usual keys, so
+	// they move on to the next set that is free.
+	others := otherPlayerBindings(Presets[2].Bindings)
+	if others[0][Thrust] != PlayerBindings[1][Thrust] || others[1][Thrust] != PlayerBindings[2][Thrust] {
+		t.Errorf("second and third players got %v and %v, want the next free sets", others[0], others[1])
+	}
+	if others[2][Thrust] != rl.KeyNull || others[2][NextWeapon] != PlayerBindings[0][NextWeapon] {
+		t.Errorf("fourth player got %v, want the clashing set with only the clashing keys unbound", others[2])
+	}
+}
+
+func TestSetHandsOutOtherPlayerBindings(t *testing.T) {
+	t.Cleanup(func() { Set(Presets[0].Bindings) })
+
+	Set(Presets[3].Bindings)
+	for player := 1; player < MAX_PLAYERS; player++ {
+		if clashes := Get().Clashes(playerDevice(player).bindings); len(clashes) > 0 {
+			t.Errorf("player %d shares keys for %v with the left-handed preset", player+1, clashes)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Key which opens and closes the rebinding screen. It is fixed so that the
+// screen can always be reached regardless of the current bindings.
+const REBIND_SCREEN_KEY = rl.KeyF1
+
+// In-game screen for rebinding the controls. The screen edits a copy of the
+// bindings which is only applied once the screen is closed without conflicts.
+type RebindScreen struct {
+	IsOpen    bool
+	Bindings  Bindings // Bindings being edited.
+	selected  int      // Index into `Actions` of the highlighted action.
+	preset    int      // Index into `Presets` of the last applied preset.
+	listening bool     // Whether the next key press is bound to the selected action.
+	message   string
+}
+
+// Opens the rebinding screen with a copy of the current bindings.
+func OpenRebindScreen(screen *RebindScreen) {
+	*screen = RebindScreen{
+		IsOpen:   true,
+		Bindings: Get().Clone(),
+		preset:   screen.preset,
+	}
+}
+
+// Handles the keyboard on the rebinding screen. Returns true when the screen
+// was closed and the edited bindings were applied.
+func UpdateRebindScreen(screen *RebindScreen) bool {
+	if screen.listening {
+		key := rl.GetKeyPressed()
+		if key == rl.KeyNull {
+			return false
+		}
+
+		screen.Bindings[Actions[screen.selected]] = key
+		screen.listening = false
+		screen.message = ""
+		return false
+	}
+
+	switch {
+	case rl.IsKeyPressed(REBIND_SCREEN_KEY):
+		if len(screen.Bindings.Conflicts()) > 0 {
+			screen.message = "Resolve the conflicting keys before closing"
+			return false
+		}
+		Set(screen.Bindings)
+		screen.IsOpen = false
+		return true
+	case rl.IsKeyPressed(rl.KeyUp):
+		screen.selected = (screen.selected + len(Actions) - 1) % len(Actions)
+	case rl.IsKeyPressed(rl.KeyDown):
+		screen.selected = (screen.selected + 1) % len(Actions)
+	case rl.IsKeyPressed(rl.KeyLeft):
+		screen.preset = (screen.preset + len(Presets) - 1) % len(Presets)
+		screen.Bindings = Presets[screen.preset].Bindings.Clone()
+	case rl.IsKeyPressed(rl.KeyRight):
+		screen.preset = (screen.preset + 1) % len(Presets)
+		screen.Bindings = Presets[screen.preset].Bindings.Clone()
+	case rl.IsKeyPressed(rl.KeyEnter):
+		screen.listening = true
+		screen.message = fmt.Sprintf("Press a key for %s", Actions[screen.selected])
+	case rl.IsKeyPressed(rl.KeyDelete):
+		screen.Bindings[Actions[screen.selected]] = rl.KeyNull
+	}
+
+	return false
+}
+
+// Draws the rebinding screen over the whole window. All sizes are multiplied
+// by `scale`.
+func DrawRebindScreen(screen RebindScreen, scale float32) {
+	width := int32(rl.GetScreenWidth())
+	height := int32(rl.GetScreenHeight())
+	size := func(pixels float32) int32 {
+		return int32(pixels * scale)
+	}
+	fontSize := size(24)
+	lineHeight := size(36)
+
+	rl.DrawRectangle(0, 0, width, height, rl.Fade(rl.Black, 0.85))
+
+	drawCentred := func(text string, y int32, size int32, color rl.Color) {
+		rl.DrawText(text, width/2-rl.MeasureText(text, size)/2, y, size, color)
+	}
+
+	y := size(120)
+	drawCentred("CONTROLS", y, size(40), rl.RayWhite)
+	y += size(70)
+	drawCentred(fmt.Sprintf("< Preset: %s >", Presets[screen.preset].Name), y, fontSize, rl.Gray)
+	y += 2 * lineHeight
+
+	for i, action := range Actions {
+		color := rl.RayWhite
+		if screen.Bindings.IsConflicting(action) {
+			color = rl.Red
+		}
+
+		name := strings.ToUpper(strings.ReplaceAll(action.String(), "_", " "))
+		key := KeyName(screen.Bindings[action])
+		if i == screen.selected {
+			name = "> " + name
+			if screen.listening {
+				key = "..."
+			}
+		}
+
+		rl.DrawText(name, width/2-size(260), y, fontSize, color)
+		rl.DrawText(key, width/2+size(100), y, fontSize, color)
+		y += lineHeight
+	}
+
+	y += lineHeight
+	if screen.message != "" {
+		drawCentred(screen.message, y, fontSize, rl.Yellow)
+	}
+	y += 2 * lineHeight
+
+	drawCentred("UP/DOWN select   ENTER rebind   DELETE unbind", y, size(20), rl.Gray)
+	drawCentred("LEFT/RIGHT change preset   F1 save and close", y+size(30), size(20), rl.Gray)
+}
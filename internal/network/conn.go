@@ -0,0 +1,180 @@
+package network
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+// Packets which have been received but not yet polled. Any further packets
+// are dropped, as they would be by a full socket buffer.
+const RECEIVE_QUEUE = 1024
+
+// Network conditions simulated on outgoing packets, so that the netcode can
+// be tried out over localhost as if it were played over the internet.
+type Conditions struct {
+	Latency time.Duration // Delay added to every packet.
+	Jitter  time.Duration // Largest random delay added on top of the latency.
+	Loss    float64       // Fraction of packets dropped, in [0, 1].
+}
+
+// Adds flags for the simulated conditions to a flag set, defaulting to the
+// current conditions.
+func (conditions *Conditions) AddFlags(flags *flag.FlagSet) {
+	flags.DurationVar(&conditions.Latency, "latency", conditions.Latency, "simulated delay added to every outgoing packet, e.g. 50ms")
+	flags.DurationVar(&conditions.Jitter, "jitter", conditions.Jitter, "largest random delay added to outgoing packets on top of the latency")
+	flags.Float64Var(&conditions.Loss, "loss", conditions.Loss, "fraction of outgoing packets dropped, from 0 to 1")
+}
+
+// Returns the flags which set these conditions, to pass them on to another
+// process.
+func (conditions Conditions) Args() []string {
+	return []string{
+		"--latency", conditions.Latency.String(),
+		"--jitter", conditions.Jitter.String(),
+		"--loss", fmt.Sprint(conditions.Loss),
+	}
+}
+
+func (conditions Conditions) Validate() error {
+	if conditions.Latency < 0 || conditions.Jitter < 0 {
+		return errors.New("latency and jitter must be >= 0")
+	}
+	if conditions.Loss < 0 || conditions.Loss > 1 {
+		return errors.New("loss must be from 0 to 1")
+	}
+	return nil
+}
+
+// A message and the address it came from.
+type Packet struct {
+	From    *net.UDPAddr
+	Message Message
+}
+
+// A UDP socket which sends and receives messages. Received messages are
+// queued by a background goroutine and collected with `Poll`, so that the
+// game loop never blocks on the network.
+type Conn struct {
+	socket     *net.UDPConn
+	conditions Conditions
+	received   chan Packet
+
+	// The simulated conditions have their own random numbers so that they
+	// don't disturb the seeded game.
+	random *rand.Rand
+}
+
+// Opens a socket listening on `address`, e.g. ":7777". Clients listen on ":0"
+// to be given any free port.
+func Listen(address string, conditions Conditions) (*Conn, error) {
+	local, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	socket, err := net.ListenUDP("udp", local)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &Conn{
+		socket:     socket,
+		conditions: conditions,
+		received:   make(chan Packet, RECEIVE_QUEUE),
+		random:     rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+	go conn.receive()
+	return conn, nil
+}
+
+// Returns the address that the socket is listening on.
+func (conn *Conn) LocalAddr() net.Addr {
+	return conn.socket.LocalAddr()
+}
+
+// Reads packets until the socket is closed. Packets which can't be decoded
+// are ignored.
+func (conn *Conn) receive() {
+	buffer := make([]byte, MAX_PACKET_SIZE)
+	for {
+		n, from, err := conn.socket.ReadFromUDP(buffer)
+		if err != nil {
+			close(conn.received)
+			return
+		}
+
+		message, err := decode(buffer[:n])
+		if err != nil {
+			continue
+		}
+		select {
+		case conn.received <- Packet{From: from, Message: message}:
+		default:
+		}
+	}
+}
+
+// Returns every packet received since the last poll, oldest first.
+func (conn *Conn) Poll() []Packet {
+	packets := []Packet{}
+	for {
+		select {
+		case packet, ok := <-conn.received:
+			if !ok {
+				return packets
+			}
+			packets = append(packets, packet)
+		default:
+			return packets
+		}
+	}
+}
+
+// Sends a message to `to`. The message may be dropped or delayed by the
+// simulated conditions. Delayed messages are sent in the background and any
+// error is lost, as it would be on a real network. Only one goroutine may
+// send at a time.
+func (conn *Conn) Send(to *net.UDPAddr, message Message) error {
+	data, err := encode(message)
+	if err != nil {
+		return err
+	}
+	if len(data) > MAX_PACKET_SIZE {
+		return fmt.Errorf("%s message of %d bytes is too large for a packet", message.Kind, len(data))
+	}
+
+	dropped, delay := conn.simulate()
+	switch {
+	case dropped:
+		return nil
+	case delay > 0:
+		time.AfterFunc(delay, func() {
+			conn.socket.WriteToUDP(data, to)
+		})
+		return nil
+	}
+
+	_, err = conn.socket.WriteToUDP(data, to)
+	return err
+}
+
+// Decides whether the next packet is dropped and how long it is delayed for.
+func (conn *Conn) simulate() (bool, time.Duration) {
+	if conn.random.Float64() < conn.conditions.Loss {
+		return true, 0
+	}
+
+	delay := conn.conditions.Latency
+	if conn.conditions.Jitter > 0 {
+		delay += time.Duration(conn.random.Int64N(int64(conn.conditions.Jitter)))
+	}
+	return false, delay
+}
+
+// Closes the socket. Packets still being delayed are not sent.
+func (conn *Conn) Close() error {
+	return conn.socket.Close()
+}
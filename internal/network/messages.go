@@ -0,0 +1,93 @@
+// Package which carries networked games over UDP. Clients send their
+// controls to an authoritative server, which runs the game and sends back
+// snapshots of it. Alternatively two peers each run the game and send each
+// other their controls, rolling back when they guessed wrong. Outgoing
+// packets can be delayed and dropped on purpose so that the netcode can be
+// tried out over localhost.
+package network
+
+import (
+	"asteroids/internal/input"
+	"bytes"
+	"encoding/gob"
+)
+
+// Clients and servers of different versions can't play together, as the
+// snapshots and controls would not match.
+const VERSION = 4
+
+// Largest payload that fits into a single UDP packet.
+const MAX_PACKET_SIZE = 65507
+
+type Kind int
+
+const (
+	// Sent by a client or joining peer until it is welcomed, asking for a
+	// player slot.
+	Hello Kind = iota
+	// Sent by the server or host in reply to a hello with the player slot.
+	Welcome
+	// Sent by the server or host when a player can't join, e.g. because it
+	// is full.
+	Rejected
+	// Sent by a client or peer every tick with its newest controls.
+	Inputs
+	// Sent by the server at a regular interval with the state of the game.
+	Snapshot
+	// Sent by a client when it leaves, so that its slot is freed straight away.
+	Bye
+)
+
+func (kind Kind) String() string {
+	return [...]string{"Hello", "Welcome", "Rejected", "Inputs", "Snapshot", "Bye"}[kind]
+}
+
+// A single packet of the protocol. Only the fields used by its kind are set.
+type Message struct {
+	Kind     Kind
+	Version  int  // Protocol version of a hello.
+	Spectate bool // Whether a hello asks to watch the game rather than play it.
+
+	Player     int    // Player slot given to the client in a welcome, or -1 for a spectator.
+	Mode       int    // Game mode being played, sent in a welcome.
+	Difficulty int    // Difficulty being played, sent in a welcome.
+	Reason     string // Why the client was rejected.
+	Seed       uint64 // Seed of the game, sent in a welcome from a rollback host.
+	Tuning     uint64 // Checksum of the tuning, sent in a hello to a rollback host.
+
+	// Tick of the newest controls sent by a client or peer, or of the game
+	// when a snapshot was taken.
+	Tick uint32
+	// Newest client tick whose controls the server has applied, sent with
+	// every snapshot so that the client can replay the rest. Between peers,
+	// the number of ticks of controls received from the other peer.
+	Ack uint32
+	// The client's most recent controls with the newest last, ending at
+	// `Tick`. Earlier controls are repeated in case their packet was lost.
+	Controls []input.Controls
+	// Snapshot of the game, encoded by the game.
+	State []byte
+	// Player slots that still need a client before the game starts.
+	Waiting int
+
+	// Newest tick that a peer has checked, and the checksums of the game at
+	// the start of the most recent ticks with the newest last, ending at
+	// `ChecksumTick`. The other peer compares them tick by tick to detect
+	// desyncs. A checksum tick of 0 means that there are no checksums yet.
+	ChecksumTick uint32
+	Checksums    []uint32
+}
+
+func encode(message Message) ([]byte, error) {
+	buffer := bytes.Buffer{}
+	if err := gob.NewEncoder(&buffer).Encode(message); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func decode(data []byte) (Message, error) {
+	message := Message{}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&message)
+	return message, err
+}
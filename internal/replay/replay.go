@@ -0,0 +1,64 @@
+// Package which records the controls of a seeded game so that it can be
+// played back exactly. Every tick of the game is driven by a single
+// `input.Controls` snapshot, so the seed and the snapshots are all that is
+// needed to repeat it.
+package replay
+
+import (
+	"asteroids/internal/input"
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// Replays from a different version can't be played back, as the game would
+// not play out the same.
+const VERSION = 1
+
+type Replay struct {
+	Version  int
+	Seed     uint64
+	Date     string           // Day that the game was played, as YYYY-MM-DD.
+	Controls []input.Controls // Controls used on every tick of the game.
+}
+
+func New(seed uint64, date string) Replay {
+	return Replay{Version: VERSION, Seed: seed, Date: date}
+}
+
+// Adds the controls used on the next tick.
+func (replay *Replay) Record(controls input.Controls) {
+	replay.Controls = append(replay.Controls, controls)
+}
+
+// Reads a replay file.
+func Load(path string) (Replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Replay{}, err
+	}
+	defer file.Close()
+
+	replay := Replay{}
+	if err := gob.NewDecoder(file).Decode(&replay); err != nil {
+		return Replay{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if replay.Version != VERSION {
+		return Replay{}, fmt.Errorf("%s was recorded by version %d, expected version %d", path, replay.Version, VERSION)
+	}
+	return replay, nil
+}
+
+// Writes the replay to a file.
+func Save(path string, replay Replay) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := gob.NewEncoder(file).Encode(replay); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
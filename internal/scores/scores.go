@@ -0,0 +1,69 @@
+// Package which keeps the best scores of each game mode in a file.
+package scores
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Number of scores kept for each game mode.
+const MAX_ENTRIES = 10
+
+type Entry struct {
+	Score      uint64 `json:"score"`
+	Difficulty string `json:"difficulty"`
+	Date       string `json:"date"` // Day the score was set, as YYYY-MM-DD.
+}
+
+// The best scores of each game mode, keyed by the mode's name and sorted from
+// best to worst.
+type Table map[string][]Entry
+
+// Returns the best scores of a game mode.
+func (table Table) Top(mode string) []Entry {
+	return table[mode]
+}
+
+// Adds a score to a game mode's table. Returns its rank starting from 1, or 0
+// if the score was not good enough to be kept.
+func (table Table) Add(mode string, entry Entry) int {
+	entries := table[mode]
+	rank := sort.Search(len(entries), func(i int) bool {
+		return entries[i].Score < entry.Score
+	})
+	if rank >= MAX_ENTRIES {
+		return 0
+	}
+
+	entries = append(entries, Entry{})
+	copy(entries[rank+1:], entries[rank:])
+	entries[rank] = entry
+	table[mode] = entries[:min(len(entries), MAX_ENTRIES)]
+
+	return rank + 1
+}
+
+// Reads a high score file.
+func Load(path string) (Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Table{}, err
+	}
+
+	table := Table{}
+	if err := json.Unmarshal(data, &table); err != nil {
+		return Table{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// Writes the high scores to a file.
+func Save(path string, table Table) error {
+	data, err := json.MarshalIndent(table, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
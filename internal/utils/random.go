@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"math/rand/v2"
+)
+
+// Source of the gameplay random number generator. It can be reseeded so that
+// a game plays out the same every time it is played with the same seed.
+var source = rand.NewPCG(rand.Uint64(), rand.Uint64())
+
+// Random number generator used by all gameplay code. Anything drawn from it
+// must happen in the same order every time for a seeded game to be repeated,
+// so it should not be used for effects that only affect rendering.
+var Rand = rand.New(source)
+
+// Reseeds the gameplay random number generator.
+func Seed(seed uint64) {
+	source.Seed(seed, seed)
+}
+
+// Returns the state of the gameplay random number generator, so that a game
+// can be rewound to an earlier tick with RestoreRand.
+func SaveRand() []byte {
+	// Marshalling a PCG source never fails.
+	state, _ := source.MarshalBinary()
+	return state
+}
+
+// Puts back a state of the gameplay random number generator returned by
+// SaveRand.
+func RestoreRand(state []byte) {
+	if err := source.UnmarshalBinary(state); err != nil {
+		panic("unreachable: random state not from SaveRand: " + err.Error())
+	}
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const SLIDER_FONT_SIZE = 16
+
+// A horizontal slider for editing a value between `Min` and `Max` with the
+// mouse.
+type Slider struct {
+	Label string
+	Min   float32
+	Max   float32
+}
+
+// Returns the value at the mouse's horizontal position along the slider's
+// track, clamped to the slider's range.
+func (slider Slider) ValueAt(bounds rl.Rectangle, mouseX float32) float32 {
+	fraction := rl.Clamp((mouseX-bounds.X)/bounds.Width, 0, 1)
+	return slider.Min + fraction*(slider.Max-slider.Min)
+}
+
+// Draws the slider's track within `bounds` with its label and value above it.
+func DrawSlider(slider Slider, bounds rl.Rectangle, value float32, active bool) {
+	color := rl.Gray
+	if active {
+		color = rl.RayWhite
+	}
+
+	label := fmt.Sprintf("%s: %.3f", slider.Label, value)
+	rl.DrawText(label, int32(bounds.X), int32(bounds.Y)-SLIDER_FONT_SIZE-2, SLIDER_FONT_SIZE, color)
+
+	middle := bounds.Y + bounds.Height/2
+	rl.DrawLineV(rl.Vector2{X: bounds.X, Y: middle}, rl.Vector2{X: bounds.X + bounds.Width, Y: middle}, color)
+
+	fraction := rl.Clamp((value-slider.Min)/(slider.Max-slider.Min), 0, 1)
+	rl.DrawCircleV(rl.Vector2{X: bounds.X + fraction*bounds.Width, Y: middle}, bounds.Height/2, color)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	TOAST_DURATION  = 4.0 // Seconds that a toast stays on screen.
+	TOAST_FONT_SIZE = 20
+	TOAST_MAX_LINES = 8
+)
+
+// A short lived message drawn at the bottom of the screen.
+type Toast struct {
+	Lines []string
+	Color rl.Color
+	Timer float32 // Seconds left before the toast disappears.
+}
+
+// Creates a toast showing the given lines. Only the first few lines are kept
+// so that a large change does not fill the whole screen.
+func NewToast(lines []string, color rl.Color) Toast {
+	if len(lines) > TOAST_MAX_LINES {
+		more := len(lines) - TOAST_MAX_LINES + 1
+		lines = append(lines[:TOAST_MAX_LINES-1:TOAST_MAX_LINES-1], fmt.Sprintf("... and %d more", more))
+	}
+
+	return Toast{Lines: lines, Color: color, Timer: TOAST_DURATION}
+}
+
+// Counts down the toast timer.
+func UpdateToast(toast *Toast, dt float32) {
+	if toast.Timer > 0 {
+		toast.Timer -= dt
+	}
+}
+
+// Draws the toast in the bottom left corner of a window of the given height.
+// The toast fades out during its last second.
+func DrawToast(toast Toast, screenHeight int32, scale float32) {
+	if toast.Timer <= 0 {
+		return
+	}
+
+	alpha := min(toast.Timer, 1.0)
+	fontSize := int32(TOAST_FONT_SIZE * scale)
+	lineHeight := fontSize + int32(4*scale)
+	margin := int32(16 * scale)
+
+	y := screenHeight - margin - int32(len(toast.Lines))*lineHeight
+	for _, line := range toast.Lines {
+		rl.DrawText(line, margin, y, fontSize, rl.Fade(toast.Color, alpha))
+		y += lineHeight
+	}
+}
@@ -2,9 +2,6 @@
 package utils
 
 import (
-	"asteroids/internal/constants"
-	"math/rand/v2"
-
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -16,6 +13,18 @@ func DrawLines(
 	thickness float32,
 	rotation float32,
 	points []rl.Vector2,
+) {
+	DrawLinesColor(origin, scale, thickness, rotation, points, rl.RayWhite)
+}
+
+// Same as `DrawLines` but with a colour other than white.
+func DrawLinesColor(
+	origin rl.Vector2,
+	scale float32,
+	thickness float32,
+	rotation float32,
+	points []rl.Vector2,
+	color rl.Color,
 ) {
 	// Lambda function to scale points
 	scalePoints := func(origin rl.Vector2, scale float32, rot float32, p rl.Vector2) rl.Vector2 {
@@ -27,29 +36,33 @@ func DrawLines(
 			scalePoints(origin, scale, rotation, points[i]),
 			scalePoints(origin, scale, rotation, points[(i+1)%len(points)]),
 			thickness,
-			rl.RayWhite,
+			color,
 		)
 	}
 }
 
 // Returns a float32 in range of [minimum, maximum].
 func RandInRange(minimum float32, maximum float32) float32 {
-	return minimum + rand.Float32()*(maximum-minimum)
+	return minimum + Rand.Float32()*(maximum-minimum)
 }
 
-func DrawGameOverScreen() {
+// Draws the game over message in the middle of a window of the given size.
+func DrawGameOverScreen(width int32, height int32, scale float32) {
+	titleSize := int32(40 * scale)
+	hintSize := int32(20 * scale)
+
 	rl.DrawText(
 		"GAME OVER",
-		constants.SCREEN_WIDTH/2-rl.MeasureText("GAME OVER", 40)/2,
-		constants.SCREEN_HEIGHT/2-20,
-		40,
+		width/2-rl.MeasureText("GAME OVER", titleSize)/2,
+		height/2-titleSize/2,
+		titleSize,
 		rl.Red,
 	)
 	rl.DrawText(
-		"Press ENTER to Restart",
-		constants.SCREEN_WIDTH/2-rl.MeasureText("Press ENTER to Restart", 20)/2,
-		constants.SCREEN_HEIGHT/2+30,
-		20,
+		"Press ENTER to Restart or M for the Menu",
+		width/2-rl.MeasureText("Press ENTER to Restart or M for the Menu", hintSize)/2,
+		height/2+int32(30*scale),
+		hintSize,
 		rl.RayWhite,
 	)
 }
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"asteroids/internal/constants"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Key which toggles between windowed and fullscreen.
+const FULLSCREEN_KEY = rl.KeyF11
+
+// The playfield is drawn at a fixed virtual resolution into a texture which is
+// then scaled to fit the window. Any space left over is letterboxed.
+type Viewport struct {
+	Target rl.RenderTexture2D
+	Scale  float32    // Scale from playfield pixels to window pixels.
+	Offset rl.Vector2 // Top left corner of the playfield in the window.
+}
+
+// Creates the playfield texture. Must be called after the window is opened.
+func NewViewport() Viewport {
+	viewport := Viewport{
+		Target: rl.LoadRenderTexture(constants.SCREEN_WIDTH, constants.SCREEN_HEIGHT),
+	}
+	rl.SetTextureFilter(viewport.Target.Texture, rl.FilterBilinear)
+	UpdateViewport(&viewport)
+
+	return viewport
+}
+
+// Recalculates the scale and letterbox offset for the current window size and
+// toggles fullscreen when the fullscreen key is pressed.
+func UpdateViewport(viewport *Viewport) {
+	if rl.IsKeyPressed(FULLSCREEN_KEY) {
+		rl.ToggleBorderlessWindowed()
+	}
+
+	fitViewport(viewport, float32(rl.GetScreenWidth()), float32(rl.GetScreenHeight()))
+}
+
+// Scales the playfield to fit a window of the given size, keeping its aspect
+// ratio and centring it.
+func fitViewport(viewport *Viewport, width float32, height float32) {
+	viewport.Scale = min(width/constants.SCREEN_WIDTH, height/constants.SCREEN_HEIGHT)
+	viewport.Offset = rl.Vector2{
+		X: (width - constants.SCREEN_WIDTH*viewport.Scale) / 2,
+		Y: (height - constants.SCREEN_HEIGHT*viewport.Scale) / 2,
+	}
+}
+
+// Converts a point in window pixels into playfield coordinates.
+func (viewport Viewport) ToPlayfield(point rl.Vector2) rl.Vector2 {
+	return rl.Vector2Scale(rl.Vector2Subtract(point, viewport.Offset), 1/viewport.Scale)
+}
+
+// Returns the mouse position in playfield coordinates.
+func (viewport Viewport) MousePosition() rl.Vector2 {
+	return viewport.ToPlayfield(rl.GetMousePosition())
+}
+
+// Draws the playfield texture scaled into the window. Render textures are
+// stored upside down so the source rectangle flips them back.
+func DrawViewport(viewport Viewport) {
+	source := rl.Rectangle{
+		Width:  float32(viewport.Target.Texture.Width),
+		Height: -float32(viewport.Target.Texture.Height),
+	}
+	dest := rl.Rectangle{
+		X:      viewport.Offset.X,
+		Y:      viewport.Offset.Y,
+		Width:  constants.SCREEN_WIDTH * viewport.Scale,
+		Height: constants.SCREEN_HEIGHT * viewport.Scale,
+	}
+
+	rl.DrawTexturePro(viewport.Target.Texture, source, dest, rl.Vector2{}, 0, rl.White)
+}
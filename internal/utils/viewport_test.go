@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"asteroids/internal/constants"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestFitViewport(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height float32
+		scale         float32
+		offset        rl.Vector2
+	}{
+		{"same size", constants.SCREEN_WIDTH, constants.SCREEN_HEIGHT, 1, rl.Vector2{}},
+		{"twice the size", 2 * constants.SCREEN_WIDTH, 2 * constants.SCREEN_HEIGHT, 2, rl.Vector2{}},
+		{"wide", 1920, 960, 1, rl.Vector2{X: 320}},
+		{"tall", 640, 960, 0.5, rl.Vector2{Y: 240}},
+	}
+	for _, test := range tests {
+		viewport := Viewport{}
+		fitViewport(&viewport, test.width, test.height)
+		if viewport.Scale != test.scale || viewport.Offset != test.offset {
+			t.Errorf("%s: scale %v and offset %v, want %v and %v", test.name, viewport.Scale, viewport.Offset, test.scale, test.offset)
+		}
+	}
+}
+
+func TestToPlayfield(t *testing.T) {
+	viewport := Viewport{}
+	fitViewport(&viewport, 1920, 960)
+
+	corners := map[rl.Vector2]rl.Vector2{
+		{X: 320, Y: 0}:    {},
+		{X: 1600, Y: 960}: {X: constants.SCREEN_WIDTH, Y: constants.SCREEN_HEIGHT},
+		{X: 0, Y: 480}:    {X: -320, Y: 480}, // In the letterbox.
+	}
+	for window, want := range corners {
+		if got := viewport.ToPlayfield(window); got != want {
+			t.Errorf("ToPlayfield(%v) = %v, want %v", window, got, want)
+		}
+	}
+}
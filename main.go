@@ -1,10 +1,19 @@
 package main
 
 import (
+	"asteroids/internal/audio"
+	"asteroids/internal/config"
 	"asteroids/internal/constants"
 	"asteroids/internal/entities"
+	"asteroids/internal/input"
+	"asteroids/internal/network"
+	"asteroids/internal/replay"
+	"asteroids/internal/scores"
 	"asteroids/internal/utils"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -17,111 +26,89 @@ const (
 	THICKNESS = constants.THICKNESS
 	SCALE     = constants.SCALE
 
-	// Default game parameters
-	ASTEROID_SPAWN_INTERVAL = constants.ASTEROID_SPAWN_INTERVAL
+	// Seconds of game time that pass every tick. Timers count in ticks rather
+	// than the measured frame time, as movement already does.
+	TICK = 1.0 / constants.TICK_RATE
+
+	// Seconds that the HUD flashes for when an extra life is awarded.
+	LIFE_FLASH_DURATION = 1.5
 )
 
 type GameState struct {
-	ship          entities.Ship
+	players       []Player            // One player for every ship on the screen.
+	pilots        []*Pilot            // Autopilot flying each player, or nil for players at the keyboard.
 	asteroids     []entities.Asteroid // Slice of asteroids present in the game.
 	asteroidTimer float32             // The spawn timer for the asteroids.
+	target        int                 // Index of the player that the last asteroid was sent towards.
 	bullets       []entities.Bullet
-	bulletTimer   float32
-	lives         uint8
 	isGameOver    bool
-	Score         uint64
-}
 
-func NewGameState() GameState {
-	return GameState{
-		ship:          entities.NewShip(),
-		asteroids:     []entities.Asteroid{},
-		asteroidTimer: 0,
-		bullets:       []entities.Bullet{},
-		bulletTimer:   0,
-		lives:         3,
-		isGameOver:    false,
-		Score:         0,
-	}
-}
+	mode          GameMode
+	timeLeft      float32 // Seconds left to score in time attack.
+	highScoreRank int     // Rank of the finished game in its mode's high scores, or 0.
 
-func render(state *GameState) {
-	// Renders the lives counter in the top right of the window.
-	livesStr := fmt.Sprintf("Lives: %o", state.lives)
-	rl.DrawText(livesStr, SCREEN_WIDTH-16-rl.MeasureText(livesStr, 30), 20, 30, rl.RayWhite)
-
-	// Renders the death timer of the ship in the middle of the screen.
-	if state.ship.IsDead() {
-		deathStr := fmt.Sprintf("Respawning in %.0f", state.ship.DeathTimer)
-		rl.DrawText(
-			deathStr,
-			SCREEN_WIDTH/2-rl.MeasureText(deathStr, 30)/2,
-			SCREEN_HEIGHT/2,
-			30,
-			rl.RayWhite,
-		)
-	}
+	elapsed        float32       // Seconds survived in survival.
+	survivalPoints float32       // Fraction of a point earned for survival time but not yet scored.
+	stormTimer     float32       // Seconds since the last survival storm.
+	storms         int           // Survival storms so far.
+	stormEdge      entities.Edge // Edge that the next survival storm comes from.
 
-	// Renders the score in the top left of the window.
-	scoreStr := fmt.Sprintf("Score: %d", state.Score)
-	rl.DrawText(scoreStr, 16, 20, 30, rl.RayWhite)
+	sandbox Sandbox // Tools used in the zen sandbox.
+	match   Match   // Rounds of a versus match.
 
-	// If the ship is moving forward, then we draw thrusters onto the ship
-	// for the effect.
-	entities.RenderShip(&state.ship)
+	powerUps []entities.PowerUp // Pickups waiting to be collected.
 
-	// ------------------------------------------------------------------------
-	// Bullet rendering
-	// ------------------------------------------------------------------------
-	if rl.IsKeyDown(rl.KeySpace) && state.bulletTimer <= 0 {
-		bullet := entities.NewBullet(state.ship.Pos, state.ship.Rot)
-		fmt.Println(bullet)
-		entities.DrawBullet(bullet)
-		state.bullets = append(state.bullets, bullet)
-		state.bulletTimer = 1
-	}
+	explosions []entities.Explosion // Rings drawn where explosive asteroids were destroyed.
 
-	for _, bullet := range state.bullets {
-		entities.DrawBullet(bullet)
-	}
-	// ------------------------------------------------------------------------
+	hazards []entities.Hazard // Gravity wells and black holes placed at the start of the game.
 
-	// ------------------------------------------------------------------------
-	// Asteroid rendering
-	// ------------------------------------------------------------------------
-	if state.asteroidTimer >= ASTEROID_SPAWN_INTERVAL {
-		// Creating a new asteroid to spawn in.
-		asteroid := entities.SpawnAsteroid(state.ship.Pos, -1)
+	adaptive Adaptive
+	popups   []entities.Popup // Floating scores shown where points were earned.
 
-		// Add new asteroid into the game state.
-		state.asteroids = append(state.asteroids, asteroid)
-		state.asteroidTimer = 0
-	}
+	boss             entities.Boss
+	bossTimer        float32 // Seconds of play since the last boss encounter.
+	bossWarningTimer float32 // Seconds left of the warning banner for an incoming boss.
+}
 
-	// Render any asteroids that are already in the game.
-	for _, asteroid := range state.asteroids {
-		entities.DrawAsteroid(asteroid)
-	}
-	// ------------------------------------------------------------------------
+func NewGameState(mode GameMode, numPlayers int) GameState {
+	// Every game starts from the difficulty preset, without the adjustments
+	// made by the adaptive difficulty during the last game.
+	config.SetAdjustment(config.NO_ADJUSTMENT)
 
-	// Increment/decrement timers.
-	state.asteroidTimer += rl.GetFrameTime()
-	state.bulletTimer -= rl.GetFrameTime()
+	players := []Player{}
+	for i := range numPlayers {
+		players = append(players, NewPlayer(i, numPlayers, mode))
+	}
 
-	// If the game is over, render the game over screen.
-	if state.isGameOver {
-		utils.DrawGameOverScreen()
+	state := GameState{
+		players:       players,
+		asteroids:     []entities.Asteroid{},
+		asteroidTimer: 0,
+		bullets:       []entities.Bullet{},
+		powerUps:      []entities.PowerUp{},
+		hazards:       entities.PlaceHazards(config.Get().Hazards, mode.Key()),
+		mode:          mode,
+		timeLeft:      config.Get().TimeAttack.Duration,
+		stormEdge:     entities.RandomEdge(),
+		sandbox:       NewSandbox(),
+		match:         NewMatch(),
+		isGameOver:    false,
 	}
+	if mode == Versus {
+		placeCover(&state)
+	}
+	return state
 }
 
 // Iterates through the existing asteroids in the game and updates their positions
 // based on their velocity and direction.
 func updateAsteroidPositions(state *GameState) {
 	if len(state.asteroids) > 0 {
+		timeScale := asteroidTimeScale(state)
 		for i := range state.asteroids {
 			state.asteroids[i].Pos = rl.Vector2Add(
 				state.asteroids[i].Pos,
-				rl.Vector2Multiply(state.asteroids[i].Vel, state.asteroids[i].Dir),
+				rl.Vector2Scale(rl.Vector2Multiply(state.asteroids[i].Vel, state.asteroids[i].Dir), timeScale),
 			)
 		}
 
@@ -141,29 +128,61 @@ func updateAsteroidPositions(state *GameState) {
 	}
 }
 
-// The ship and asteroid have circular hitboxes which we check for any collisions
-// between them.
+// The ships and asteroids have circular hitboxes which we check for any
+// collisions between them.
 func checkForShipAsteroidCollisions(state *GameState) {
-	for _, asteroid := range state.asteroids {
-		// If the asteroid hits the ship, then the ship dies and we introduce
-		// a 5 second death timer.
-		if rl.CheckCollisionCircles(
-			state.ship.Pos,
-			entities.SHIP_HITBOX_RADIUS,
-			asteroid.Pos,
-			float32(asteroid.Hitbox),
-		) && !state.ship.IsDead() {
-			state.ship.DeathTimer += 5
-			state.lives -= 1
+	for i := range state.players {
+		player := &state.players[i]
+
+		for j := len(state.asteroids) - 1; j >= 0; j-- {
+			// Exploding asteroids can remove several asteroids at once.
+			if j >= len(state.asteroids) {
+				continue
+			}
+
+			if player.ship.IsDead() || !rl.CheckCollisionCircles(
+				player.ship.Pos,
+				entities.SHIP_HITBOX_RADIUS,
+				state.asteroids[j].Pos,
+				float32(state.asteroids[j].Hitbox),
+			) {
+				continue
+			}
+
+			// A charged shield absorbs the hit and destroys the asteroid instead.
+			if player.ship.Shields > 0 {
+				player.ship.Shields -= 1
+				destroyAsteroid(state, player, j)
+				continue
+			}
+
+			killShip(state, player)
 		}
 	}
 }
 
+// If a ship is hit, then the ship dies and we introduce a death timer.
+func killShip(state *GameState, player *Player) {
+	// The ship can't die in the zen sandbox.
+	if state.mode == Zen {
+		return
+	}
+
+	player.ship.DeathTimer += respawnTime(state)
+	loseLife(state, player)
+	breakCombo(player)
+	state.adaptive.deaths++
+	state.adaptive.alive = 0
+	input.SendFeedback(player.index, input.FeedbackDeath)
+}
+
 // Update all bullets positions.
 func updateBulletPositions(state *GameState) {
 	if len(state.bullets) > 0 {
 		// Update bullet start and ending position.
 		for i := range state.bullets {
+			entities.SteerMissile(&state.bullets[i], state.asteroids)
+
 			state.bullets[i].Start = rl.Vector2Add(
 				state.bullets[i].Start,
 				rl.Vector2Multiply(state.bullets[i].Vel, state.bullets[i].Dir),
@@ -196,36 +215,49 @@ func checkForBulletAsteroidCollisions(state *GameState) {
 
 	for i := len(state.bullets) - 1; i >= 0; i-- {
 		for j := len(state.asteroids) - 1; j >= 0; j-- {
+			// Piercing bullets can only hit each asteroid once.
+			if state.bullets[i].HasHit(state.asteroids[j]) {
+				continue
+			}
+
 			// Check if the bullet collides with an asteroid
 			if rl.CheckCollisionCircles(
-				state.bullets[i].Start, // Bullet tip
-				2,                      // Small radius for the bullet
-				state.asteroids[j].Pos, // Asteroid center
+				state.bullets[i].Start,  // Bullet tip
+				state.bullets[i].Radius, // Radius around the bullet tip
+				state.asteroids[j].Pos,  // Asteroid center
 				float32(state.asteroids[j].Hitbox),
 			) {
-				// Increase score
-				state.Score += state.asteroids[j].Score
-
-				// Decrement the asteroid health and remove it if it's health is 0.
-				state.asteroids[j].Health -= 1
-				if state.asteroids[j].Health <= 0 {
-					if state.asteroids[j].Size == entities.Large {
-						// Create two medium asteroids when a large asteroid is destroyed.
-						mediumAsteroids := entities.SplitAsteroid(state.asteroids[j])
-						state.asteroids = append(state.asteroids, mediumAsteroids...)
-					} else if state.asteroids[j].Size == entities.Medium {
-						// Create two small asteroids when a medium asteroid is destroyed.
-						// Asteroids will float in a random direction.
-						smallAsteroids := entities.SplitAsteroid(state.asteroids[j])
-						state.asteroids = append(state.asteroids, smallAsteroids...)
-					}
+				// Metallic asteroids can deflect bullets without taking damage.
+				if entities.Deflects(state.asteroids[j], state.bullets[i]) {
+					entities.DeflectBullet(&state.bullets[i], state.asteroids[j])
+					break
+				}
 
-					// Remove this asteroid from the game.
-					state.asteroids = append(state.asteroids[:j], state.asteroids[j+1:]...)
+				// The hit is scored for the player who fired the bullet.
+				// Piercing bullets only count towards accuracy on their first
+				// hit, while deflections don't count at all.
+				owner := &state.players[state.bullets[i].Owner]
+				if !state.bullets[i].Scored {
+					owner.combo.shotHits++
+					state.adaptive.shotHits++
+					state.bullets[i].Scored = true
+				}
+				state.bullets[i].HitIDs = append(state.bullets[i].HitIDs, state.asteroids[j].ID)
+				firstFragment := entities.NextAsteroidID()
+				damageAsteroid(state, owner, j, state.bullets[i].Damage)
+
+				// The fragments of a destroyed asteroid split off right under
+				// a piercing bullet, which mustn't hit them straight away.
+				for _, asteroid := range state.asteroids {
+					if state.bullets[i].Piercing && asteroid.ID >= firstFragment {
+						state.bullets[i].HitIDs = append(state.bullets[i].HitIDs, asteroid.ID)
+					}
 				}
 
-				// Remove the bullet from the game.
-				state.bullets = append(state.bullets[:i], state.bullets[i+1:]...)
+				// Remove the bullet from the game unless it pierces through.
+				if !state.bullets[i].Piercing {
+					state.bullets = append(state.bullets[:i], state.bullets[i+1:]...)
+				}
 
 				// A bullet can only destroy one asteroid at a time.
 				break
@@ -234,51 +266,576 @@ func checkForBulletAsteroidCollisions(state *GameState) {
 	}
 }
 
-func update(state *GameState) {
-	if state.isGameOver {
-		if rl.IsKeyPressed(rl.KeyEnter) {
-			*state = NewGameState()
+// Damages the asteroid at index `j` and destroys it once its health reaches 0.
+// Every hit increases the player's score and continues their combo.
+func damageAsteroid(state *GameState, player *Player, j int, damage int) {
+	scoreHit(state, player, state.asteroids[j].Pos, state.asteroids[j].Score)
+
+	// Decrement the asteroid health and remove it if it's health is 0.
+	state.asteroids[j].Health -= damage
+	if state.asteroids[j].Health <= 0 {
+		destroyAsteroid(state, player, j)
+	}
+}
+
+// Removes the asteroid at index `j` from the game. Medium and large asteroids
+// split into smaller asteroids which float in a random direction, and the
+// asteroid may drop a power-up. Explosive asteroids damage their neighbours
+// and crystal asteroids drop gems. The player who destroyed the asteroid
+// scores anything caught in an explosion.
+func destroyAsteroid(state *GameState, player *Player, j int) {
+	asteroid := state.asteroids[j]
+	input.SendFeedback(player.index, input.FeedbackExplosion)
+
+	// Remove this asteroid from the game before adding the pieces so that the
+	// indices of the remaining asteroids are unaffected.
+	state.asteroids = append(state.asteroids[:j], state.asteroids[j+1:]...)
+
+	// The blast goes off before the asteroid splits, so that it only catches
+	// the neighbours and not the asteroid's own pieces.
+	if asteroid.Material == entities.Explosive {
+		explode(state, player, asteroid)
+	}
+	state.asteroids = append(state.asteroids, entities.SplitAsteroid(asteroid)...)
+
+	dropPowerUp(state, asteroid.Pos)
+	state.powerUps = append(state.powerUps, entities.CrystalGems(asteroid)...)
+}
+
+// Damages every asteroid caught in the blast of an exploding asteroid. The
+// targets are found up front and looked up by ID, as each hit can destroy
+// asteroids and cause further explosions.
+func explode(state *GameState, player *Player, asteroid entities.Asteroid) {
+	state.explosions = append(state.explosions, entities.NewExplosion(asteroid.Pos))
+
+	for _, id := range entities.ExplosionTargets(asteroid, state.asteroids) {
+		if j := indexOfAsteroid(state, id); j >= 0 {
+			damageAsteroid(state, player, j, entities.EXPLOSION_DAMAGE)
+		}
+	}
+}
+
+// Returns the index of the asteroid with the given ID, or -1 if it has been
+// destroyed.
+func indexOfAsteroid(state *GameState, id uint32) int {
+	for j, asteroid := range state.asteroids {
+		if asteroid.ID == id {
+			return j
+		}
+	}
+	return -1
+}
+
+// Pulls the ships towards any nearby magnetic asteroids and counts down the
+// explosion effects.
+func updateMaterialEffects(state *GameState) {
+	for i := range state.players {
+		ship := &state.players[i].ship
+		if ship.IsDead() {
+			continue
+		}
+		for _, asteroid := range state.asteroids {
+			ship.Pos = rl.Vector2Add(ship.Pos, entities.MagneticPull(asteroid, ship.Pos))
+		}
+	}
+
+	for i := len(state.explosions) - 1; i >= 0; i-- {
+		state.explosions[i].Timer -= TICK
+		if state.explosions[i].Timer <= 0 {
+			state.explosions = append(state.explosions[:i], state.explosions[i+1:]...)
+		}
+	}
+}
+
+// Awards an extra life each time a player's score passes their next
+// threshold. Lives past the cap are not awarded, but the threshold still
+// counts as passed.
+func awardExtraLives(state *GameState, player *Player) {
+	tuning := config.Get().Lives
+
+	for {
+		threshold, ok := tuning.ExtraLifeThreshold(player.extraLivesAwarded)
+		if !ok || player.Score < threshold {
+			return
+		}
+
+		player.extraLivesAwarded++
+		gainLife(state, player)
+	}
+}
+
+// Gives the player an extra life unless they are already at the lives cap.
+// Time attack has no lives, so the time lost for a death is given back instead,
+// and survival only ever has one life, as does each versus round. Downed
+// players have to be revived instead.
+func gainLife(state *GameState, player *Player) {
+	if state.mode == Survival || state.mode == Versus || player.IsDown() {
+		return
+	}
+	if state.mode == TimeAttack {
+		bonus := config.Get().TimeAttack.DeathPenalty
+		state.timeLeft += bonus
+		player.lifeFlashTimer = LIFE_FLASH_DURATION
+		state.popups = append(state.popups, entities.NewPopup(player.ship.Pos, fmt.Sprintf("+%.0fs", bonus), rl.Green))
+		input.SendFeedback(player.index, input.FeedbackExtraLife)
+		return
+	}
+
+	if player.lives < config.Get().Lives.Max {
+		player.lives++
+		player.lifeFlashTimer = LIFE_FLASH_DURATION
+		input.SendFeedback(player.index, input.FeedbackExtraLife)
+	}
+}
+
+// Spawns a new asteroid every spawn interval.
+func spawnAsteroids(state *GameState) {
+	// The boss takes over spawning while it is active, and asteroids are only
+	// placed by hand in the zen sandbox.
+	if state.boss.Active || state.mode == Zen {
+		return
+	}
+
+	state.asteroidTimer += TICK * asteroidTimeScale(state)
+
+	if state.asteroidTimer >= config.Get().AsteroidSpawnInterval {
+		// Creating a new asteroid to spawn in. With several ships the
+		// asteroids take turns going after each of them.
+		asteroid := entities.SpawnAsteroid(nextTarget(state), -1)
+
+		// Add new asteroid into the game state.
+		state.asteroids = append(state.asteroids, asteroid)
+		state.asteroidTimer = 0
+	}
+}
+
+// Starts a new game of the given mode for `numPlayers` ships. Versus needs at
+// least two ships. Daily challenges are played alone under the fixed rules
+// with the day's seed, and are either recorded or, when `run` is playing back
+// a replay, restarted from the beginning of the replay.
+func newGame(mode GameMode, numPlayers int, run *DailyRun) GameState {
+	if mode == Versus {
+		numPlayers = max(numPlayers, 2)
+	}
+
+	config.SetFixedRules(mode == Daily)
+	if mode == Daily {
+		numPlayers = 1
+		if run.Playback {
+			run.tick = 0
+		} else {
+			*run = NewDailyRun(today())
+		}
+		utils.Seed(run.Replay.Seed)
+	}
+
+	return NewGameState(mode, numPlayers)
+}
+
+// Returns the controls of every player for the next tick, or false if the game
+// shouldn't advance this frame. Replays supply their recorded controls, daily
+// attempts are recorded, and the zen sandbox tools take over the mouse while
+// they are in use and can freeze time. The cursor is the mouse position in
+// playfield coordinates, used for aiming in twin-stick mode.
+func pollControls(state *GameState, run *DailyRun, cursor rl.Vector2) ([]input.Controls, bool) {
+	if state.mode == Daily && run.Playback {
+		controls, ok := run.Next()
+		return []input.Controls{controls}, ok
+	}
+
+	panelInUse := false
+	if state.mode == Zen {
+		panelInUse = updateSandbox(state, cursor)
+		if state.sandbox.Frozen {
+			return nil, false
+		}
+	}
+
+	controls := []input.Controls{}
+	for i, player := range state.players {
+		if isPiloted(state, i) {
+			controls = append(controls, state.pilots[i].Fly(state, i))
+			continue
 		}
+		controls = append(controls, input.PollPlayer(i, player.ship.Pos, cursor))
+	}
+	// Only the first player uses the mouse.
+	if panelInUse {
+		controls[0].Fire = false
+	}
+	if state.mode == Daily {
+		run.Replay.Record(controls[0])
+	}
+	return controls, true
+}
+
+// Advances the game by one tick. Everything that happens during the tick is
+// decided by the state and the controls of every player, so a seeded game
+// plays out the same when it is given the same controls.
+func update(state *GameState, controls []input.Controls) {
+	if state.isGameOver {
 		return
 	}
 
-	// Updates the ship based on movement or death.
-	entities.UpdateShip(&state.ship)
+	// Nothing moves while the scoreboard is shown between versus rounds.
+	if updateRoundBreak(state) {
+		return
+	}
+
+	// Updates the ships based on the controls or death.
+	for i := range state.players {
+		entities.UpdateShip(&state.players[i].ship, controls[i])
+		fireWeapon(state, &state.players[i], controls[i])
+	}
 
 	// Update foreign entities positions.
+	spawnAsteroids(state)
 	updateAsteroidPositions(state)
 	updateBulletPositions(state)
+	updateHazards(state)
+
+	updatePowerUps(state)
+	updateMaterialEffects(state)
+	updateBoss(state)
+	updateCombo(state)
+	updateAdaptive(state)
 
 	// Check for any entity collisions.
 	checkForShipAsteroidCollisions(state)
 	checkForBulletAsteroidCollisions(state)
-
-	// Updating the death timer of the ship.
-	if state.ship.DeathTimer > 0 {
-		state.ship.DeathTimer -= rl.GetFrameTime()
+	checkForBulletBossCollisions(state)
+	checkForShipHits(state)
+
+	// Updating the death timers of the ships and reviving downed players.
+	updateDeathTimers(state)
+	updateRevives(state)
+
+	for i := range state.players {
+		player := &state.players[i]
+		awardExtraLives(state, player)
+		if player.lifeFlashTimer > 0 {
+			player.lifeFlashTimer -= TICK
+		}
 	}
 
-	// If there is no more lives left, set the game state to be over.
-	if state.lives <= 0 {
+	// If there is no more lives or time left, set the game state to be over.
+	updateMode(state)
+	if isModeOver(state) {
 		state.isGameOver = true
 	}
 }
 
+// Reloads the tuning file if it has changed and shows the changed values, or
+// the reason the edit was rejected, in a toast.
+func pollTuning(watcher *config.Watcher, toast *utils.Toast) {
+	utils.UpdateToast(toast, rl.GetFrameTime())
+
+	changes, err := watcher.Poll(rl.GetFrameTime())
+	if err != nil {
+		lines := append([]string{"Rejected " + watcher.Path + ":"}, strings.Split(err.Error(), "\n")...)
+		*toast = utils.NewToast(lines, rl.Red)
+	} else if len(changes) > 0 {
+		lines := append([]string{"Reloaded " + watcher.Path + ":"}, changes...)
+		*toast = utils.NewToast(lines, rl.Green)
+	}
+}
+
+// Persists the current controls to the bindings file.
+func saveBindings(toast *utils.Toast) {
+	if err := input.Save(constants.BINDINGS_FILE, input.Get()); err != nil {
+		*toast = utils.NewToast([]string{"Could not save controls: " + err.Error()}, rl.Red)
+		return
+	}
+	*toast = utils.NewToast([]string{"Controls saved to " + constants.BINDINGS_FILE}, rl.Green)
+}
+
+// Commands run instead of the game when named as the first argument.
+var subcommands = map[string]func(args []string) error{
+	"server":        runServer,
+	"rollback-test": runRollbackTest,
+	"rollback-bot":  runRollbackBot,
+	"env":           runEnv,
+}
+
 func main() {
+	// Subcommands have their own flags, as they run without a window.
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	difficultyName := flag.String("difficulty", "normal", "difficulty preset: easy, normal, hard or arcade")
+	adaptive := flag.Bool("adaptive", false, "adjust the spawn rate and asteroid speed to how well the player is doing")
+	modeName := flag.String("mode", "", "game mode to start straight away instead of showing the menu: "+modeKeys())
+	replayPath := flag.String("replay", "", "daily challenge replay file to play back")
+	numPlayers := flag.Int("players", 1, fmt.Sprintf("number of ships in local multiplayer, from 1 to %d", input.MAX_PLAYERS))
+	bots := flag.Int("bots", 0, "number of the players flown by the autopilot, counting back from the last player")
+	botSkillName := flag.String("bot-skill", "veteran", "skill of the autopilot: rookie, veteran or ace")
+	address := flag.String("connect", "", "address of a server to play on online, e.g. localhost:7777")
+	spectateAddress := flag.String("spectate", "", "address of a server or broadcast game to watch, e.g. localhost:7777")
+	broadcastAddress := flag.String("broadcast", "", "address to broadcast the local game to spectators on, e.g. :7779")
+	hostAddress := flag.String("host", "", "address to host a peer-to-peer versus match with rollback on, e.g. :7778")
+	joinAddress := flag.String("join", "", "address of a peer hosting a versus match with rollback, e.g. localhost:7778")
+	conditions := network.Conditions{}
+	conditions.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *numPlayers < 1 || *numPlayers > input.MAX_PLAYERS {
+		fmt.Fprintf(os.Stderr, "players must be from 1 to %d\n", input.MAX_PLAYERS)
+		os.Exit(2)
+	}
+	if *bots < 0 || *bots > *numPlayers {
+		fmt.Fprintln(os.Stderr, "bots must be from 0 to the number of players")
+		os.Exit(2)
+	}
+	botSkill, ok := ParsePilotSkill(*botSkillName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown bot skill %q\n", *botSkillName)
+		os.Exit(2)
+	}
+
+	if err := conditions.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// The menu is skipped when a mode or a replay is given on the command
+	// line, or when playing online as the server picks the mode.
+	menu := Menu{Players: *numPlayers, Bots: *bots, BotSkill: botSkill}
+	run := DailyRun{}
+	var client *Client
+	var session *RollbackSession
+	var broadcaster *Broadcaster
+	camera := NewSpectatorCamera()
+	switch {
+	case *address != "" || *spectateAddress != "":
+		var err error
+		if *spectateAddress != "" {
+			client, err = Connect(*spectateAddress, conditions, true)
+		} else {
+			client, err = Connect(*address, conditions, false)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer client.Close()
+	case *hostAddress != "" || *joinAddress != "":
+		var err error
+		if *joinAddress != "" {
+			session, err = JoinRollback(*joinAddress, conditions)
+		} else {
+			session, err = HostRollback(*hostAddress, conditions)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer session.Close()
+	case *replayPath != "":
+		recording, err := replay.Load(*replayPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		run = DailyRun{Replay: recording, Playback: true}
+		menu.Mode = Daily
+	case *modeName != "":
+		mode, ok := ParseMode(*modeName)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown mode %q\n", *modeName)
+			os.Exit(2)
+		}
+		menu.Mode = mode
+	default:
+		OpenMenu(&menu)
+	}
+
+	// A local game can be watched by spectators on other machines.
+	if *broadcastAddress != "" && client == nil && session == nil {
+		var err error
+		broadcaster, err = Broadcast(*broadcastAddress, conditions)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer broadcaster.Close()
+	}
+
+	difficulty, ok := config.ParseDifficulty(*difficultyName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown difficulty %q\n", *difficultyName)
+		os.Exit(2)
+	}
+	config.SetDifficulty(difficulty)
+	config.SetAdaptive(*adaptive)
+	menu.Difficulty = difficulty
+
+	// The window can be resized freely as the playfield is scaled to fit it.
+	rl.SetConfigFlags(rl.FlagWindowResizable)
 	rl.InitWindow(SCREEN_WIDTH, SCREEN_HEIGHT, "Asteroids 1979")
 	defer rl.CloseWindow()
 
-	rl.SetTargetFPS(120)
+	rl.SetWindowMinSize(SCREEN_WIDTH/4, SCREEN_HEIGHT/4)
+	rl.SetTargetFPS(constants.TICK_RATE)
+
+	viewport := utils.NewViewport()
+	defer rl.UnloadRenderTexture(viewport.Target)
+
+	audio.Init()
+	defer audio.Close()
+	input.OnFeedback(func(event input.FeedbackEvent) {
+		if event == input.FeedbackExtraLife {
+			audio.Play(audio.Chime)
+		}
+	})
+
+	// Gameplay tuning is loaded from a file and reloaded whenever it changes.
+	// Any reload results are shown in a toast at the bottom of the screen.
+	toast := utils.Toast{}
+	watcher, err := config.NewWatcher(constants.TUNING_FILE)
+	if err != nil {
+		toast = utils.NewToast(
+			[]string{"Using default tuning: " + err.Error()},
+			rl.Red,
+		)
+	}
 
-	gameState := NewGameState()
+	// Controls are loaded from the bindings file if one has been saved.
+	if bindings, err := input.Load(constants.BINDINGS_FILE); err == nil {
+		input.Set(bindings)
+	} else if !os.IsNotExist(err) {
+		lines := append([]string{"Using default controls:"}, strings.Split(err.Error(), "\n")...)
+		toast = utils.NewToast(lines, rl.Red)
+	}
+	rebindScreen := input.RebindScreen{}
+
+	highScores, err := scores.Load(constants.SCORES_FILE)
+	if err != nil && !os.IsNotExist(err) {
+		toast = utils.NewToast([]string{"High scores not loaded: " + err.Error()}, rl.Red)
+	}
+
+	// The game is created after the tuning is loaded so that it starts with
+	// the loaded lives.
+	gameState := newGame(menu.Mode, menu.Players, &run)
+	assignPilots(&gameState, menu.Bots, menu.BotSkill)
+	attract := Attract{}
 
 	for !rl.WindowShouldClose() {
-		update(&gameState)
+		utils.UpdateViewport(&viewport)
+		// Reloading the tuning in the middle of a rollback match would make
+		// the game drift apart from the peer's.
+		if session == nil {
+			pollTuning(watcher, &toast)
+		}
+
+		// Gamepads can be plugged in or removed at any time.
+		if message := input.UpdateGamepads(); message != "" {
+			toast = utils.NewToast([]string{message}, rl.RayWhite)
+		}
+
+		// The game is paused while the controls are being rebound.
+		if rebindScreen.IsOpen {
+			if input.UpdateRebindScreen(&rebindScreen) {
+				saveBindings(&toast)
+			}
+		} else {
+			if rl.IsKeyPressed(input.REBIND_SCREEN_KEY) {
+				input.OpenRebindScreen(&rebindScreen)
+			}
+			if rl.IsKeyPressed(input.CONTROL_MODE_KEY) {
+				mode := input.ToggleMode()
+				toast = utils.NewToast([]string{"Control mode: " + mode.String()}, rl.RayWhite)
+			}
+
+			switch {
+			case client != nil:
+				client.Update(viewport.MousePosition())
+				if client.spectating && client.IsPlaying() {
+					UpdateSpectatorCamera(&camera, &client.View)
+				}
+			case session != nil:
+				ship := session.LocalShip()
+				session.Update(input.Poll(ship.Pos, viewport.MousePosition()))
+			case menu.IsOpen:
+				if UpdateMenu(&menu) {
+					config.SetDifficulty(menu.Difficulty)
+					run.Playback = false
+					gameState = newGame(menu.Mode, menu.Players, &run)
+					assignPilots(&gameState, menu.Bots, menu.BotSkill)
+				} else if UpdateAttractIdle(&attract) {
+					config.SetDifficulty(menu.Difficulty)
+					gameState = startAttract(&attract)
+					menu.IsOpen = false
+				}
+			case attract.Active:
+				if attractOver(&gameState) {
+					attract.Active = false
+					OpenMenu(&menu)
+				} else if controls, ok := pollControls(&gameState, &run, viewport.MousePosition()); ok {
+					update(&gameState, controls)
+				}
+			case gameState.isGameOver:
+				if rl.IsKeyPressed(MENU_KEY) {
+					OpenMenu(&menu)
+				} else if input.IsPressed(input.Confirm) {
+					gameState = newGame(gameState.mode, len(gameState.players), &run)
+					assignPilots(&gameState, menu.Bots, menu.BotSkill)
+				}
+			default:
+				controls, ok := pollControls(&gameState, &run, viewport.MousePosition())
+				if ok {
+					update(&gameState, controls)
+				} else if run.Playback {
+					// The replay ran out before the game ended.
+					gameState.isGameOver = true
+				}
+				if gameState.isGameOver {
+					finishGame(&gameState, &run, highScores, &toast)
+				}
+			}
+		}
+		if broadcaster != nil {
+			broadcaster.Update(&gameState)
+		}
 
 		rl.BeginDrawing()
 		rl.ClearBackground(rl.Black)
 
-		render(&gameState)
+		switch {
+		case client != nil:
+			switch {
+			case client.spectating && client.IsPlaying():
+				renderSpectated(&client.View, viewport, camera)
+			case client.IsPlaying():
+				render(&client.View, viewport)
+			}
+			drawNetworkBanner(client, hudScale(viewport))
+		case session != nil:
+			if session.started {
+				render(&session.State, viewport)
+			}
+			drawRollbackBanner(session, hudScale(viewport))
+		case menu.IsOpen:
+			DrawMenu(menu, highScores, hudScale(viewport))
+		default:
+			render(&gameState, viewport)
+			switch {
+			case attract.Active:
+				drawAttractBanner(hudScale(viewport))
+			case gameState.mode == Daily:
+				drawDailyBanner(run, hudScale(viewport))
+			}
+		}
+		if rebindScreen.IsOpen {
+			input.DrawRebindScreen(rebindScreen, hudScale(viewport))
+		}
+		utils.DrawToast(toast, int32(rl.GetScreenHeight()), hudScale(viewport))
 
 		rl.EndDrawing()
 	}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/entities"
+	"asteroids/internal/input"
+	"asteroids/internal/utils"
+	"os"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// The game is simulated without a window, so there is no gamepad to rumble.
+func TestMain(m *testing.M) {
+	input.MuteFeedback(true)
+	os.Exit(m.Run())
+}
+
+// Returns a new game of classic with a fixed seed and the default tuning.
+// Nothing drops from destroyed asteroids, so tests don't depend on the drop
+// roll.
+func newTestState(t *testing.T, numPlayers int) GameState {
+	t.Helper()
+	tuning := config.Default()
+	tuning.PowerUps.DropChance = 0
+	config.Set(tuning)
+	config.SetDifficulty(config.Normal)
+	t.Cleanup(func() { config.Set(config.Default()) })
+
+	utils.Seed(1)
+	return NewGameState(Classic, numPlayers)
+}
+
+// Adds an asteroid of the given size and material at `pos` to the game and
+// returns its index.
+func addAsteroid(state *GameState, pos rl.Vector2, size entities.AsteroidSize, material entities.Material) int {
+	asteroid := entities.SpawnAsteroidAt(pos, size)
+	asteroid.Material = material
+	state.asteroids = append(state.asteroids, asteroid)
+	return len(state.asteroids) - 1
+}
+
+func TestPiercingBulletSkipsOwnFragments(t *testing.T) {
+	state := newTestState(t, 1)
+	pos := rl.Vector2{X: 300, Y: 300}
+	j := addAsteroid(&state, pos, entities.Large, entities.Rock)
+	state.asteroids[j].Health = 1
+
+	bullet := entities.NewBullet(pos, rl.Vector2{X: 1})
+	bullet.Piercing = true
+	bullet.Damage = 1
+	state.bullets = append(state.bullets, bullet)
+
+	checkForBulletAsteroidCollisions(&state)
+	if len(state.asteroids) != 2 {
+		t.Fatalf("%d asteroids after the hit, want the 2 fragments", len(state.asteroids))
+	}
+	if len(state.bullets) != 1 {
+		t.Fatalf("piercing bullet was removed")
+	}
+
+	// The fragments are right under the bullet but it has already passed
+	// through them.
+	for range 3 {
+		checkForBulletAsteroidCollisions(&state)
+	}
+	for _, fragment := range state.asteroids {
+		if fragment.Health != config.Get().Asteroids.Medium.Health {
+			t.Errorf("fragment %d has %d health, want it untouched", fragment.ID, fragment.Health)
+		}
+	}
+}
+
+func TestPiercingBulletHitsOtherAsteroids(t *testing.T) {
+	state := newTestState(t, 1)
+	pos := rl.Vector2{X: 300, Y: 300}
+	addAsteroid(&state, pos, entities.Small, entities.Rock)
+	addAsteroid(&state, pos, entities.Small, entities.Rock)
+
+	bullet := entities.NewBullet(pos, rl.Vector2{X: 1})
+	bullet.Piercing = true
+	bullet.Damage = 1
+	state.bullets = append(state.bullets, bullet)
+
+	// A bullet destroys one asteroid at a time, so the second goes on the
+	// next tick.
+	checkForBulletAsteroidCollisions(&state)
+	checkForBulletAsteroidCollisions(&state)
+	if len(state.asteroids) != 0 {
+		t.Errorf("%d asteroids left, want the piercing bullet to destroy both", len(state.asteroids))
+	}
+}
+
+func TestDeflectedBulletCountsHit(t *testing.T) {
+	state := newTestState(t, 1)
+	pos := rl.Vector2{X: 300, Y: 300}
+	metal := addAsteroid(&state, rl.Vector2{X: 200, Y: 300}, entities.Large, entities.Metallic)
+	addAsteroid(&state, pos, entities.Small, entities.Rock)
+
+	bullet := entities.NewBullet(pos, rl.Vector2{X: 1})
+	entities.DeflectBullet(&bullet, state.asteroids[metal])
+	state.bullets = append(state.bullets, bullet)
+
+	checkForBulletAsteroidCollisions(&state)
+	if hits := state.players[0].combo.shotHits; hits != 1 {
+		t.Errorf("%d shot hits after a deflected bullet hit, want 1", hits)
+	}
+	if state.adaptive.shotHits != 1 {
+		t.Errorf("%d adaptive shot hits after a deflected bullet hit, want 1", state.adaptive.shotHits)
+	}
+}
+
+func TestAwardExtraLives(t *testing.T) {
+	state := newTestState(t, 1)
+	tuning := config.Get()
+	tuning.Lives.Thresholds = []uint64{1000}
+	tuning.Lives.Every = 1000
+	config.Set(tuning)
+	player := &state.players[0]
+	lives := player.lives
+
+	// A big score can pass several thresholds at once.
+	player.Score = 2500
+	awardExtraLives(&state, player)
+	if player.lives != lives+2 || player.extraLivesAwarded != 2 {
+		t.Errorf("%d lives and %d awarded at 2500 points, want %d and 2", player.lives, player.extraLivesAwarded, lives+2)
+	}
+
+	// Thresholds passed at the cap still count as passed.
+	player.Score = 100000
+	awardExtraLives(&state, player)
+	if player.lives != config.Get().Lives.Max || player.extraLivesAwarded != 100 {
+		t.Errorf("%d lives and %d awarded at 100000 points, want the cap of %d and 100", player.lives, player.extraLivesAwarded, config.Get().Lives.Max)
+	}
+}
+
+func TestAdaptiveDifficulty(t *testing.T) {
+	tests := []struct {
+		name     string
+		shots    int
+		shotHits int
+		deaths   int
+		alive    float32
+		want     float32
+	}{
+		{"coping well", 10, 10, 0, 90, 1.1},
+		{"struggling", 10, 0, 2, 5, 0.9},
+		{"in the target band", 10, 5, 0, 45, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := newTestState(t, 1)
+			config.SetAdaptive(true)
+			t.Cleanup(func() {
+				config.SetAdaptive(false)
+				config.SetAdjustment(config.NO_ADJUSTMENT)
+			})
+
+			interval := config.Get().Adaptive.Interval
+			state.adaptive = Adaptive{timer: interval, shots: test.shots, shotHits: test.shotHits, deaths: test.deaths, alive: test.alive}
+			updateAdaptive(&state)
+
+			if got := config.CurrentAdjustment().SpawnRate; got < test.want-0.001 || got > test.want+0.001 {
+				t.Errorf("spawn rate %v, want %v", got, test.want)
+			}
+			if state.adaptive.timer != 0 || state.adaptive.shots != 0 {
+				t.Errorf("adaptive state %+v not reset after the adjustment", state.adaptive)
+			}
+		})
+	}
+}
+
+func TestBlackHoleSwallowsShip(t *testing.T) {
+	state := newTestState(t, 1)
+	player := &state.players[0]
+	hole := rl.Vector2{X: 200, Y: 200}
+	state.hazards = []entities.Hazard{{Kind: entities.BlackHole, Pos: hole}}
+	player.ship.Pos = hole
+	lives := player.lives
+
+	updateHazards(&state)
+	if !player.ship.IsDead() || player.lives != lives-1 {
+		t.Errorf("dead %v with %d lives, want the ship lost", player.ship.IsDead(), player.lives)
+	}
+	if rl.Vector2Distance(player.ship.Pos, hole) < entities.EVENT_HORIZON {
+		t.Error("the ship respawns inside the black hole")
+	}
+}
+
+func TestBlackHoleSwallowsAsteroidsUnscored(t *testing.T) {
+	state := newTestState(t, 1)
+	hole := rl.Vector2{X: 200, Y: 200}
+	state.hazards = []entities.Hazard{{Kind: entities.BlackHole, Pos: hole}}
+	state.asteroids = nil
+	addAsteroid(&state, hole, entities.Large, entities.Rock)
+	kept := addAsteroid(&state, rl.Vector2{X: 1000, Y: 800}, entities.Large, entities.Rock)
+	pos := state.asteroids[kept].Pos
+
+	updateHazards(&state)
+	if len(state.asteroids) != 1 || state.asteroids[0].Pos != pos {
+		t.Errorf("%d asteroids left, want only the one far from the black hole", len(state.asteroids))
+	}
+	if state.players[0].Score != 0 {
+		t.Errorf("scored %d for an asteroid lost to a black hole", state.players[0].Score)
+	}
+}
+
+func TestExplosionSparesOwnFragments(t *testing.T) {
+	state := newTestState(t, 1)
+	pos := rl.Vector2{X: 300, Y: 300}
+	j := addAsteroid(&state, pos, entities.Large, entities.Explosive)
+	state.asteroids[j].Health = 1
+
+	damageAsteroid(&state, &state.players[0], j, 1)
+
+	if len(state.asteroids) != 2 {
+		t.Fatalf("%d asteroids after the explosion, want the 2 fragments", len(state.asteroids))
+	}
+	for _, fragment := range state.asteroids {
+		if fragment.Size != entities.Medium || fragment.Health != config.Get().Asteroids.Medium.Health {
+			t.Errorf("fragment %d is size %d with %d health, want an untouched medium", fragment.ID, fragment.Size, fragment.Health)
+		}
+	}
+	if len(state.explosions) != 1 {
+		t.Errorf("%d explosions, want 1", len(state.explosions))
+	}
+}
+
+func TestExplosionDamagesNeighbours(t *testing.T) {
+	state := newTestState(t, 1)
+	j := addAsteroid(&state, rl.Vector2{X: 300, Y: 300}, entities.Small, entities.Explosive)
+	state.asteroids[j].Health = 1
+	near := addAsteroid(&state, rl.Vector2{X: 350, Y: 300}, entities.Large, entities.Rock)
+	state.asteroids[near].Health = 10
+	far := addAsteroid(&state, rl.Vector2{X: 900, Y: 300}, entities.Large, entities.Rock)
+	state.asteroids[far].Health = 10
+
+	damageAsteroid(&state, &state.players[0], j, 1)
+
+	if len(state.asteroids) != 2 {
+		t.Fatalf("%d asteroids left, want the two rocks", len(state.asteroids))
+	}
+	if health := state.asteroids[0].Health; health != 10-entities.EXPLOSION_DAMAGE {
+		t.Errorf("nearby rock has %d health, want %d", health, 10-entities.EXPLOSION_DAMAGE)
+	}
+	if health := state.asteroids[1].Health; health != 10 {
+		t.Errorf("distant rock has %d health, want it untouched", health)
+	}
+}
+
+func TestExplosionsChainThroughNeighbours(t *testing.T) {
+	state := newTestState(t, 1)
+	j := addAsteroid(&state, rl.Vector2{X: 300, Y: 300}, entities.Small, entities.Explosive)
+	state.asteroids[j].Health = 1
+	next := addAsteroid(&state, rl.Vector2{X: 400, Y: 300}, entities.Small, entities.Explosive)
+	state.asteroids[next].Health = 1
+	// Only in range of the second explosion.
+	last := addAsteroid(&state, rl.Vector2{X: 500, Y: 300}, entities.Large, entities.Rock)
+	state.asteroids[last].Health = 10
+
+	damageAsteroid(&state, &state.players[0], j, 1)
+
+	if len(state.explosions) != 2 {
+		t.Errorf("%d explosions, want 2", len(state.explosions))
+	}
+	if len(state.asteroids) != 1 || state.asteroids[0].Health != 10-entities.EXPLOSION_DAMAGE {
+		t.Errorf("asteroids = %+v, want the rock damaged by the second explosion", state.asteroids)
+	}
+}
+
+func TestHazardsOnlyInModesWhichWantThem(t *testing.T) {
+	newTestState(t, 1)
+	for mode := range GameMode(NUM_MODES) {
+		hazards := NewGameState(mode, 2).hazards
+		want := mode == Survival || mode == Zen
+		if (len(hazards) > 0) != want {
+			t.Errorf("%s has %d hazards", mode, len(hazards))
+		}
+	}
+}
+
+func TestComboMultiplier(t *testing.T) {
+	tuning := config.Default().Combo
+	tuning.HitsPerStep = 5
+	tuning.MaxMultiplier = 4
+
+	for _, test := range []struct {
+		hits    int
+		enabled bool
+		want    uint64
+	}{
+		{0, true, 1},
+		{4, true, 1},
+		{5, true, 2},
+		{14, true, 3},
+		{100, true, 4},
+		{100, false, 1},
+	} {
+		tuning.Enabled = test.enabled
+		if got := (Combo{Hits: test.hits}).Multiplier(tuning); got != test.want {
+			t.Errorf("Multiplier() with %d hits (enabled %v) = %d, want %d", test.hits, test.enabled, got, test.want)
+		}
+	}
+}
+
+func TestScoreHitUsesModeCombo(t *testing.T) {
+	newTestState(t, 1)
+	tuning := config.Get()
+	tuning.Combo.HitsPerStep = 1
+	tuning.Combo.Modes = map[string]config.ComboTuning{"zen": tuning.Combo}
+	tuning.Combo.Enabled = false
+	config.Set(tuning)
+
+	for _, test := range []struct {
+		mode GameMode
+		want uint64
+	}{
+		{Classic, 20},
+		{Zen, 50},
+	} {
+		state := NewGameState(test.mode, 1)
+		player := &state.players[0]
+		scoreHit(&state, player, player.ship.Pos, 10)
+		scoreHit(&state, player, player.ship.Pos, 10)
+		if player.Score != test.want {
+			t.Errorf("%s scored %d for two hits, want %d", test.mode, player.Score, test.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/input"
+	"asteroids/internal/scores"
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Key which returns to the menu from the game over screen.
+const MENU_KEY = rl.KeyM
+
+// Keys which change the number of players flown by the autopilot and its
+// skill. No preset binds them, as the bound actions are checked first.
+const (
+	MENU_BOTS_KEY      = rl.KeyB
+	MENU_BOT_SKILL_KEY = rl.KeyN
+)
+
+// Number of high scores shown for the highlighted mode.
+const MENU_SCORES = 5
+
+// Title screen where the game mode, difficulty and number of players are
+// chosen.
+type Menu struct {
+	IsOpen       bool
+	Mode         GameMode // Highlighted game mode.
+	Difficulty   config.Difficulty
+	Players      int           // Number of ships in local multiplayer, from 1 to `input.MAX_PLAYERS`.
+	Bots         int           // Number of the players flown by the autopilot, counting back from the last.
+	BotSkill     PilotSkill    // Skill of the autopilot.
+	DailyResults []DailyResult // Saved daily results, newest first.
+}
+
+// Opens the menu, reloading the daily results in case one was just saved.
+func OpenMenu(menu *Menu) {
+	menu.IsOpen = true
+	menu.DailyResults = loadDailyResults()
+}
+
+// Handles the controls on the menu. Returns true when a game is started with
+// the chosen mode, difficulty and number of players.
+func UpdateMenu(menu *Menu) bool {
+	switch {
+	case input.IsPressed(input.Thrust):
+		menu.Mode = (menu.Mode + NUM_MODES - 1) % NUM_MODES
+	case input.IsPressed(input.Reverse):
+		menu.Mode = (menu.Mode + 1) % NUM_MODES
+	case input.IsPressed(input.RotateLeft):
+		menu.Difficulty = (menu.Difficulty + config.NUM_DIFFICULTIES - 1) % config.NUM_DIFFICULTIES
+	case input.IsPressed(input.RotateRight):
+		menu.Difficulty = (menu.Difficulty + 1) % config.NUM_DIFFICULTIES
+	case input.IsPressed(input.PrevWeapon):
+		menu.Players = max(1, menu.Players-1)
+		menu.Bots = min(menu.Bots, menu.Players)
+	case input.IsPressed(input.NextWeapon):
+		menu.Players = min(menu.Players+1, input.MAX_PLAYERS)
+	case rl.IsKeyPressed(MENU_BOTS_KEY):
+		menu.Bots = (menu.Bots + 1) % (menuPlayers(*menu) + 1)
+	case rl.IsKeyPressed(MENU_BOT_SKILL_KEY):
+		menu.BotSkill = (menu.BotSkill + 1) % NUM_PILOT_SKILLS
+	case input.IsPressed(input.Confirm):
+		menu.IsOpen = false
+		return true
+	}
+
+	return false
+}
+
+// Draws the menu over the whole window, along with the best scores of the
+// highlighted mode. All sizes are multiplied by `scale`.
+func DrawMenu(menu Menu, table scores.Table, scale float32) {
+	size := func(pixels float32) int32 {
+		return int32(pixels * scale)
+	}
+	fontSize := size(24)
+	lineHeight := size(36)
+
+	y := size(120)
+	drawCentred("ASTEROIDS", y, size(60), rl.RayWhite)
+	y += size(110)
+
+	for mode := range GameMode(NUM_MODES) {
+		color := rl.Gray
+		name := mode.String()
+		if mode == menu.Mode {
+			color = rl.RayWhite
+			name = "> " + name + " <"
+		}
+		drawCentred(name, y, fontSize, color)
+		y += lineHeight
+	}
+
+	y += lineHeight / 2
+	drawCentred(menu.Mode.Description(), y, size(20), rl.Gray)
+	y += lineHeight
+	if menu.Mode == Daily {
+		drawCentred("Difficulty: Normal (fixed)", y, fontSize, rl.Gray)
+		y += lineHeight
+		drawCentred("Players: 1 (fixed)", y, fontSize, rl.Gray)
+		y += 2 * lineHeight
+		drawDailyResults(menu.DailyResults, y, scale)
+	} else if menu.Mode == Versus {
+		// Versus needs at least two ships and has no high scores.
+		drawCentred(fmt.Sprintf("< Difficulty: %s >", menu.Difficulty), y, fontSize, rl.Yellow)
+		y += lineHeight
+		drawCentred(fmt.Sprintf("< Players: %d >", max(menu.Players, 2)), y, fontSize, rl.Yellow)
+		y += lineHeight
+		drawCentred(botsLabel(menu), y, fontSize, rl.Yellow)
+		y += 2 * lineHeight
+		drawCentred(versusRules(), y, int32(20*scale), rl.Gray)
+	} else {
+		drawCentred(fmt.Sprintf("< Difficulty: %s >", menu.Difficulty), y, fontSize, rl.Yellow)
+		y += lineHeight
+		drawCentred(fmt.Sprintf("< Players: %d >", menu.Players), y, fontSize, rl.Yellow)
+		y += lineHeight
+		drawCentred(botsLabel(menu), y, fontSize, rl.Yellow)
+		y += lineHeight
+		if min(menu.Bots, menuPlayers(menu)) > 0 {
+			drawCentred("Games with bots don't set high scores", y, int32(20*scale), rl.Gray)
+		}
+		y += lineHeight
+		drawHighScores(table.Top(highScoreKey(menu.Mode, menuPlayers(menu))), y, scale)
+	}
+
+	bindings := input.Get()
+	hint := fmt.Sprintf(
+		"%s/%s mode   %s/%s difficulty   %s/%s players   %s bots   %s bot skill   %s start",
+		input.KeyName(bindings[input.Thrust]),
+		input.KeyName(bindings[input.Reverse]),
+		input.KeyName(bindings[input.RotateLeft]),
+		input.KeyName(bindings[input.RotateRight]),
+		input.KeyName(bindings[input.PrevWeapon]),
+		input.KeyName(bindings[input.NextWeapon]),
+		input.KeyName(MENU_BOTS_KEY),
+		input.KeyName(MENU_BOT_SKILL_KEY),
+		input.KeyName(bindings[input.Confirm]),
+	)
+	drawCentred(hint, int32(rl.GetScreenHeight())-size(80), size(20), rl.Gray)
+}
+
+// Returns the number of players in a game started from the menu, as versus
+// needs at least two.
+func menuPlayers(menu Menu) int {
+	if menu.Mode == Versus {
+		return max(menu.Players, 2)
+	}
+	return menu.Players
+}
+
+// Returns how many of the players are flown by the autopilot and how well.
+func botsLabel(menu Menu) string {
+	bots := min(menu.Bots, menuPlayers(menu))
+	if bots == 0 {
+		return "Bots: none"
+	}
+	return fmt.Sprintf("Bots: %d %s", bots, menu.BotSkill)
+}
+
+// Draws the best scores of the highlighted mode and player count starting at
+// `y`.
+func drawHighScores(entries []scores.Entry, y int32, scale float32) {
+	drawCentred("HIGH SCORES", y, int32(24*scale), rl.RayWhite)
+	y += int32(36 * scale)
+
+	if len(entries) == 0 {
+		drawCentred("No scores yet", y, int32(20*scale), rl.Gray)
+	}
+	for i, entry := range entries[:min(len(entries), MENU_SCORES)] {
+		line := fmt.Sprintf("%d. %d  %s  %s", i+1, entry.Score, entry.Difficulty, entry.Date)
+		drawCentred(line, y, int32(20*scale), rl.Gray)
+		y += int32(28 * scale)
+	}
+}
+
+// Draws whether today's challenge has been played and the latest daily
+// results starting at `y`.
+func drawDailyResults(results []DailyResult, y int32, scale float32) {
+	status := "Today's challenge has not been played yet"
+	switch {
+	case len(results) == 0 || results[0].Date != today():
+	case results[0].Replay == "":
+		status = "Today's challenge was not finished - further attempts are practice"
+	default:
+		status = fmt.Sprintf("Today's score: %d - further attempts are practice", results[0].Score)
+	}
+	drawCentred(status, y, int32(24*scale), rl.RayWhite)
+	y += int32(36 * scale)
+
+	for _, result := range results[:min(len(results), MENU_DAILY_RESULTS)] {
+		line := fmt.Sprintf("%s  %d  (%s)", result.Date, result.Score, result.Replay)
+		if result.Replay == "" {
+			line = fmt.Sprintf("%s  -  (unfinished)", result.Date)
+		}
+		drawCentred(line, y, int32(20*scale), rl.Gray)
+		y += int32(28 * scale)
+	}
+}
+
+// Draws text centred horizontally in the window.
+func drawCentred(text string, y int32, size int32, color rl.Color) {
+	width := int32(rl.GetScreenWidth())
+	rl.DrawText(text, width/2-rl.MeasureText(text, size)/2, y, size, color)
+}
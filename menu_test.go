@@ -0,0 +1,17 @@
+package main
+
+import (
+	"asteroids/internal/input"
+	"testing"
+)
+
+func TestMenuKeysAreNotBound(t *testing.T) {
+	for _, preset := range input.Presets {
+		for _, action := range input.Actions {
+			key := preset.Bindings[action]
+			if key == MENU_BOTS_KEY || key == MENU_BOT_SKILL_KEY {
+				t.Errorf("%s preset binds %s to %s, which the menu also uses", preset.Name, input.KeyName(key), action)
+			}
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/entities"
+	"asteroids/internal/scores"
+	"asteroids/internal/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type GameMode int
+
+const (
+	Classic GameMode = iota
+	TimeAttack
+	Survival
+	Daily
+	Zen
+	Versus
+
+	NUM_MODES = iota
+)
+
+func (mode GameMode) String() string {
+	return [...]string{"Classic", "Time Attack", "Survival", "Daily", "Zen", "Versus"}[mode]
+}
+
+// Returns the name used for the mode by the `--mode` flag and in the high
+// score file.
+func (mode GameMode) Key() string {
+	return strings.ReplaceAll(strings.ToLower(mode.String()), " ", "-")
+}
+
+// Returns the name of the high score table for games of the mode with the
+// given number of players. Solo games keep the mode's own table so that older
+// high score files still apply, and co-op games get one per player count.
+func highScoreKey(mode GameMode, players int) string {
+	if players <= 1 {
+		return mode.Key()
+	}
+	return fmt.Sprintf("%s-%dp", mode.Key(), players)
+}
+
+// Returns a one line summary of the mode for the menu.
+func (mode GameMode) Description() string {
+	switch mode {
+	case Classic:
+		return "Survive as long as you can with a limited number of lives"
+	case TimeAttack:
+		return "Score as much as you can before the clock runs out"
+	case Survival:
+		return "One life against an endless, escalating asteroid storm"
+	case Daily:
+		return "Today's seeded run under fixed rules, with one scored attempt a day"
+	case Zen:
+		return "A sandbox with no deaths for practising and trying out tuning"
+	case Versus:
+		return "Shoot down the other ships to win rounds, using the asteroids as cover"
+	}
+	panic("unreachable: unknown game mode")
+}
+
+// Returns the mode with the given flag name.
+func ParseMode(name string) (GameMode, bool) {
+	for mode := range GameMode(NUM_MODES) {
+		if name == mode.Key() {
+			return mode, true
+		}
+	}
+	return Classic, false
+}
+
+// Returns the names of every mode, for the flag's usage message.
+func modeKeys() string {
+	keys := []string{}
+	for mode := range GameMode(NUM_MODES) {
+		keys = append(keys, mode.Key())
+	}
+	return strings.Join(keys, ", ")
+}
+
+// Seconds of warning given before a survival storm hits.
+const STORM_WARNING = 3.0
+
+// Returns the lives that a game of the mode starts with. Versus rounds played
+// to be the last ship standing give every ship a single life.
+func startingLives(mode GameMode) uint8 {
+	if mode == Survival || mode == Versus && !isPlayingForKills() {
+		return 1
+	}
+	return config.Get().Lives.Starting
+}
+
+// Runs the rules specific to the game mode, such as the time attack clock.
+func updateMode(state *GameState) {
+	switch state.mode {
+	case TimeAttack:
+		state.timeLeft = max(0, state.timeLeft-TICK)
+	case Survival:
+		updateSurvival(state)
+	case Versus:
+		updateVersus(state)
+	}
+}
+
+// Escalates endless survival. The spawn rate and asteroid speed grow with the
+// time survived, storms sweep in from one edge at a regular interval and
+// every second survived is worth points on top of the asteroids destroyed.
+// The clock stops while every ship is dead.
+func updateSurvival(state *GameState) {
+	if !anyAlive(state) {
+		return
+	}
+	tuning := config.Get().Survival
+
+	state.elapsed += TICK
+	minutes := state.elapsed / 60
+	config.SetAdjustment(config.Adjustment{
+		SpawnRate: min(1+tuning.SpawnGrowth*minutes, tuning.MaxSpawnRate),
+		Speed:     min(1+tuning.SpeedGrowth*minutes, tuning.MaxSpeed),
+	})
+
+	// Survival points are added whole to every ship still flying, carrying
+	// the fraction over to the next frame.
+	state.survivalPoints += tuning.PointsPerSecond * TICK
+	whole := uint64(state.survivalPoints)
+	for i := range state.players {
+		if !state.players[i].ship.IsDead() {
+			state.players[i].Score += whole
+		}
+	}
+	state.survivalPoints -= float32(whole)
+
+	state.stormTimer += TICK
+	if state.stormTimer >= tuning.StormInterval {
+		size := tuning.StormSize + tuning.StormGrowth*state.storms
+		state.asteroids = append(state.asteroids, entities.SpawnStorm(state.stormEdge, size)...)
+
+		state.stormTimer = 0
+		state.storms++
+		state.stormEdge = entities.RandomEdge()
+	}
+}
+
+// Returns true while the warning for the next survival storm is shown.
+func isStormWarning(state *GameState) bool {
+	return state.mode == Survival && state.stormTimer >= config.Get().Survival.StormInterval-STORM_WARNING
+}
+
+// Returns the clock shown for the mode, which is the time left in time attack
+// and the time survived in survival. Returns false if the mode has no clock.
+func modeClock(state *GameState) (float32, bool) {
+	switch state.mode {
+	case TimeAttack:
+		return state.timeLeft, true
+	case Survival:
+		return state.elapsed, true
+	}
+	return 0, false
+}
+
+// Takes away a life when a player's ship dies. Time attack has unlimited
+// lives, but every death costs time on the shared clock instead. Versus ships
+// respawn without limit when playing for kills.
+func loseLife(state *GameState, player *Player) {
+	if state.mode == Versus && isPlayingForKills() {
+		return
+	}
+	if state.mode == TimeAttack {
+		penalty := config.Get().TimeAttack.DeathPenalty
+		state.timeLeft = max(0, state.timeLeft-penalty)
+		state.popups = append(state.popups, entities.NewPopup(player.ship.Pos, fmt.Sprintf("-%.0fs", penalty), rl.Red))
+		return
+	}
+	player.lives -= 1
+}
+
+// Returns true once the game is over for the mode being played. The zen
+// sandbox never ends, versus ends once a player has won the match and other
+// modes with lives end once every player is down.
+func isModeOver(state *GameState) bool {
+	switch state.mode {
+	case TimeAttack:
+		return state.timeLeft <= 0
+	case Zen:
+		return false
+	case Versus:
+		return state.match.MatchWinner >= 0
+	}
+	return allDown(state)
+}
+
+// Records the result of a game which has just ended. Daily challenges keep
+// their own results instead of going into the high scores, and versus matches
+// have a winner rather than a score. Games with bots aren't entered either, as
+// the autopilot would have earned part of the score.
+func finishGame(state *GameState, run *DailyRun, table scores.Table, toast *utils.Toast) {
+	switch {
+	case state.mode == Daily:
+		finishDaily(state, run, toast)
+	case state.mode == Versus || hasPilots(state):
+	default:
+		recordHighScore(state, table, toast)
+	}
+}
+
+// Adds the score of a finished game to the high scores of its mode and player
+// count and saves them. Co-op games are entered with the combined score of
+// every player, so they are kept apart from solo runs.
+func recordHighScore(state *GameState, table scores.Table, toast *utils.Toast) {
+	state.highScoreRank = table.Add(highScoreKey(state.mode, len(state.players)), scores.Entry{
+		Score:      totalScore(state),
+		Difficulty: config.CurrentDifficulty().String(),
+		Date:       time.Now().Format(time.DateOnly),
+	})
+	if state.highScoreRank == 0 {
+		return
+	}
+
+	if err := scores.Save(constants.SCORES_FILE, table); err != nil {
+		*toast = utils.NewToast([]string{"Failed to save high scores: " + err.Error()}, rl.Red)
+	}
+}
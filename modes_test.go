@@ -0,0 +1,188 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"slices"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	for mode := range GameMode(NUM_MODES) {
+		if parsed, ok := ParseMode(mode.Key()); !ok || parsed != mode {
+			t.Errorf("ParseMode(%q) = %v, %v", mode.Key(), parsed, ok)
+		}
+	}
+	if _, ok := ParseMode("Time Attack"); ok {
+		t.Error("ParseMode() accepted a display name rather than a key")
+	}
+}
+
+func TestModeKeysMatchTuning(t *testing.T) {
+	keys := []string{}
+	for mode := range GameMode(NUM_MODES) {
+		keys = append(keys, mode.Key())
+	}
+	if !slices.Equal(keys, config.MODE_KEYS) {
+		t.Errorf("mode keys are %v, but the tuning accepts %v", keys, config.MODE_KEYS)
+	}
+}
+
+func TestHighScoreKey(t *testing.T) {
+	tests := []struct {
+		mode    GameMode
+		players int
+		want    string
+	}{
+		{Classic, 1, "classic"},
+		{Classic, 2, "classic-2p"},
+		{TimeAttack, 4, "time-attack-4p"},
+	}
+	for _, test := range tests {
+		if got := highScoreKey(test.mode, test.players); got != test.want {
+			t.Errorf("highScoreKey(%v, %d) = %q, want %q", test.mode, test.players, got, test.want)
+		}
+	}
+}
+
+func TestTimeAttackClock(t *testing.T) {
+	newTestState(t, 1)
+	state := NewGameState(TimeAttack, 1)
+	duration := config.Get().TimeAttack.Duration
+
+	updateMode(&state)
+	if clock, ok := modeClock(&state); !ok || clock != duration-TICK {
+		t.Errorf("clock %v after a tick, want %v", clock, duration-TICK)
+	}
+
+	state.timeLeft = TICK / 2
+	updateMode(&state)
+	if state.timeLeft != 0 || !isModeOver(&state) {
+		t.Errorf("%vs left and over %v once the clock runs out, want 0 and over", state.timeLeft, isModeOver(&state))
+	}
+}
+
+func TestTimeAttackDeathCostsTime(t *testing.T) {
+	newTestState(t, 1)
+	state := NewGameState(TimeAttack, 1)
+	player := &state.players[0]
+	lives, timeLeft := player.lives, state.timeLeft
+
+	loseLife(&state, player)
+	if player.lives != lives || state.timeLeft != timeLeft-config.Get().TimeAttack.DeathPenalty {
+		t.Errorf("%d lives and %vs left after a death, want the lives unchanged and the death penalty taken off the clock", player.lives, state.timeLeft)
+	}
+
+	state.timeLeft = 1
+	loseLife(&state, player)
+	if state.timeLeft != 0 {
+		t.Errorf("%vs left after a death with less time than the penalty, want 0", state.timeLeft)
+	}
+}
+
+func TestGainLifeInTimeAttack(t *testing.T) {
+	newTestState(t, 1)
+	state := NewGameState(TimeAttack, 1)
+	player := &state.players[0]
+	lives, timeLeft := player.lives, state.timeLeft
+
+	gainLife(&state, player)
+	if player.lives != lives || state.timeLeft != timeLeft+config.Get().TimeAttack.DeathPenalty {
+		t.Errorf("%d lives and %vs left, want the lives unchanged and the death penalty given back", player.lives, state.timeLeft)
+	}
+}
+
+func TestSurvivalEscalates(t *testing.T) {
+	newTestState(t, 1)
+	t.Cleanup(func() { config.SetAdjustment(config.NO_ADJUSTMENT) })
+	state := NewGameState(Survival, 1)
+	tuning := config.Get().Survival
+
+	state.elapsed = 120 - TICK
+	updateSurvival(&state)
+	if got, want := config.CurrentAdjustment().SpawnRate, 1+2*tuning.SpawnGrowth; got < want-0.001 || got > want+0.001 {
+		t.Errorf("spawn rate %v after two minutes, want %v", got, want)
+	}
+
+	state.elapsed = 1e6
+	updateSurvival(&state)
+	if adjustment := config.CurrentAdjustment(); adjustment.SpawnRate != tuning.MaxSpawnRate || adjustment.Speed != tuning.MaxSpeed {
+		t.Errorf("adjustment %+v after a long time, want the caps", adjustment)
+	}
+}
+
+func TestSurvivalPoints(t *testing.T) {
+	newTestState(t, 1)
+	t.Cleanup(func() { config.SetAdjustment(config.NO_ADJUSTMENT) })
+	state := NewGameState(Survival, 1)
+
+	for range 60 {
+		updateSurvival(&state)
+	}
+	want := uint64(config.Get().Survival.PointsPerSecond * 60 * TICK)
+	if score := state.players[0].Score; score < want-1 || score > want {
+		t.Errorf("scored %d for a second survived, want %d", score, want)
+	}
+
+	// The clock stops while every ship is dead.
+	state.players[0].ship.DeathTimer = 1
+	elapsed := state.elapsed
+	updateSurvival(&state)
+	if state.elapsed != elapsed {
+		t.Error("the survival clock ran with every ship dead")
+	}
+}
+
+func TestSurvivalStorms(t *testing.T) {
+	newTestState(t, 1)
+	t.Cleanup(func() { config.SetAdjustment(config.NO_ADJUSTMENT) })
+	state := NewGameState(Survival, 1)
+	tuning := config.Get().Survival
+
+	for storm := range 2 {
+		state.asteroids = nil
+		state.stormTimer = tuning.StormInterval - TICK/2
+		if !isStormWarning(&state) {
+			t.Errorf("no warning before storm %d", storm+1)
+		}
+		updateSurvival(&state)
+		if want := tuning.StormSize + storm*tuning.StormGrowth; len(state.asteroids) != want {
+			t.Errorf("storm %d has %d asteroids, want %d", storm+1, len(state.asteroids), want)
+		}
+	}
+	if state.stormTimer != 0 || isStormWarning(&state) {
+		t.Error("the storm timer didn't restart after the storm")
+	}
+}
+
+func TestZenSandbox(t *testing.T) {
+	newTestState(t, 1)
+	state := NewGameState(Zen, 1)
+	player := &state.players[0]
+	lives := player.lives
+
+	killShip(&state, player)
+	if player.ship.IsDead() || player.lives != lives {
+		t.Errorf("ship dead %v with %d lives after a hit, want it untouched", player.ship.IsDead(), player.lives)
+	}
+
+	asteroids := len(state.asteroids)
+	state.asteroidTimer = config.Get().AsteroidSpawnInterval
+	spawnAsteroids(&state)
+	if len(state.asteroids) != asteroids {
+		t.Errorf("%d asteroids spawned, want them only placed by hand", len(state.asteroids)-asteroids)
+	}
+
+	player.lives = 0
+	if isModeOver(&state) {
+		t.Error("the sandbox ended")
+	}
+}
+
+func TestZenSlidersCoverTuning(t *testing.T) {
+	tuning := config.Default()
+	for _, slider := range zenSliders {
+		if value := *slider.value(&tuning); value < slider.Min || value > slider.Max {
+			t.Errorf("%s slider runs from %v to %v, but the default is %v", slider.Label, slider.Min, slider.Max, value)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/entities"
+	"asteroids/internal/input"
+	"math"
+	"math/rand/v2"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Skill of an autopilot, which decides how quickly it reacts, how well it
+// aims and how far ahead it sees trouble coming.
+type PilotSkill int
+
+const (
+	Rookie PilotSkill = iota
+	Veteran
+	Ace
+	NUM_PILOT_SKILLS
+)
+
+func (skill PilotSkill) String() string {
+	return [...]string{"Rookie", "Veteran", "Ace"}[skill]
+}
+
+func ParsePilotSkill(name string) (PilotSkill, bool) {
+	for skill := range PilotSkill(NUM_PILOT_SKILLS) {
+		if strings.EqualFold(name, skill.String()) {
+			return skill, true
+		}
+	}
+	return 0, false
+}
+
+// How an autopilot of a given skill flies.
+type pilotSkillLevel struct {
+	reactionDelay float32 // Seconds between the pilot deciding what to do and the ship doing it.
+	aimError      float32 // Largest angle in radians that the pilot's aim is off by.
+	threatHorizon float32 // Seconds ahead that the pilot sees collisions coming.
+	hyperspace    bool    // Whether the pilot escapes collisions it can't fly away from through hyperspace.
+}
+
+var pilotSkillLevels = [NUM_PILOT_SKILLS]pilotSkillLevel{
+	Rookie:  {reactionDelay: 0.35, aimError: 0.2, threatHorizon: 0.6},
+	Veteran: {reactionDelay: 0.15, aimError: 0.08, threatHorizon: 1.0},
+	Ace:     {reactionDelay: 0.05, aimError: 0.02, threatHorizon: 1.5, hyperspace: true},
+}
+
+const (
+	// Distance that the pilot tries to keep from anything that can hit the
+	// ship, on top of the hitboxes.
+	PILOT_SAFETY_MARGIN = 12
+
+	// Seconds before a collision that it can no longer be flown away from.
+	PILOT_PANIC_TIME = 0.15
+
+	// Largest angle in radians between the heading and the aim point that
+	// the pilot fires at.
+	PILOT_AIM_TOLERANCE = 0.12
+
+	// Largest angle in radians between the heading and the escape direction
+	// that the pilot thrusts at.
+	PILOT_THRUST_TOLERANCE = 1.0
+
+	// Heat at which the pilot stops firing to let the weapon cool down
+	// before it overheats.
+	PILOT_HEAT_LIMIT = 0.85
+
+	// Seconds between changes to how far off the pilot's aim is.
+	PILOT_AIM_WOBBLE = 0.5
+
+	// Priority that a target gains for being about to hit the ship, divided by
+	// the seconds until it does.
+	PILOT_DANGER_WEIGHT = 20
+
+	// Points that a versus pilot considers another ship to be worth.
+	PILOT_SHIP_VALUE = 500
+
+	// Least points that the pilot considers a part of the boss to be worth,
+	// as its weak points score nothing until the boss is defeated.
+	PILOT_BOSS_VALUE = 100
+)
+
+// Flies a ship in place of a player at the keyboard by deciding the controls
+// for every tick. The pilot dodges whatever is about to hit the ship, and
+// otherwise turns towards the most valuable or dangerous target and shoots
+// where it will be when the shot arrives.
+type Pilot struct {
+	Skill PilotSkill
+
+	aimError    float32          // Angle in radians that the aim is currently off by.
+	wobbleTimer float32          // Seconds until the aim error changes.
+	decisions   []input.Controls // Controls decided but not yet acted on, oldest first.
+
+	// The pilot has its own random numbers so that it doesn't disturb the
+	// seeded game.
+	random *rand.Rand
+}
+
+func NewPilot(skill PilotSkill) *Pilot {
+	return &Pilot{
+		Skill:  skill,
+		random: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// Gives the last `count` players to autopilots of the given skill, for
+// partners and opponents in local games. The daily challenge is always flown
+// by the player.
+func assignPilots(state *GameState, count int, skill PilotSkill) {
+	state.pilots = make([]*Pilot, len(state.players))
+	if state.mode == Daily {
+		return
+	}
+	for i := max(len(state.players)-count, 0); i < len(state.players); i++ {
+		state.pilots[i] = NewPilot(skill)
+	}
+}
+
+// Returns true if the player at `index` is flown by an autopilot.
+func isPiloted(state *GameState, index int) bool {
+	return index < len(state.pilots) && state.pilots[index] != nil
+}
+
+// Returns true if any player is flown by an autopilot.
+func hasPilots(state *GameState) bool {
+	for i := range state.pilots {
+		if state.pilots[i] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the controls for the ship of the player at `index` for the next
+// tick. The pilot decides what to do from the game as it is now, but the ship
+// only acts on it after the pilot's reaction delay.
+func (pilot *Pilot) Fly(state *GameState, index int) input.Controls {
+	pilot.decisions = append(pilot.decisions, pilot.decide(state, index))
+
+	delay := int(pilotSkillLevels[pilot.Skill].reactionDelay * constants.TICK_RATE)
+	if len(pilot.decisions) <= delay {
+		return input.Controls{}
+	}
+	controls := pilot.decisions[0]
+	pilot.decisions = pilot.decisions[1:]
+	return controls
+}
+
+// Decides the controls for the player's ship as things stand. Getting out of
+// the way comes first, but the pilot keeps shooting whenever its target lines
+// up with the ship.
+func (pilot *Pilot) decide(state *GameState, index int) input.Controls {
+	player := &state.players[index]
+	ship := player.ship
+	controls := input.Controls{}
+	if ship.IsDead() || player.IsDown() {
+		return controls
+	}
+	level := pilotSkillLevels[pilot.Skill]
+
+	pilot.wobbleTimer -= TICK
+	if pilot.wobbleTimer <= 0 {
+		pilot.aimError = (pilot.random.Float32()*2 - 1) * level.aimError
+		pilot.wobbleTimer = PILOT_AIM_WOBBLE
+	}
+
+	aim, hasTarget := pilot.chooseTarget(state, player)
+	if hasTarget {
+		aim = rl.Vector2Rotate(aim, pilot.aimError)
+	}
+
+	threat, hasThreat := findThreat(state, player, level.threatHorizon)
+	switch {
+	case hasThreat && threat.time < PILOT_PANIC_TIME && level.hyperspace:
+		controls.Hyperspace = true
+	case hasThreat:
+		escape := threat.escapeDirection()
+		controls.Rotate = steer(ship, escape)
+		if angleBetween(ship.Heading(), escape) < PILOT_THRUST_TOLERANCE {
+			controls.Thrust = 1
+		}
+	case hasTarget:
+		controls.Rotate = steer(ship, aim)
+	}
+
+	weapon := ship.Weapon
+	if hasTarget && angleBetween(ship.Heading(), aim) < PILOT_AIM_TOLERANCE &&
+		!player.overheated[weapon] && player.heat[weapon] < PILOT_HEAT_LIMIT {
+		controls.Fire = true
+	}
+	return controls
+}
+
+// Something on course to hit the ship, relative to the ship.
+type threat struct {
+	offset rl.Vector2 // Position relative to the ship.
+	vel    rl.Vector2 // Velocity relative to the ship, per tick.
+	time   float32    // Seconds until it hits.
+}
+
+// Returns the direction that gets the ship out of the way of the threat
+// fastest: sideways to the threat's path, on the side away from it. Threats
+// which aren't moving relative to the ship are backed away from instead.
+func (threat threat) escapeDirection() rl.Vector2 {
+	if rl.Vector2Length(threat.vel) < 0.01 {
+		return rl.Vector2Normalize(rl.Vector2Negate(threat.offset))
+	}
+
+	sideways := rl.Vector2Normalize(rl.Vector2{X: -threat.vel.Y, Y: threat.vel.X})
+	if rl.Vector2DotProduct(sideways, threat.offset) > 0 {
+		sideways = rl.Vector2Negate(sideways)
+	}
+	return sideways
+}
+
+// Returns whatever will hit the player's ship soonest within the horizon, in
+// seconds. Asteroids, the boss and, when ships can be hit, other players'
+// projectiles are all threats.
+func findThreat(state *GameState, player *Player, horizon float32) (threat, bool) {
+	ship := player.ship
+	shipVel := shipVelocity(ship)
+	soonest := threat{time: horizon}
+	found := false
+
+	consider := func(pos rl.Vector2, vel rl.Vector2, radius float32) {
+		offset := wrappedOffset(ship.Pos, pos)
+		relative := rl.Vector2Subtract(vel, shipVel)
+		ticks, ok := timeToCollision(offset, relative, radius+entities.SHIP_HITBOX_RADIUS+PILOT_SAFETY_MARGIN)
+		if ok && ticks*TICK < soonest.time {
+			soonest = threat{offset: offset, vel: relative, time: ticks * TICK}
+			found = true
+		}
+	}
+
+	timeScale := asteroidTimeScale(state)
+	for _, asteroid := range state.asteroids {
+		consider(asteroid.Pos, asteroidVelocity(asteroid, timeScale), float32(asteroid.Hitbox))
+	}
+	if state.boss.Active {
+		for _, collider := range state.boss.Colliders() {
+			consider(collider.Pos, rl.Vector2{}, float32(collider.Hitbox))
+		}
+	}
+	if canHitShips(state) {
+		for _, bullet := range state.bullets {
+			if bullet.Owner != player.index {
+				consider(bullet.Start, rl.Vector2Multiply(bullet.Vel, bullet.Dir), bullet.Radius)
+			}
+		}
+	}
+	return soonest, found
+}
+
+// Picks what to shoot at and returns the direction to aim in to hit it, or
+// false if there is nothing worth shooting. Targets are ranked by their points
+// over their distance, and anything about to hit the ship is ranked higher
+// the sooner it will. Versus pilots hunt the other ships too.
+func (pilot *Pilot) chooseTarget(state *GameState, player *Player) (rl.Vector2, bool) {
+	ship := player.ship
+	shipVel := shipVelocity(ship)
+	speed := ship.Weapon.Tuning().Speed
+	best := float32(0)
+	aim := rl.Vector2{}
+
+	consider := func(pos rl.Vector2, vel rl.Vector2, radius float32, value float32) {
+		offset := wrappedOffset(ship.Pos, pos)
+		priority := value / (rl.Vector2Length(offset) + 100)
+		if ticks, ok := timeToCollision(offset, rl.Vector2Subtract(vel, shipVel), radius+entities.SHIP_HITBOX_RADIUS); ok {
+			priority += PILOT_DANGER_WEIGHT / (ticks*TICK + 0.1)
+		}
+
+		if priority > best {
+			best = priority
+			aim = leadAim(offset, vel, speed)
+		}
+	}
+
+	timeScale := asteroidTimeScale(state)
+	for _, asteroid := range state.asteroids {
+		consider(asteroid.Pos, asteroidVelocity(asteroid, timeScale), float32(asteroid.Hitbox), float32(asteroid.Score))
+	}
+	// The boss' core absorbs shots, so only its armour and weak points are
+	// worth shooting at.
+	if state.boss.Active {
+		for _, segment := range state.boss.Segments {
+			consider(segment.Asteroid.Pos, rl.Vector2{}, float32(segment.Asteroid.Hitbox), max(float32(segment.Asteroid.Score), PILOT_BOSS_VALUE))
+		}
+		for _, weakPoint := range state.boss.WeakPoints {
+			if weakPoint.Health > 0 {
+				consider(state.boss.WeakPointPos(weakPoint), rl.Vector2{}, entities.BOSS_WEAK_POINT_RADIUS, PILOT_BOSS_VALUE)
+			}
+		}
+	}
+	if state.mode == Versus {
+		for _, other := range state.players {
+			if other.index != player.index && !other.IsDown() && !other.ship.IsDead() {
+				consider(other.ship.Pos, shipVelocity(other.ship), entities.SHIP_HITBOX_RADIUS, PILOT_SHIP_VALUE)
+			}
+		}
+	}
+
+	return aim, best > 0
+}
+
+// Returns the direction to shoot in from the ship to hit a target at `offset`
+// moving at `vel` per tick with a projectile moving at `speed` per tick. The
+// shot is led to where the target will be when the shot gets there. Beams and
+// targets that can't be caught are aimed at directly.
+func leadAim(offset rl.Vector2, vel rl.Vector2, speed float32) rl.Vector2 {
+	if speed <= 0 {
+		return rl.Vector2Normalize(offset)
+	}
+
+	// The shot meets the target after t ticks when |offset + vel*t| = speed*t.
+	a := rl.Vector2DotProduct(vel, vel) - speed*speed
+	b := 2 * rl.Vector2DotProduct(offset, vel)
+	c := rl.Vector2DotProduct(offset, offset)
+	t, ok := smallestPositiveRoot(a, b, c)
+	if !ok {
+		return rl.Vector2Normalize(offset)
+	}
+	return rl.Vector2Normalize(rl.Vector2Add(offset, rl.Vector2Scale(vel, t)))
+}
+
+// Returns the ticks until something at `offset` moving at `vel` per tick
+// comes within `radius` of the ship, or false if it never does. Anything
+// already within the radius collides straight away.
+func timeToCollision(offset rl.Vector2, vel rl.Vector2, radius float32) (float32, bool) {
+	c := rl.Vector2DotProduct(offset, offset) - radius*radius
+	if c <= 0 {
+		return 0, true
+	}
+	a := rl.Vector2DotProduct(vel, vel)
+	b := 2 * rl.Vector2DotProduct(offset, vel)
+	return smallestPositiveRoot(a, b, c)
+}
+
+// Returns the smallest positive root of at² + bt + c, or false if it has none.
+func smallestPositiveRoot(a float32, b float32, c float32) (float32, bool) {
+	if math.Abs(float64(a)) < 1e-6 {
+		if b >= 0 {
+			return 0, false
+		}
+		return -c / b, true
+	}
+
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return 0, false
+	}
+	root := float32(math.Sqrt(float64(discriminant)))
+	t1 := (-b - root) / (2 * a)
+	t2 := (-b + root) / (2 * a)
+	switch {
+	case min(t1, t2) > 0:
+		return min(t1, t2), true
+	case max(t1, t2) > 0:
+		return max(t1, t2), true
+	}
+	return 0, false
+}
+
+// Returns how far `to` is from `from` the short way round, as the ship wraps
+// around the edges of the window.
+func wrappedOffset(from rl.Vector2, to rl.Vector2) rl.Vector2 {
+	offset := rl.Vector2Subtract(to, from)
+	offset.X = float32(math.Remainder(float64(offset.X), SCREEN_WIDTH))
+	offset.Y = float32(math.Remainder(float64(offset.Y), SCREEN_HEIGHT))
+	return offset
+}
+
+// Returns how far the ship moves every tick.
+func shipVelocity(ship entities.Ship) rl.Vector2 {
+	return rl.Vector2Add(rl.Vector2Multiply(ship.Vel, ship.Heading()), ship.Drift)
+}
+
+// Returns how far the asteroid moves every tick.
+func asteroidVelocity(asteroid entities.Asteroid, timeScale float32) rl.Vector2 {
+	return rl.Vector2Scale(rl.Vector2Multiply(asteroid.Vel, asteroid.Dir), timeScale)
+}
+
+// Returns how hard to rotate to turn the ship towards `direction`, turning
+// at full speed until the last tick's worth of rotation.
+func steer(ship entities.Ship, direction rl.Vector2) float32 {
+	desired := float32(math.Atan2(float64(-direction.X), float64(direction.Y)))
+	diff := float32(math.Remainder(float64(desired-ship.Rot), 2*math.Pi))
+	return rl.Clamp(diff/config.Get().Ship.RotationSpeed, -1, 1)
+}
+
+// Returns the angle in radians between two directions.
+func angleBetween(a rl.Vector2, b rl.Vector2) float32 {
+	cos := rl.Vector2DotProduct(rl.Vector2Normalize(a), rl.Vector2Normalize(b))
+	return float32(math.Acos(float64(rl.Clamp(cos, -1, 1))))
+}
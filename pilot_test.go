@@ -0,0 +1,215 @@
+package main
+
+import (
+	"asteroids/internal/constants"
+	"asteroids/internal/entities"
+	"asteroids/internal/scores"
+	"asteroids/internal/utils"
+	"math"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Returns a game with a single stationary ship at `pos` facing down the
+// screen, and nothing else in it.
+func newPilotTestState(t *testing.T, pos rl.Vector2) GameState {
+	t.Helper()
+	state := newTestState(t, 1)
+	state.asteroids = nil
+	state.hazards = nil
+	state.players[0].ship.Pos = pos
+	state.players[0].ship.Vel = rl.Vector2{}
+	state.players[0].ship.Rot = 0
+	return state
+}
+
+// Adds an asteroid at `pos` moving `vel` every tick and returns its index.
+func addMovingAsteroid(state *GameState, pos rl.Vector2, vel rl.Vector2) int {
+	i := addAsteroid(state, pos, entities.Medium, entities.Rock)
+	state.asteroids[i].Dir = rl.Vector2Normalize(vel)
+	speed := rl.Vector2Length(vel)
+	state.asteroids[i].Vel = rl.Vector2{X: speed, Y: speed}
+	return i
+}
+
+func TestParsePilotSkill(t *testing.T) {
+	for skill := range PilotSkill(NUM_PILOT_SKILLS) {
+		if parsed, ok := ParsePilotSkill(skill.String()); !ok || parsed != skill {
+			t.Errorf("ParsePilotSkill(%q) = %v, %v", skill, parsed, ok)
+		}
+	}
+	if _, ok := ParsePilotSkill("ace"); !ok {
+		t.Error("ParsePilotSkill() is case sensitive")
+	}
+	if _, ok := ParsePilotSkill("expert"); ok {
+		t.Error("ParsePilotSkill() accepted an unknown skill")
+	}
+}
+
+func TestSmallestPositiveRoot(t *testing.T) {
+	tests := []struct {
+		a, b, c float32
+		want    float32
+		ok      bool
+	}{
+		{1, -3, 2, 1, true}, // Roots at 1 and 2.
+		{1, 1, -2, 1, true}, // Roots at -2 and 1.
+		{1, 3, 2, 0, false}, // Roots at -1 and -2.
+		{1, 0, 1, 0, false}, // No real roots.
+		{0, -2, 4, 2, true}, // Linear.
+		{0, 2, 4, 0, false}, // Linear with a negative root.
+	}
+	for _, test := range tests {
+		got, ok := smallestPositiveRoot(test.a, test.b, test.c)
+		if ok != test.ok || (ok && math.Abs(float64(got-test.want)) > 1e-4) {
+			t.Errorf("smallestPositiveRoot(%v, %v, %v) = %v, %v, want %v, %v", test.a, test.b, test.c, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestTimeToCollision(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset rl.Vector2
+		vel    rl.Vector2
+		want   float32
+		ok     bool
+	}{
+		{"head on", rl.Vector2{X: 100}, rl.Vector2{X: -10}, 9, true},
+		{"passing by", rl.Vector2{X: 100, Y: 50}, rl.Vector2{X: -10}, 0, false},
+		{"moving away", rl.Vector2{X: 100}, rl.Vector2{X: 10}, 0, false},
+		{"already touching", rl.Vector2{X: 5}, rl.Vector2{}, 0, true},
+	}
+	for _, test := range tests {
+		got, ok := timeToCollision(test.offset, test.vel, 10)
+		if ok != test.ok || (ok && math.Abs(float64(got-test.want)) > 1e-4) {
+			t.Errorf("%s: timeToCollision() = %v, %v, want %v, %v", test.name, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestLeadAim(t *testing.T) {
+	offset := rl.Vector2{X: 100}
+	if aim := leadAim(offset, rl.Vector2{}, 10); aim != (rl.Vector2{X: 1}) {
+		t.Errorf("aimed at %v for a still target, want straight at it", aim)
+	}
+	if aim := leadAim(offset, rl.Vector2{Y: 5}, 0); aim != (rl.Vector2{X: 1}) {
+		t.Errorf("aimed a beam at %v, want straight at the target", aim)
+	}
+
+	// The shot and the target must end up in the same place.
+	vel := rl.Vector2{Y: 5}
+	aim := leadAim(offset, vel, 10)
+	ticks := 100 / (10 * aim.X)
+	target := rl.Vector2Add(offset, rl.Vector2Scale(vel, ticks))
+	if shot := rl.Vector2Scale(aim, 10*ticks); rl.Vector2Distance(shot, target) > 0.01 {
+		t.Errorf("shot ends up at %v, want the target at %v", shot, target)
+	}
+}
+
+func TestPilotReactionDelay(t *testing.T) {
+	state := newPilotTestState(t, rl.Vector2{X: 400, Y: 300})
+	addMovingAsteroid(&state, rl.Vector2{X: 400, Y: 500}, rl.Vector2{})
+
+	pilot := NewPilot(Rookie)
+	pilot.wobbleTimer = PILOT_AIM_WOBBLE // Keeps the aim true until the pilot reacts.
+	delay := int(pilotSkillLevels[Rookie].reactionDelay * constants.TICK_RATE)
+	for tick := range delay {
+		if controls := pilot.Fly(&state, 0); controls.Fire {
+			t.Fatalf("fired on tick %d, before reacting", tick)
+		}
+	}
+	if controls := pilot.Fly(&state, 0); !controls.Fire {
+		t.Error("didn't fire at the asteroid ahead once it reacted")
+	}
+}
+
+func TestPilotDecisions(t *testing.T) {
+	ship := rl.Vector2{X: 400, Y: 300}
+	tests := []struct {
+		name  string
+		skill PilotSkill
+		pos   rl.Vector2
+		vel   rl.Vector2
+		fire  bool
+		move  bool
+		jump  bool
+	}{
+		{name: "shoots ahead", skill: Veteran, pos: rl.Vector2{X: 400, Y: 600}, fire: true},
+		{name: "turns towards a target", skill: Veteran, pos: rl.Vector2{X: 700, Y: 300}, move: true},
+		{name: "dodges", skill: Veteran, pos: rl.Vector2{X: 480, Y: 300}, vel: rl.Vector2{X: -4}, move: true},
+		{name: "jumps away", skill: Ace, pos: rl.Vector2{X: 440, Y: 300}, vel: rl.Vector2{X: -20}, jump: true},
+		{name: "rookies don't jump", skill: Rookie, pos: rl.Vector2{X: 440, Y: 300}, vel: rl.Vector2{X: -20}, move: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := newPilotTestState(t, ship)
+			addMovingAsteroid(&state, test.pos, test.vel)
+
+			pilot := NewPilot(test.skill)
+			pilot.aimError = 0
+			pilot.wobbleTimer = PILOT_AIM_WOBBLE
+			controls := pilot.decide(&state, 0)
+
+			if controls.Fire != test.fire {
+				t.Errorf("fire = %v, want %v", controls.Fire, test.fire)
+			}
+			if moved := controls.Rotate != 0 || controls.Thrust != 0; moved != test.move {
+				t.Errorf("rotate %v and thrust %v, want moving %v", controls.Rotate, controls.Thrust, test.move)
+			}
+			if controls.Hyperspace != test.jump {
+				t.Errorf("hyperspace = %v, want %v", controls.Hyperspace, test.jump)
+			}
+		})
+	}
+}
+
+func TestPilotHoldsFireWhenHot(t *testing.T) {
+	state := newPilotTestState(t, rl.Vector2{X: 400, Y: 300})
+	addMovingAsteroid(&state, rl.Vector2{X: 400, Y: 600}, rl.Vector2{})
+	player := &state.players[0]
+	player.heat[player.ship.Weapon] = PILOT_HEAT_LIMIT
+
+	pilot := NewPilot(Ace)
+	pilot.wobbleTimer = PILOT_AIM_WOBBLE
+	if controls := pilot.decide(&state, 0); controls.Fire {
+		t.Error("fired with the weapon about to overheat")
+	}
+}
+
+func TestAssignPilots(t *testing.T) {
+	state := newTestState(t, 3)
+	assignPilots(&state, 2, Veteran)
+	for i, want := range []bool{false, true, true} {
+		if isPiloted(&state, i) != want {
+			t.Errorf("player %d piloted = %v, want %v", i+1, !want, want)
+		}
+	}
+
+	state.mode = Daily
+	assignPilots(&state, 2, Veteran)
+	for i := range state.players {
+		if isPiloted(&state, i) {
+			t.Errorf("player %d of the daily challenge is piloted", i+1)
+		}
+	}
+}
+
+func TestNoHighScoresWithBots(t *testing.T) {
+	state := newTestState(t, 2)
+	assignPilots(&state, 1, Veteran)
+	state.players[0].Score = 1000
+	state.isGameOver = true
+
+	table := scores.Table{}
+	toast := utils.Toast{}
+	finishGame(&state, &DailyRun{}, table, &toast)
+	if len(table.Top(state.mode.Key())) != 0 {
+		t.Errorf("a game with a bot was entered in the high scores: %v", table)
+	}
+	if state.highScoreRank != 0 {
+		t.Errorf("high score rank = %d, want 0", state.highScoreRank)
+	}
+}
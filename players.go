@@ -0,0 +1,264 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/entities"
+	"asteroids/internal/input"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Seconds that a ship is dead for before it respawns.
+const RESPAWN_TIME = 5
+
+// Returns how long a ship stays dead for before it respawns. Versus ships
+// playing for kills come back sooner.
+func respawnTime(state *GameState) float32 {
+	if state.mode == Versus && isPlayingForKills() {
+		return config.Get().Versus.RespawnTime
+	}
+	return RESPAWN_TIME
+}
+
+// Distance between the ships when a multiplayer game starts.
+const PLAYER_SPACING = 80
+
+// Ship colours of the players, in order. The first player keeps the classic
+// white ship.
+var PLAYER_COLORS = [input.MAX_PLAYERS]rl.Color{rl.RayWhite, rl.SkyBlue, rl.Orange, rl.Lime}
+
+// A ship and everything which belongs to the player flying it. Each player in
+// local multiplayer has their own lives, score, weapons and power-ups.
+type Player struct {
+	index int // Index of the player, which owns their bullets.
+	ship  entities.Ship
+	lives uint8
+	Score uint64
+
+	weaponTimer float32                       // Seconds until the selected weapon can fire again.
+	charge      float32                       // Seconds that the charge shot has been charged for.
+	wasFiring   bool                          // Whether fire was held last frame, used to release charge shots.
+	beam        entities.Beam                 // The laser beam, active while the laser is firing.
+	heat        [entities.NUM_WEAPONS]float32 // Heat of each weapon, overheating at 1.
+	overheated  [entities.NUM_WEAPONS]bool    // Weapons locked until they cool down.
+
+	effects [entities.NUM_POWERUP_KINDS]float32 // Seconds left of each timed power-up.
+	combo   Combo
+
+	extraLivesAwarded int     // Number of extra life thresholds the score has passed.
+	lifeFlashTimer    float32 // Seconds left of the HUD flash after an extra life.
+	reviveTimer       float32 // Seconds that a partner has been next to the downed ship.
+
+	kills int // Other ships destroyed this versus round.
+	wins  int // Versus rounds won.
+}
+
+// Creates the player at `index` out of `count` players. The ships start side
+// by side in the middle of the window, except in versus where they start
+// around the middle facing each other.
+func NewPlayer(index int, count int, mode GameMode) Player {
+	ship := entities.NewShip()
+	ship.Pos = playerSpawn(index, count, mode)
+	ship.Color = PLAYER_COLORS[index]
+	if mode == Versus {
+		toCentre := rl.Vector2Subtract(entities.NewShip().Pos, ship.Pos)
+		ship.Rot = float32(math.Atan2(float64(-toCentre.X), float64(toCentre.Y)))
+	}
+
+	return Player{
+		index: index,
+		ship:  ship,
+		lives: startingLives(mode),
+	}
+}
+
+// Returns where the ship of the player at `index` out of `count` players
+// starts.
+func playerSpawn(index int, count int, mode GameMode) rl.Vector2 {
+	if mode == Versus {
+		return versusSpawn(index, count)
+	}
+
+	pos := entities.NewShip().Pos
+	pos.X += (float32(index) - float32(count-1)/2) * PLAYER_SPACING
+	return pos
+}
+
+// Returns the name of the player shown on the HUD.
+func (player Player) Name() string {
+	return [...]string{"P1", "P2", "P3", "P4"}[player.index]
+}
+
+// Returns true once the player has run out of lives. In co-op a downed ship
+// can still be revived by a partner.
+func (player Player) IsDown() bool {
+	return player.lives == 0
+}
+
+// Returns the combined score of every player.
+func totalScore(state *GameState) uint64 {
+	total := uint64(0)
+	for _, player := range state.players {
+		total += player.Score
+	}
+	return total
+}
+
+// Returns true if any player's ship is alive.
+func anyAlive(state *GameState) bool {
+	for _, player := range state.players {
+		if !player.ship.IsDead() {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true once every player is down.
+func allDown(state *GameState) bool {
+	for _, player := range state.players {
+		if !player.IsDown() {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the ship that the next asteroid goes after, picking the living ships
+// in turn. Falls back to the first ship if every ship is dead.
+func nextTarget(state *GameState) rl.Vector2 {
+	for range state.players {
+		state.target = (state.target + 1) % len(state.players)
+		if ship := state.players[state.target].ship; !ship.IsDead() {
+			return ship.Pos
+		}
+	}
+	return state.players[0].ship.Pos
+}
+
+// Returns the first ship which is alive, which the boss goes after. Falls back
+// to the first ship if every ship is dead.
+func leadShip(state *GameState) rl.Vector2 {
+	for _, player := range state.players {
+		if !player.ship.IsDead() {
+			return player.ship.Pos
+		}
+	}
+	return state.players[0].ship.Pos
+}
+
+// Counts down the death timers of the ships. The ships of downed players stay
+// dead until they are revived.
+func updateDeathTimers(state *GameState) {
+	for i := range state.players {
+		player := &state.players[i]
+		if player.ship.DeathTimer > 0 && !player.IsDown() {
+			player.ship.DeathTimer -= TICK
+		}
+	}
+}
+
+// Returns true if downed players can be revived by a partner, which is the
+// case in any multiplayer game apart from versus.
+func canRevive(state *GameState) bool {
+	return len(state.players) > 1 && state.mode != Versus
+}
+
+// Revives downed players whose partner has stayed next to their ship for the
+// revive time. The revived ship comes back where it went down with one life.
+func updateRevives(state *GameState) {
+	if !canRevive(state) {
+		return
+	}
+	tuning := config.Get().Coop
+
+	for i := range state.players {
+		downed := &state.players[i]
+		if !downed.IsDown() {
+			continue
+		}
+
+		if isPartnerNearby(state, downed) {
+			downed.reviveTimer += TICK
+		} else {
+			downed.reviveTimer = 0
+		}
+
+		if downed.reviveTimer >= tuning.ReviveTime {
+			downed.lives = 1
+			downed.ship.DeathTimer = 0
+			downed.reviveTimer = 0
+			state.popups = append(state.popups, entities.NewPopup(downed.ship.Pos, "REVIVED", downed.ship.Color))
+			input.SendFeedback(downed.index, input.FeedbackExtraLife)
+		}
+	}
+}
+
+// Returns true if the living ship of another player is within the revive
+// radius of the downed player's ship.
+func isPartnerNearby(state *GameState, downed *Player) bool {
+	radius := config.Get().Coop.ReviveRadius
+	for _, partner := range state.players {
+		if partner.index != downed.index && !partner.ship.IsDead() &&
+			rl.Vector2Distance(partner.ship.Pos, downed.ship.Pos) <= radius {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the players' weapons can hit each other's ships, which they
+// always can in versus and can in co-op with friendly fire enabled.
+func canHitShips(state *GameState) bool {
+	return state.mode == Versus || config.Get().Coop.FriendlyFire && len(state.players) > 1
+}
+
+// Checks for bullets hitting the ships of other players.
+func checkForShipHits(state *GameState) {
+	if !canHitShips(state) {
+		return
+	}
+
+	for i := len(state.bullets) - 1; i >= 0; i-- {
+		bullet := state.bullets[i]
+		for j := range state.players {
+			victim := &state.players[j]
+			if bullet.Owner == victim.index || victim.ship.IsDead() ||
+				!rl.CheckCollisionCircles(bullet.Start, bullet.Radius, victim.ship.Pos, entities.SHIP_HITBOX_RADIUS) {
+				continue
+			}
+
+			shootShip(state, &state.players[bullet.Owner], victim)
+			state.bullets = append(state.bullets[:i], state.bullets[i+1:]...)
+			break
+		}
+	}
+}
+
+// Hits the victim's ship with the shooter's weapon. A charged shield absorbs
+// the hit as it would an asteroid.
+func shootShip(state *GameState, shooter *Player, victim *Player) {
+	if victim.ship.Shields > 0 {
+		victim.ship.Shields -= 1
+		return
+	}
+
+	killShip(state, victim)
+	creditKill(state, shooter, victim)
+}
+
+// Returns the hitboxes of every living ship other than the shooter's, for the
+// laser to be cast against, along with the players they belong to.
+func otherShips(state *GameState, shooter *Player) ([]entities.Asteroid, []*Player) {
+	colliders := []entities.Asteroid{}
+	owners := []*Player{}
+	for i := range state.players {
+		player := &state.players[i]
+		if player.index != shooter.index && !player.ship.IsDead() {
+			colliders = append(colliders, entities.Asteroid{Pos: player.ship.Pos, Hitbox: entities.SHIP_HITBOX_RADIUS})
+			owners = append(owners, player)
+		}
+	}
+	return colliders, owners
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Returns a co-op game where the second player is down right next to the
+// first player's ship.
+func newReviveTestState(t *testing.T) GameState {
+	t.Helper()
+	state := newTestState(t, 2)
+	state.players[0].ship.Pos = rl.Vector2{X: 400, Y: 300}
+	state.players[1].ship.Pos = rl.Vector2{X: 420, Y: 300}
+	state.players[1].ship.DeathTimer = 1
+	state.players[1].lives = 0
+	return state
+}
+
+// Runs the revives for the given number of seconds.
+func runRevives(state *GameState, seconds float32) {
+	for range int(seconds / TICK) {
+		updateRevives(state)
+	}
+}
+
+func TestPartnerRevives(t *testing.T) {
+	state := newReviveTestState(t)
+	downed := &state.players[1]
+	reviveTicks := int(config.Get().Coop.ReviveTime / TICK)
+
+	ticks := 0
+	for downed.IsDown() && ticks <= 2*reviveTicks {
+		updateRevives(&state)
+		ticks++
+	}
+	if ticks < reviveTicks || ticks > reviveTicks+1 {
+		t.Errorf("revived after %d ticks, want the revive time of %d", ticks, reviveTicks)
+	}
+	if downed.lives != 1 || downed.ship.IsDead() {
+		t.Errorf("%d lives and dead %v once revived, want back with one life", downed.lives, downed.ship.IsDead())
+	}
+	if downed.ship.Pos != (rl.Vector2{X: 420, Y: 300}) {
+		t.Errorf("revived at %v, want where the ship went down", downed.ship.Pos)
+	}
+}
+
+func TestReviveNeedsPartnerToStay(t *testing.T) {
+	state := newReviveTestState(t)
+	reviveTime := config.Get().Coop.ReviveTime
+
+	runRevives(&state, reviveTime-1)
+	state.players[0].ship.Pos = rl.Vector2{X: 1000, Y: 800}
+	updateRevives(&state)
+	state.players[0].ship.Pos = rl.Vector2{X: 400, Y: 300}
+	runRevives(&state, 1.5)
+	if !state.players[1].IsDown() {
+		t.Error("revived although the partner flew off, want the revive to start over")
+	}
+}
+
+func TestNoRevivesInVersus(t *testing.T) {
+	state := newReviveTestState(t)
+	state.mode = Versus
+
+	runRevives(&state, config.Get().Coop.ReviveTime+1)
+	if !state.players[1].IsDown() {
+		t.Error("a versus player was revived")
+	}
+}
+
+func TestNextTargetSkipsDeadShips(t *testing.T) {
+	state := newTestState(t, 3)
+	for i := range state.players {
+		state.players[i].ship.Pos = rl.Vector2{X: float32(100 * (i + 1))}
+	}
+	state.players[1].ship.DeathTimer = 1
+
+	for _, want := range []float32{300, 100, 300} {
+		if target := nextTarget(&state); target.X != want {
+			t.Errorf("asteroid sent after the ship at %v, want %v", target.X, want)
+		}
+	}
+}
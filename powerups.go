@@ -0,0 +1,95 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/entities"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	RAPID_FIRE_RATE  = 4    // Rapid fire divides the bullet cooldown by this much.
+	SPREAD_ANGLE     = 0.26 // Radians between the bullets of a spread shot.
+	TIME_SLOW_FACTOR = 0.5  // Asteroid speed while time is slowed.
+)
+
+// Returns true while a timed power-up is active for the player.
+func isActive(player *Player, kind entities.PowerUpKind) bool {
+	return player.effects[kind] > 0
+}
+
+// Returns how fast asteroids move relative to normal. Time slows down for
+// everyone while any player has the time slow power-up.
+func asteroidTimeScale(state *GameState) float32 {
+	for i := range state.players {
+		if isActive(&state.players[i], entities.TimeSlow) {
+			return TIME_SLOW_FACTOR
+		}
+	}
+	return 1
+}
+
+// Drops a pickup where an asteroid was destroyed, based on the drop chance.
+func dropPowerUp(state *GameState, pos rl.Vector2) {
+	if powerUp, ok := entities.MaybeDropPowerUp(pos); ok {
+		state.powerUps = append(state.powerUps, powerUp)
+	}
+}
+
+// Moves the pickups, removes any which have expired and collects those a ship
+// flies into. Also counts down every player's active power-ups.
+func updatePowerUps(state *GameState) {
+	for i := len(state.powerUps) - 1; i >= 0; i-- {
+		entities.UpdatePowerUp(&state.powerUps[i], TICK)
+
+		collector := collectingPlayer(state, state.powerUps[i])
+		if collector != nil {
+			applyPowerUp(state, collector, state.powerUps[i].Kind)
+		}
+
+		if collector != nil || state.powerUps[i].IsExpired() {
+			state.powerUps = append(state.powerUps[:i], state.powerUps[i+1:]...)
+		}
+	}
+
+	for i := range state.players {
+		player := &state.players[i]
+		for kind := range player.effects {
+			player.effects[kind] = max(0, player.effects[kind]-TICK)
+		}
+	}
+}
+
+// Returns the first player whose ship is touching the pickup, or nil if no
+// ship is.
+func collectingPlayer(state *GameState, powerUp entities.PowerUp) *Player {
+	for i := range state.players {
+		player := &state.players[i]
+		if !player.ship.IsDead() && rl.CheckCollisionCircles(
+			player.ship.Pos,
+			entities.SHIP_HITBOX_RADIUS,
+			powerUp.Pos,
+			entities.POWERUP_HITBOX,
+		) {
+			return player
+		}
+	}
+	return nil
+}
+
+// Applies a power-up collected by the player. Timed power-ups restart their
+// timer if they are already active.
+func applyPowerUp(state *GameState, player *Player, kind entities.PowerUpKind) {
+	tuning := config.Get().PowerUps
+
+	switch kind {
+	case entities.ShieldRecharge:
+		player.ship.Shields = tuning.MaxShields
+	case entities.ExtraLife:
+		gainLife(state, player)
+	case entities.Gem:
+		addScore(state, player, player.ship.Pos, entities.GEM_SCORE)
+	default:
+		player.effects[kind] = tuning.EffectDuration
+	}
+}
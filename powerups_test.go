@@ -0,0 +1,85 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/entities"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestDropChance(t *testing.T) {
+	state := newTestState(t, 1)
+	dropPowerUp(&state, rl.Vector2{X: 100, Y: 100})
+	if len(state.powerUps) != 0 {
+		t.Errorf("dropped %d pickups with no drop chance", len(state.powerUps))
+	}
+
+	tuning := config.Get()
+	tuning.PowerUps.DropChance = 1
+	config.Set(tuning)
+	dropPowerUp(&state, rl.Vector2{X: 100, Y: 100})
+	if len(state.powerUps) != 1 || state.powerUps[0].Kind == entities.Gem {
+		t.Errorf("dropped %v with a certain drop chance, want a single power-up", state.powerUps)
+	}
+}
+
+func TestCollectPowerUps(t *testing.T) {
+	tuning := config.Get().PowerUps
+	for kind := range entities.PowerUpKind(entities.NUM_POWERUP_KINDS) {
+		state := newTestState(t, 1)
+		player := &state.players[0]
+		player.ship.Shields = 0
+		lives := player.lives
+		state.powerUps = append(state.powerUps, entities.NewPowerUp(player.ship.Pos, kind))
+
+		updatePowerUps(&state)
+		if len(state.powerUps) != 0 {
+			t.Errorf("%s wasn't collected by the ship flying into it", kind)
+		}
+		switch {
+		case kind == entities.ShieldRecharge:
+			if player.ship.Shields != tuning.MaxShields {
+				t.Errorf("%d shields after a shield recharge, want %d", player.ship.Shields, tuning.MaxShields)
+			}
+		case kind == entities.ExtraLife:
+			if player.lives != lives+1 {
+				t.Errorf("%d lives after an extra life, want %d", player.lives, lives+1)
+			}
+		case !isActive(player, kind):
+			t.Errorf("%s isn't active once collected", kind)
+		}
+	}
+}
+
+func TestPowerUpsExpire(t *testing.T) {
+	state := newTestState(t, 1)
+	player := &state.players[0]
+	pickup := entities.NewPowerUp(rl.Vector2{X: 100, Y: 100}, entities.RapidFire)
+	pickup.Timer = TICK
+	state.powerUps = append(state.powerUps, pickup)
+	player.effects[entities.TimeSlow] = TICK
+
+	if asteroidTimeScale(&state) != TIME_SLOW_FACTOR {
+		t.Error("asteroids aren't slowed while time slow is active")
+	}
+	updatePowerUps(&state)
+	if len(state.powerUps) != 0 {
+		t.Error("the pickup outlived its lifetime")
+	}
+	if isActive(player, entities.TimeSlow) || asteroidTimeScale(&state) != 1 {
+		t.Error("time slow is still active after running out")
+	}
+}
+
+func TestDeadShipsDontCollect(t *testing.T) {
+	state := newTestState(t, 1)
+	player := &state.players[0]
+	player.ship.DeathTimer = 1
+	state.powerUps = append(state.powerUps, entities.NewPowerUp(player.ship.Pos, entities.RapidFire))
+
+	updatePowerUps(&state)
+	if len(state.powerUps) != 1 || isActive(player, entities.RapidFire) {
+		t.Error("a dead ship collected the pickup")
+	}
+}
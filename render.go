@@ -0,0 +1,402 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/entities"
+	"asteroids/internal/utils"
+	"fmt"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Font size of the HUD text at the virtual resolution.
+const HUD_FONT_SIZE = 30
+
+// Height of the multiplayer panels along the bottom of the window at the
+// virtual resolution.
+const PLAYER_PANEL_HEIGHT = 150
+
+// Returns how much the HUD is scaled by. The HUD follows the playfield scale
+// so that it stays readable on large monitors.
+func hudScale(viewport utils.Viewport) float32 {
+	return viewport.Scale
+}
+
+// Renders the game. The playfield is drawn into the viewport texture at the
+// virtual resolution, while the HUD is drawn directly to the window so that it
+// can be anchored to the window edges.
+func render(state *GameState, viewport utils.Viewport) {
+	renderWithCamera(state, viewport, rl.Camera2D{Zoom: 1})
+}
+
+// Renders the game with the playfield seen through a camera, which can zoom
+// in on part of it. The edges of the playfield are outlined so that they can
+// be told apart from empty space when zoomed in.
+func renderWithCamera(state *GameState, viewport utils.Viewport, camera rl.Camera2D) {
+	rl.BeginTextureMode(viewport.Target)
+	rl.ClearBackground(rl.Black)
+	rl.BeginMode2D(camera)
+	renderPlayfield(state)
+	if camera.Zoom > 1 {
+		rl.DrawRectangleLines(0, 0, SCREEN_WIDTH, SCREEN_HEIGHT, rl.DarkGray)
+	}
+	rl.EndMode2D()
+	rl.EndTextureMode()
+
+	utils.DrawViewport(viewport)
+	renderHUD(state, hudScale(viewport))
+}
+
+// Renders all of the entities in the game.
+func renderPlayfield(state *GameState) {
+	// Hazards are drawn first so that everything else is drawn on top of them.
+	for _, hazard := range state.hazards {
+		entities.DrawHazard(hazard)
+	}
+
+	// If a ship is moving forward, then we draw thrusters onto the ship for
+	// the effect. Downed ships wait to be revived by a partner.
+	for i := range state.players {
+		entities.RenderShip(&state.players[i].ship)
+		if state.players[i].IsDown() && canRevive(state) {
+			renderDownedShip(state.players[i])
+		}
+	}
+
+	// ------------------------------------------------------------------------
+	// Bullet rendering
+	// ------------------------------------------------------------------------
+	for _, bullet := range state.bullets {
+		entities.DrawBullet(bullet)
+	}
+	for i := range state.players {
+		player := &state.players[i]
+		entities.DrawBeam(player.beam)
+
+		renderHeatGauge(player)
+
+		// The charge shot's charge is shown as a ring growing around the ship.
+		if player.charge > 0 && !player.ship.IsDead() {
+			radius := entities.SHIP_HITBOX_RADIUS * player.charge / entities.CHARGE_MAX_TIME
+			rl.DrawCircleLinesV(player.ship.Pos, radius, rl.SkyBlue)
+		}
+	}
+	// ------------------------------------------------------------------------
+
+	// ------------------------------------------------------------------------
+	// Asteroid rendering
+	// ------------------------------------------------------------------------
+	for _, asteroid := range state.asteroids {
+		entities.DrawAsteroid(asteroid)
+	}
+	// ------------------------------------------------------------------------
+
+	for _, powerUp := range state.powerUps {
+		entities.DrawPowerUp(powerUp)
+	}
+
+	for _, explosion := range state.explosions {
+		entities.DrawExplosion(explosion)
+	}
+
+	entities.DrawBoss(state.boss)
+
+	for _, popup := range state.popups {
+		entities.DrawPopup(popup)
+	}
+
+	if state.mode == Zen {
+		renderSandbox(state)
+	}
+}
+
+// Renders a downed ship as a faded outline inside the revive radius. The ring
+// fills up while a partner stays close enough to revive the ship.
+func renderDownedShip(player Player) {
+	tuning := config.Get().Coop
+	pos := player.ship.Pos
+	color := player.ship.Color
+
+	entities.DrawShipIcon(pos, SCALE, THICKNESS, rl.Fade(color, 0.4))
+	rl.DrawCircleLinesV(pos, tuning.ReviveRadius, rl.Fade(color, 0.3))
+
+	if player.reviveTimer > 0 && tuning.ReviveTime > 0 {
+		progress := min(player.reviveTimer/tuning.ReviveTime, 1)
+		rl.DrawRing(pos, tuning.ReviveRadius-3, tuning.ReviveRadius, -90, -90+360*progress, 36, color)
+	}
+}
+
+// Renders the selected weapon's heat as a small bar underneath the player's
+// ship. The bar goes from green to red as the weapon heats up and blinks while
+// the weapon is overheated.
+func renderHeatGauge(player *Player) {
+	weapon := player.ship.Weapon
+	heat := player.heat[weapon]
+	if player.ship.IsDead() || heat <= 0 {
+		return
+	}
+
+	const (
+		GAUGE_WIDTH  = 36
+		GAUGE_HEIGHT = 4
+	)
+	x := player.ship.Pos.X - GAUGE_WIDTH/2
+	y := player.ship.Pos.Y + entities.SHIP_HITBOX_RADIUS + 14
+
+	color := rl.ColorLerp(rl.Green, rl.Red, heat)
+	if player.overheated[weapon] && int(heat*20)%2 == 0 {
+		color = rl.RayWhite
+	}
+
+	rl.DrawRectangleLinesEx(rl.Rectangle{X: x, Y: y, Width: GAUGE_WIDTH, Height: GAUGE_HEIGHT}, 1, rl.DarkGray)
+	rl.DrawRectangleV(rl.Vector2{X: x, Y: y}, rl.Vector2{X: GAUGE_WIDTH * heat, Y: GAUGE_HEIGHT}, color)
+}
+
+// Renders the warning for an incoming boss and the boss' health bar, split
+// into its phases, at the top centre of the window.
+func renderBossHUD(state *GameState, width int32, y int32, scale float32) {
+	fontSize := int32(20 * scale)
+
+	if state.bossWarningTimer > 0 && int(state.bossWarningTimer*4)%2 == 0 {
+		warning := "WARNING: BOSS APPROACHING"
+		rl.DrawText(warning, width/2-rl.MeasureText(warning, fontSize*2)/2, y+fontSize*3, fontSize*2, rl.Red)
+	}
+
+	if !state.boss.Active {
+		return
+	}
+
+	barWidth := float32(width) * 0.4
+	barHeight := 10 * scale
+	x := float32(width)/2 - barWidth/2
+	barY := float32(y + fontSize + int32(4*scale))
+	health := float32(state.boss.Health()) / float32(state.boss.MaxHealth)
+
+	label := fmt.Sprintf("BOSS - PHASE %d", state.boss.Phase)
+	rl.DrawText(label, width/2-rl.MeasureText(label, fontSize)/2, y, fontSize, rl.Red)
+	rl.DrawRectangleV(rl.Vector2{X: x, Y: barY}, rl.Vector2{X: barWidth * health, Y: barHeight}, rl.Red)
+	rl.DrawRectangleLinesEx(rl.Rectangle{X: x, Y: barY, Width: barWidth, Height: barHeight}, 1, rl.RayWhite)
+
+	// Marks where each phase begins.
+	for phase := 1; phase < entities.BOSS_PHASES; phase++ {
+		markerX := x + barWidth*float32(phase)/entities.BOSS_PHASES
+		rl.DrawLineV(rl.Vector2{X: markerX, Y: barY}, rl.Vector2{X: markerX, Y: barY + barHeight}, rl.RayWhite)
+	}
+}
+
+// Renders the score, lives and any messages anchored to the window edges. A
+// single player has the classic layout, while in multiplayer every player's
+// score, lives and status are shown in their own panel along the bottom.
+func renderHUD(state *GameState, scale float32) {
+	width := int32(rl.GetScreenWidth())
+	height := int32(rl.GetScreenHeight())
+	fontSize := int32(HUD_FONT_SIZE * scale)
+	margin := int32(16 * scale)
+	solo := len(state.players) == 1
+	lifeFlashTimer := float32(0)
+	for _, player := range state.players {
+		lifeFlashTimer = max(lifeFlashTimer, player.lifeFlashTimer)
+	}
+
+	// Renders the remaining lives as ship icons in the top right of the window.
+	// The newest life blinks while the extra life flash is active. Modes with a
+	// clock show it instead, as they have unlimited lives or only one.
+	iconSize := float32(fontSize)
+	clock, hasClock := modeClock(state)
+	if hasClock {
+		timeStr := fmt.Sprintf("%d:%02d", int(clock)/60, int(clock)%60)
+		timeColor := rl.RayWhite
+		if state.mode == TimeAttack && clock < 10 || lifeFlashTimer > 0 && int(lifeFlashTimer*8)%2 == 0 {
+			timeColor = rl.Yellow
+		}
+		rl.DrawText(timeStr, width-margin-rl.MeasureText(timeStr, fontSize), margin, fontSize, timeColor)
+	} else if solo {
+		renderLives(&state.players[0], rl.Vector2{X: float32(width - margin), Y: float32(margin) + iconSize/2}, iconSize, -1, 2*scale)
+	}
+
+	// Renders the difficulty underneath the lives, along with the adaptive
+	// adjustment if it is enabled.
+	difficultyStr := config.CurrentDifficulty().String()
+	if config.IsAdaptive() {
+		difficultyStr += fmt.Sprintf(" (adaptive x%.1f)", config.CurrentAdjustment().SpawnRate)
+	}
+	smallFontSize := fontSize * 2 / 3
+	rl.DrawText(difficultyStr, width-margin-rl.MeasureText(difficultyStr, smallFontSize), margin+int32(iconSize), smallFontSize, rl.Gray)
+
+	// Flashes the window briefly when an extra life is awarded.
+	if lifeFlashTimer > 0 {
+		alpha := 0.25 * lifeFlashTimer / LIFE_FLASH_DURATION
+		rl.DrawRectangle(0, 0, width, height, rl.Fade(rl.RayWhite, alpha))
+	}
+
+	// Warns of the next survival storm and the edge that it comes from.
+	if isStormWarning(state) && int(rl.GetTime()*4)%2 == 0 {
+		stormStr := fmt.Sprintf("STORM INCOMING: %s", strings.ToUpper(state.stormEdge.String()))
+		rl.DrawText(stormStr, width/2-rl.MeasureText(stormStr, fontSize)/2, height/4, fontSize, rl.Orange)
+	}
+
+	// Renders the death timer of the ship in the middle of the screen.
+	if ship := state.players[0].ship; solo && ship.IsDead() {
+		deathStr := fmt.Sprintf("Respawning in %.0f", ship.DeathTimer)
+		rl.DrawText(
+			deathStr,
+			width/2-rl.MeasureText(deathStr, fontSize)/2,
+			height/2,
+			fontSize,
+			rl.RayWhite,
+		)
+	}
+
+	// Renders the score in the top left of the window. Multiplayer games
+	// show the combined score of the team, and versus shows the round being
+	// played and how to win it instead.
+	scoreStr := fmt.Sprintf("Score: %d", totalScore(state))
+	switch {
+	case state.mode == Versus:
+		scoreStr = versusGoal(state)
+	case !solo:
+		scoreStr = fmt.Sprintf("Team: %d", totalScore(state))
+	}
+	rl.DrawText(scoreStr, margin, margin, fontSize, rl.RayWhite)
+
+	// Renders the selected weapon at the top centre of the window.
+	if solo {
+		weaponStr, weaponColor := weaponStatus(&state.players[0])
+		rl.DrawText(weaponStr, width/2-rl.MeasureText(weaponStr, smallFontSize)/2, margin, smallFontSize, weaponColor)
+	}
+
+	renderBossHUD(state, width, margin+fontSize, scale)
+
+	// Renders the shield charge and active power-ups with their remaining
+	// time underneath the score.
+	if solo {
+		renderPlayerStatus(state, &state.players[0], margin, margin+fontSize+margin/2, scale)
+	} else {
+		renderPlayerPanels(state, scale)
+	}
+
+	if state.mode == Versus {
+		renderScoreboard(state, scale)
+	}
+
+	// If the game is over, render the game over screen along with the score's
+	// place in the high scores.
+	if state.isGameOver {
+		utils.DrawGameOverScreen(width, height, scale)
+
+		if state.highScoreRank > 0 {
+			rankStr := fmt.Sprintf("New %s high score! #%d", state.mode, state.highScoreRank)
+			if len(state.players) > 1 {
+				rankStr = fmt.Sprintf("New %d player %s high score! #%d", len(state.players), state.mode, state.highScoreRank)
+			}
+			rl.DrawText(rankStr, width/2-rl.MeasureText(rankStr, fontSize)/2, height/2-int32(90*scale), fontSize, rl.Yellow)
+		}
+	}
+}
+
+// Renders the player's lives as a row of ship icons starting at `pos` and
+// going right, or left when `direction` is -1. The newest life blinks while
+// the extra life flash is active.
+func renderLives(player *Player, pos rl.Vector2, size float32, direction float32, thickness float32) {
+	for i := int32(0); i < int32(player.lives); i++ {
+		color := player.ship.Color
+		if player.lifeFlashTimer > 0 && i == int32(player.lives)-1 && int(player.lifeFlashTimer*8)%2 == 0 {
+			color = rl.Yellow
+		}
+
+		iconPos := rl.Vector2{X: pos.X + direction*size*(float32(i)+0.5)*0.9, Y: pos.Y}
+		entities.DrawShipIcon(iconPos, size, thickness, color)
+	}
+}
+
+// Returns the name of the player's selected weapon and the colour it is shown
+// in, which turns red while the weapon is overheated.
+func weaponStatus(player *Player) (string, rl.Color) {
+	if player.overheated[player.ship.Weapon] {
+		return player.ship.Weapon.String() + " - OVERHEATED", rl.Red
+	}
+	return player.ship.Weapon.String(), rl.Gray
+}
+
+// Renders the player's combo, shield charge and active power-ups with their
+// remaining time in a column starting at `x`, `y`.
+func renderPlayerStatus(state *GameState, player *Player, x int32, y int32, scale float32) {
+	smallFontSize := int32(HUD_FONT_SIZE*scale) * 2 / 3
+	margin := int32(16 * scale)
+
+	if player.combo.Hits > 0 {
+		tuning := comboTuning(state)
+		comboStr := fmt.Sprintf("Combo %d  x%d", player.combo.Hits, player.combo.Multiplier(tuning))
+		rl.DrawText(comboStr, x, y, smallFontSize, rl.Yellow)
+		y += smallFontSize + margin/4
+
+		// The bar shrinks as the combo window runs out.
+		barWidth := float32(rl.MeasureText(comboStr, smallFontSize)) * player.combo.Timer / tuning.Window
+		rl.DrawRectangleV(rl.Vector2{X: float32(x), Y: float32(y)}, rl.Vector2{X: barWidth, Y: 3 * scale}, rl.Yellow)
+		y += margin / 2
+	}
+	if player.ship.Shields > 0 {
+		rl.DrawText(fmt.Sprintf("Shield x%d", player.ship.Shields), x, y, smallFontSize, rl.Green)
+		y += smallFontSize + margin/4
+	}
+	for kind, timer := range player.effects {
+		if timer > 0 {
+			effectStr := fmt.Sprintf("%s %.1fs", entities.PowerUpKind(kind), timer)
+			rl.DrawText(effectStr, x, y, smallFontSize, rl.RayWhite)
+			y += smallFontSize + margin/4
+		}
+	}
+}
+
+// Renders a panel for every player side by side along the bottom of the
+// window, with their score, lives, weapon and status. Versus panels show the
+// kills and rounds won instead of the score. Downed players are told how to
+// get back in.
+func renderPlayerPanels(state *GameState, scale float32) {
+	width := int32(rl.GetScreenWidth())
+	height := int32(rl.GetScreenHeight())
+	fontSize := int32(HUD_FONT_SIZE*scale) * 2 / 3
+	margin := int32(16 * scale)
+	panelWidth := (width - margin) / int32(len(state.players))
+	_, hasClock := modeClock(state)
+
+	for i := range state.players {
+		player := &state.players[i]
+		x := margin + int32(i)*panelWidth
+		y := height - int32(PLAYER_PANEL_HEIGHT*scale)
+
+		scoreStr := fmt.Sprintf("%s  %d", player.Name(), player.Score)
+		if state.mode == Versus {
+			scoreStr = fmt.Sprintf("%s  %d kills  %d wins", player.Name(), player.kills, player.wins)
+		}
+		rl.DrawText(scoreStr, x, y, fontSize, player.ship.Color)
+		y += fontSize + margin/4
+
+		// Modes with a clock don't show lives, as on the single player HUD,
+		// and neither do versus rounds played for kills.
+		weaponX := x
+		if !hasClock && !(state.mode == Versus && isPlayingForKills()) {
+			iconSize := float32(fontSize)
+			renderLives(player, rl.Vector2{X: float32(x), Y: float32(y) + iconSize/2}, iconSize, 1, scale)
+			weaponX += int32(iconSize*0.9*float32(player.lives)) + margin/2
+		}
+		weaponStr, weaponColor := weaponStatus(player)
+		rl.DrawText(weaponStr, weaponX, y, fontSize, weaponColor)
+		y += fontSize + margin/4
+
+		switch {
+		case player.IsDown() && canRevive(state):
+			rl.DrawText("DOWN - fly close to revive", x, y, fontSize, rl.Red)
+			y += fontSize + margin/4
+		case player.IsDown():
+			rl.DrawText("OUT", x, y, fontSize, rl.Red)
+			y += fontSize + margin/4
+		case player.ship.IsDead():
+			rl.DrawText(fmt.Sprintf("Respawning in %.0f", player.ship.DeathTimer), x, y, fontSize, rl.RayWhite)
+			y += fontSize + margin/4
+		}
+
+		renderPlayerStatus(state, player, x, y, scale)
+	}
+}
@@ -0,0 +1,529 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/entities"
+	"asteroids/internal/input"
+	"asteroids/internal/network"
+	"asteroids/internal/utils"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"net"
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// Ticks that the local controls are held back before they are applied,
+	// giving them time to reach the peer so that fewer ticks are rolled back.
+	INPUT_DELAY = 2
+
+	// Most ticks that the game runs ahead of the peer's newest controls
+	// before it waits for them.
+	MAX_PREDICTION = 8
+
+	// Ticks of saved games and controls kept for rolling back. It must cover
+	// the prediction and input delay of both peers.
+	ROLLBACK_WINDOW = 32
+
+	// Ticks of checksums sent with every message. The checksum of every
+	// tick is compared with the peer, and repeating the newest ones means
+	// that a lost message doesn't leave its tick unchecked.
+	CHECKSUM_WINDOW = 32
+
+	// Seconds without hearing from the peer before the connection is
+	// considered lost.
+	PEER_TIMEOUT = 5.0
+
+	// Tick that nothing needs to be rolled back to.
+	NO_ROLLBACK = math.MaxUint32
+)
+
+// A copy of the game along with the global state that the simulation uses,
+// which is enough to rewind the game to an earlier tick.
+type SavedState struct {
+	state          GameState
+	rand           []byte
+	nextAsteroidID uint32
+}
+
+// Returns a copy of the game that shares nothing that the simulation changes
+// with the original. Asteroid shapes are shared as they never change.
+func cloneState(state *GameState) GameState {
+	clone := *state
+	clone.players = slices.Clone(state.players)
+	clone.asteroids = slices.Clone(state.asteroids)
+	clone.bullets = slices.Clone(state.bullets)
+	clone.powerUps = slices.Clone(state.powerUps)
+	clone.explosions = slices.Clone(state.explosions)
+	clone.hazards = slices.Clone(state.hazards)
+	clone.popups = slices.Clone(state.popups)
+	clone.boss.Segments = slices.Clone(state.boss.Segments)
+	clone.boss.WeakPoints = slices.Clone(state.boss.WeakPoints)
+	return clone
+}
+
+func saveState(state *GameState) SavedState {
+	return SavedState{
+		state:          cloneState(state),
+		rand:           utils.SaveRand(),
+		nextAsteroidID: entities.NextAsteroidID(),
+	}
+}
+
+// Puts the game and the global state back as they were when saved. The saved
+// state is copied so that it can be restored again.
+func restoreState(saved SavedState) GameState {
+	utils.RestoreRand(saved.rand)
+	entities.SetNextAsteroidID(saved.nextAsteroidID)
+	return cloneState(&saved.state)
+}
+
+// Returns a checksum of a saved game, which two peers compare to find out
+// whether their games have drifted apart. Only what the rules depend on is
+// included, as any difference soon shows up in the ships and asteroids.
+func checksum(saved SavedState) uint32 {
+	hash := fnv.New32a()
+	write := func(values ...any) {
+		for _, value := range values {
+			binary.Write(hash, binary.LittleEndian, value)
+		}
+	}
+
+	state := &saved.state
+	write(saved.rand, saved.nextAsteroidID, state.isGameOver, state.asteroidTimer)
+	write(int64(state.match.Round), int64(state.match.RoundWinner), int64(state.match.MatchWinner), state.match.breakTimer)
+	for _, player := range state.players {
+		ship := player.ship
+		write(ship.Pos, ship.Vel, ship.Rot, ship.DeathTimer, int64(ship.Shields), int64(ship.Weapon))
+		write(player.lives, player.Score, int64(player.kills), int64(player.wins), player.heat)
+	}
+	for _, asteroid := range state.asteroids {
+		write(asteroid.ID, asteroid.Pos, int64(asteroid.Health))
+	}
+	for _, bullet := range state.bullets {
+		write(bullet.Start, bullet.Dir, int64(bullet.Owner))
+	}
+	for _, powerUp := range state.powerUps {
+		write(powerUp.Pos, int64(powerUp.Kind))
+	}
+	return hash.Sum32()
+}
+
+// Returns a checksum of the tuning as loaded. Peers must play with the same
+// tuning, or their games would drift apart straight away.
+func tuningChecksum() uint64 {
+	data, err := json.Marshal(config.Base())
+	if err != nil {
+		panic("unreachable: tuning can't be encoded: " + err.Error())
+	}
+	hash := fnv.New64a()
+	hash.Write(data)
+	return hash.Sum64()
+}
+
+// A two player versus match played peer to peer. Both peers run the whole
+// game and send each other only their controls. Rather than waiting for the
+// peer's controls, the game goes ahead with a guess at them. When the real
+// controls arrive and differ from the guess, the game is rewound to the tick
+// they were for and simulated again up to the present.
+type RollbackSession struct {
+	conn        *network.Conn
+	peer        *net.UDPAddr // Address of the peer, or nil while the host waits for one.
+	isHost      bool
+	localPlayer int // Index of the local ship: 0 for the host and 1 for the guest.
+	seed        uint64
+	started     bool
+
+	State GameState
+	tick  uint32 // Next tick to simulate.
+
+	// Everything kept for a tick is stored at the tick modulo the window.
+	saved          [ROLLBACK_WINDOW]SavedState     // Game before each tick was simulated.
+	localControls  [ROLLBACK_WINDOW]input.Controls // Local controls of each tick.
+	remoteControls [ROLLBACK_WINDOW]input.Controls // Peer's controls of each tick, as received.
+	predicted      [ROLLBACK_WINDOW]input.Controls // Peer's controls that each tick was simulated with.
+
+	localTicks  uint32 // Ticks of local controls sampled, including the input delay.
+	remoteTicks uint32 // Ticks of the peer's controls received without gaps.
+	remoteAck   uint32 // Ticks of local controls that the peer has received.
+	rollbackTo  uint32 // Earliest tick that was simulated with a wrong guess.
+	Rollbacks   int    // Times that the game has been rewound.
+
+	checkedTick     uint32            // Newest tick whose checksum has been taken.
+	checksums       map[uint32]uint32 // Local checksums of the game at the start of each of the newest checked ticks.
+	peerCheckedTick uint32            // Newest tick whose checksum the peer has sent.
+	peerChecksums   map[uint32]uint32
+	comparedTick    uint32 // Newest tick whose checksums have been compared or can no longer be.
+	DesyncTick      uint32 // First tick whose checksums differ, or 0 while the games agree.
+	VerifiedTick    uint32 // Newest tick whose checksums matched the peer's.
+
+	helloTimer  float32
+	silentTimer float32 // Seconds since the peer was last heard from.
+	rejected    string  // Why the host wouldn't let the guest join.
+	peerLeft    bool
+}
+
+// Listens on `address`, e.g. ":7778", for a peer to play a match against.
+func HostRollback(address string, conditions network.Conditions) (*RollbackSession, error) {
+	conn, err := network.Listen(address, conditions)
+	if err != nil {
+		return nil, err
+	}
+	return newRollbackSession(conn, nil, true), nil
+}
+
+// Joins a match hosted by the peer at `address`, e.g. "localhost:7778".
+func JoinRollback(address string, conditions network.Conditions) (*RollbackSession, error) {
+	peer, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := network.Listen(":0", conditions)
+	if err != nil {
+		return nil, err
+	}
+	return newRollbackSession(conn, peer, false), nil
+}
+
+func newRollbackSession(conn *network.Conn, peer *net.UDPAddr, isHost bool) *RollbackSession {
+	localPlayer := 1
+	if isHost {
+		localPlayer = 0
+	}
+
+	return &RollbackSession{
+		conn:          conn,
+		peer:          peer,
+		isHost:        isHost,
+		localPlayer:   localPlayer,
+		State:         NewGameState(Versus, 2),
+		rollbackTo:    NO_ROLLBACK,
+		checksums:     map[uint32]uint32{},
+		peerChecksums: map[uint32]uint32{},
+	}
+}
+
+// Tells the peer that the match is being left and closes the socket.
+func (session *RollbackSession) Close() {
+	if session.peer != nil {
+		session.conn.Send(session.peer, network.Message{Kind: network.Bye})
+	}
+	session.conn.Close()
+}
+
+// Starts the match from the seed that the host picked, so that both peers
+// start from the same game.
+func (session *RollbackSession) start(seed uint64) {
+	session.seed = seed
+	session.started = true
+	utils.Seed(seed)
+	entities.SetNextAsteroidID(1)
+	session.State = NewGameState(Versus, 2)
+
+	// The first ticks have no controls, as the local controls are delayed.
+	session.localTicks = INPUT_DELAY
+}
+
+// Returns the ship flown by the local player.
+func (session *RollbackSession) LocalShip() entities.Ship {
+	return session.State.players[session.localPlayer].ship
+}
+
+// Returns true while the game can go on. It stops for good when the peer is
+// gone or the games have drifted apart.
+func (session *RollbackSession) isRunning() bool {
+	return session.started && session.DesyncTick == 0 && !session.peerLeft && session.silentTimer <= PEER_TIMEOUT
+}
+
+// Advances the session by one tick with the local controls: handles the
+// messages from the peer, rolls back if any guess was wrong, then simulates
+// the next tick unless the game is too far ahead of the peer. The local
+// controls are sent every tick, whether or not the game moved on.
+func (session *RollbackSession) Update(controls input.Controls) {
+	session.handlePackets()
+	session.silentTimer += TICK
+
+	if !session.started {
+		session.helloTimer -= TICK
+		if !session.isHost && session.helloTimer <= 0 && session.rejected == "" {
+			session.conn.Send(session.peer, network.Message{
+				Kind:    network.Hello,
+				Version: network.VERSION,
+				Tuning:  tuningChecksum(),
+			})
+			session.helloTimer = HELLO_INTERVAL
+		}
+		return
+	}
+	if !session.isRunning() {
+		return
+	}
+
+	session.step(controls)
+	session.conn.Send(session.peer, session.controlsMessage())
+	session.takeChecksums()
+}
+
+// Rolls back if any guess at the peer's controls was wrong, then simulates
+// the next tick with the local controls unless the game is too far ahead of
+// the peer.
+func (session *RollbackSession) step(controls input.Controls) {
+	if session.rollbackTo < session.tick {
+		session.rollback(session.rollbackTo)
+	}
+	session.rollbackTo = NO_ROLLBACK
+
+	if session.tick < session.remoteTicks+MAX_PREDICTION {
+		session.localControls[session.localTicks%ROLLBACK_WINDOW] = controls
+		session.localTicks++
+		session.advance()
+	}
+}
+
+// Handles the messages received since the last tick.
+func (session *RollbackSession) handlePackets() {
+	for _, packet := range session.conn.Poll() {
+		message := packet.Message
+		if session.isHost && message.Kind == network.Hello {
+			session.welcome(packet.From, message)
+			continue
+		}
+		if session.peer == nil || packet.From.String() != session.peer.String() {
+			continue
+		}
+		session.silentTimer = 0
+
+		switch message.Kind {
+		case network.Welcome:
+			if !session.started {
+				config.SetDifficulty(config.Difficulty(message.Difficulty))
+				session.start(message.Seed)
+			}
+		case network.Rejected:
+			session.rejected = message.Reason
+		case network.Inputs:
+			if session.started {
+				session.receiveControls(message)
+			}
+		case network.Bye:
+			session.peerLeft = true
+		}
+	}
+}
+
+// Lets the first peer with a matching version and tuning into the match, and
+// starts it. Repeated hellos are welcomed again in case the welcome was lost.
+func (session *RollbackSession) welcome(addr *net.UDPAddr, hello network.Message) {
+	reject := func(reason string) {
+		session.conn.Send(addr, network.Message{Kind: network.Rejected, Reason: reason})
+	}
+
+	switch {
+	case hello.Version != network.VERSION:
+		reject(fmt.Sprintf("host is running version %d", network.VERSION))
+		return
+	case hello.Tuning != tuningChecksum():
+		reject("tuning differs from the host's")
+		return
+	case session.peer != nil && addr.String() != session.peer.String():
+		reject("match is full")
+		return
+	}
+
+	if !session.started {
+		session.peer = addr
+		session.start(rand.Uint64())
+	}
+	session.silentTimer = 0
+	session.conn.Send(addr, network.Message{
+		Kind:       network.Welcome,
+		Player:     1,
+		Mode:       int(Versus),
+		Difficulty: int(config.CurrentDifficulty()),
+		Seed:       session.seed,
+	})
+}
+
+// Stores the peer's controls which follow on from those already received.
+// Any that differ from the guess that a simulated tick was made with mark the
+// game to be rolled back to that tick. The peer's checksums are compared with
+// the local ones as they arrive.
+func (session *RollbackSession) receiveControls(message network.Message) {
+	session.remoteAck = max(session.remoteAck, message.Ack)
+
+	first := message.Tick - uint32(len(message.Controls)) + 1
+	for i, controls := range message.Controls {
+		tick := first + uint32(i)
+		if tick != session.remoteTicks {
+			continue
+		}
+
+		session.remoteControls[tick%ROLLBACK_WINDOW] = controls
+		session.remoteTicks++
+		if tick < session.tick && controls != session.predicted[tick%ROLLBACK_WINDOW] {
+			session.rollbackTo = min(session.rollbackTo, tick)
+		}
+	}
+
+	first = message.ChecksumTick - uint32(len(message.Checksums)) + 1
+	for i, sum := range message.Checksums {
+		if tick := first + uint32(i); tick > session.comparedTick {
+			session.peerChecksums[tick] = sum
+		}
+	}
+	session.peerCheckedTick = max(session.peerCheckedTick, message.ChecksumTick)
+	session.compareChecksums()
+}
+
+// Returns the peer's controls for a tick, or a guess at them if they haven't
+// arrived yet. The guess is that the peer is still holding their last
+// controls, as they usually are.
+func (session *RollbackSession) remoteControlsFor(tick uint32) input.Controls {
+	if tick < session.remoteTicks {
+		return session.remoteControls[tick%ROLLBACK_WINDOW]
+	}
+	if session.remoteTicks == 0 {
+		return input.Controls{}
+	}
+	return session.remoteControls[(session.remoteTicks-1)%ROLLBACK_WINDOW].Held()
+}
+
+// Saves the game and simulates the next tick.
+func (session *RollbackSession) advance() {
+	tick := session.tick
+	session.saved[tick%ROLLBACK_WINDOW] = saveState(&session.State)
+
+	remote := session.remoteControlsFor(tick)
+	session.predicted[tick%ROLLBACK_WINDOW] = remote
+
+	controls := make([]input.Controls, 2)
+	controls[session.localPlayer] = session.localControls[tick%ROLLBACK_WINDOW]
+	controls[1-session.localPlayer] = remote
+	update(&session.State, controls)
+	session.tick++
+}
+
+// Rewinds the game to the start of `tick` and simulates it again up to the
+// present with the controls known now. The feedback of the simulated ticks
+// has already been felt, so it isn't sent again.
+func (session *RollbackSession) rollback(tick uint32) {
+	wasMuted := input.MuteFeedback(true)
+	defer input.MuteFeedback(wasMuted)
+
+	present := session.tick
+	session.State = restoreState(session.saved[tick%ROLLBACK_WINDOW])
+	session.tick = tick
+	for session.tick < present {
+		session.advance()
+	}
+	session.Rollbacks++
+}
+
+// Returns a message with the local controls that the peer hasn't received
+// yet, along with the newest checksums.
+func (session *RollbackSession) controlsMessage() network.Message {
+	first := max(session.remoteAck, session.localTicks-min(session.localTicks, ROLLBACK_WINDOW))
+	controls := []input.Controls{}
+	for tick := first; tick < session.localTicks; tick++ {
+		controls = append(controls, session.localControls[tick%ROLLBACK_WINDOW])
+	}
+
+	checksums := []uint32{}
+	for tick := session.checkedTick - min(session.checkedTick, CHECKSUM_WINDOW) + 1; tick <= session.checkedTick; tick++ {
+		checksums = append(checksums, session.checksums[tick])
+	}
+
+	return network.Message{
+		Kind:         network.Inputs,
+		Tick:         session.localTicks - 1,
+		Ack:          session.remoteTicks,
+		Controls:     controls,
+		ChecksumTick: session.checkedTick,
+		Checksums:    checksums,
+	}
+}
+
+// Takes the checksums of the ticks which can no longer be rolled back, as
+// the controls of every tick before them are known. Only the newest ones are
+// kept, as they are all that is sent to the peer.
+func (session *RollbackSession) takeChecksums() {
+	confirmed := min(session.remoteTicks, session.tick)
+	for tick := session.checkedTick + 1; tick <= confirmed; tick++ {
+		saved := session.saved[tick%ROLLBACK_WINDOW]
+		if tick == session.tick {
+			saved = saveState(&session.State)
+		}
+
+		session.checksums[tick] = checksum(saved)
+		session.checkedTick = tick
+		if tick > CHECKSUM_WINDOW {
+			delete(session.checksums, tick-CHECKSUM_WINDOW)
+		}
+	}
+	session.compareChecksums()
+}
+
+// Compares the local and peer checksums of each tick in order, stopping at the
+// first tick that differs. A tick is skipped once either checksum is gone for
+// good, which only happens when many messages in a row are lost.
+func (session *RollbackSession) compareChecksums() {
+	for session.DesyncTick == 0 {
+		tick := session.comparedTick + 1
+		local, hasLocal := session.checksums[tick]
+		peer, hasPeer := session.peerChecksums[tick]
+		switch {
+		case hasLocal && hasPeer && local != peer:
+			session.DesyncTick = tick
+			return
+		case hasLocal && hasPeer:
+			session.VerifiedTick = tick
+		case !hasLocal && tick > session.checkedTick, !hasPeer && tick+CHECKSUM_WINDOW > session.peerCheckedTick:
+			// The missing checksum can still be taken or sent.
+			return
+		}
+
+		delete(session.peerChecksums, tick)
+		session.comparedTick = tick
+	}
+}
+
+// Returns the state of the match to show at the bottom of the screen, or ""
+// while it is being played.
+func (session *RollbackSession) status() string {
+	switch {
+	case session.rejected != "":
+		return "Could not join: " + session.rejected
+	case session.DesyncTick > 0:
+		return fmt.Sprintf("Desync detected at tick %d", session.DesyncTick)
+	case session.peerLeft:
+		return "Your opponent left the match"
+	case !session.started && session.isHost:
+		return "Waiting for an opponent to join on " + session.conn.LocalAddr().String()
+	case !session.started:
+		return "Connecting to " + session.peer.String() + "..."
+	case session.silentTimer > PEER_TIMEOUT:
+		return "Lost connection to " + session.peer.String()
+	case session.tick >= session.remoteTicks+MAX_PREDICTION:
+		return "Waiting for your opponent..."
+	}
+	return ""
+}
+
+// Renders the state of the match at the bottom of the screen.
+func drawRollbackBanner(session *RollbackSession, scale float32) {
+	banner := session.status()
+	if banner == "" {
+		return
+	}
+
+	fontSize := int32(20 * scale)
+	width := int32(rl.GetScreenWidth())
+	y := int32(rl.GetScreenHeight()) - fontSize - int32(16*scale)
+	rl.DrawText(banner, width/2-rl.MeasureText(banner, fontSize)/2, y, fontSize, rl.Gray)
+}
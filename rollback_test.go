@@ -0,0 +1,169 @@
+package main
+
+import (
+	"asteroids/internal/constants"
+	"asteroids/internal/entities"
+	"asteroids/internal/input"
+	"asteroids/internal/network"
+	"asteroids/internal/utils"
+	"math/rand/v2"
+	"testing"
+)
+
+// Ticks simulated by the determinism tests.
+const TEST_TICKS = 20 * constants.TICK_RATE
+
+// Returns random controls for both ships on every tick, changing every so
+// often like the rollback bots' do.
+func testControls(seed uint64) [][]input.Controls {
+	randoms := []*rand.Rand{rand.New(rand.NewPCG(seed, 1)), rand.New(rand.NewPCG(seed, 2))}
+	ticks := make([][]input.Controls, TEST_TICKS)
+	for player, random := range randoms {
+		controls := input.Controls{}
+		holdTimer := 0
+		for tick := range ticks {
+			holdTimer--
+			if holdTimer <= 0 {
+				controls = randomControls(random)
+				holdTimer = random.IntN(constants.TICK_RATE / 2)
+			} else {
+				controls = controls.Held()
+			}
+			if player == 0 {
+				ticks[tick] = make([]input.Controls, len(randoms))
+			}
+			ticks[tick][player] = controls
+		}
+	}
+	return ticks
+}
+
+// Plays a versus match from a seed with the given controls and returns the
+// checksum of the game at the start of every tick.
+func simulate(seed uint64, controls [][]input.Controls) []uint32 {
+	utils.Seed(seed)
+	entities.SetNextAsteroidID(1)
+	state := NewGameState(Versus, 2)
+
+	checksums := []uint32{}
+	for _, tickControls := range controls {
+		checksums = append(checksums, checksum(saveState(&state)))
+		update(&state, tickControls)
+	}
+	return checksums
+}
+
+func TestSimulationIsDeterministic(t *testing.T) {
+	newTestState(t, 2)
+	controls := testControls(1)
+
+	first := simulate(7, controls)
+	second := simulate(7, controls)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("checksums differ at tick %d: %08x and %08x", i, first[i], second[i])
+		}
+	}
+
+	if other := simulate(7, testControls(2)); other[len(other)-1] == first[len(first)-1] {
+		t.Error("different controls gave the same game")
+	}
+}
+
+// One side of a rollback match played in the same process. The random
+// generator and asteroid IDs are global, so each peer keeps its own and puts
+// them in place while it runs.
+type testPeer struct {
+	session        *RollbackSession
+	controls       int // Index of the player whose controls are used.
+	rand           []byte
+	nextAsteroidID uint32
+	inbox          []delayedMessage
+}
+
+// A message from the other peer which arrives on `tick`.
+type delayedMessage struct {
+	tick    int
+	message network.Message
+}
+
+func (peer *testPeer) run(f func()) {
+	utils.RestoreRand(peer.rand)
+	entities.SetNextAsteroidID(peer.nextAsteroidID)
+	f()
+	peer.rand = utils.SaveRand()
+	peer.nextAsteroidID = entities.NextAsteroidID()
+}
+
+func TestRollbackPeersStayInSync(t *testing.T) {
+	newTestState(t, 2)
+	controls := testControls(3)
+
+	// The messages take a few ticks to arrive, so both peers have to guess
+	// at each other's controls and roll back when they guessed wrong.
+	const delay = 4
+	peers := []*testPeer{
+		{session: newRollbackSession(nil, nil, true), controls: 0},
+		{session: newRollbackSession(nil, nil, false), controls: 1},
+	}
+	for _, peer := range peers {
+		peer.rand = utils.SaveRand()
+		peer.run(func() { peer.session.start(11) })
+	}
+
+	for tick := range controls {
+		for i, peer := range peers {
+			other := peers[1-i]
+			peer.run(func() {
+				for len(peer.inbox) > 0 && peer.inbox[0].tick <= tick {
+					peer.session.receiveControls(peer.inbox[0].message)
+					peer.inbox = peer.inbox[1:]
+				}
+				peer.session.step(controls[tick][peer.controls])
+				other.inbox = append(other.inbox, delayedMessage{tick + delay, peer.session.controlsMessage()})
+				peer.session.takeChecksums()
+			})
+		}
+	}
+
+	for i, peer := range peers {
+		session := peer.session
+		if session.DesyncTick > 0 {
+			t.Errorf("peer %d desynced at tick %d", i, session.DesyncTick)
+		}
+		if session.VerifiedTick < TEST_TICKS-2*delay-INPUT_DELAY {
+			t.Errorf("peer %d verified up to tick %d, want close to %d", i, session.VerifiedTick, TEST_TICKS)
+		}
+		if session.Rollbacks == 0 {
+			t.Errorf("peer %d never rolled back", i)
+		}
+	}
+	if !input.MuteFeedback(true) {
+		t.Error("rolling back unmuted the feedback")
+	}
+}
+
+func TestDesyncNamesTheTickThatDiffers(t *testing.T) {
+	session := newRollbackSession(nil, nil, true)
+	peerChecksums := []uint32{}
+	for tick := uint32(1); tick <= 40; tick++ {
+		session.checksums[tick] = tick
+		peerChecksums = append(peerChecksums, tick)
+	}
+	session.checkedTick = 40
+	peerChecksums[37-1]++
+
+	// The peer's oldest checksums have dropped out of its window, but the
+	// ticks before the desync still match.
+	session.receiveControls(network.Message{
+		Kind:         network.Inputs,
+		ChecksumTick: 40,
+		Checksums:    peerChecksums[40-CHECKSUM_WINDOW:],
+	})
+	if session.DesyncTick != 37 {
+		t.Errorf("desync tick = %d, want 37", session.DesyncTick)
+	}
+	if session.VerifiedTick != 36 {
+		t.Errorf("verified tick = %d, want 36", session.VerifiedTick)
+	}
+}
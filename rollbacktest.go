@@ -0,0 +1,170 @@
+package main
+
+import (
+	"asteroids/internal/constants"
+	"asteroids/internal/input"
+	"asteroids/internal/network"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Seconds that a bot keeps playing after its last checksum was verified, so
+// that its peer can verify it too.
+const BOT_LINGER = 1.0
+
+// Plays a rollback match between two bots in separate processes over
+// localhost with simulated network conditions, and fails if their games
+// drift apart. Both bots report the checksum of the game at the final tick,
+// which must match on top of the checks made during the match.
+func runRollbackTest(args []string) error {
+	flags := flag.NewFlagSet("rollback-test", flag.ExitOnError)
+	port := flags.Int("port", 7778, "UDP port that the host bot listens on")
+	ticks := flags.Int("ticks", 10*constants.TICK_RATE, "ticks to play before the checksums are compared")
+	seed := flags.Uint64("seed", 1, "seed of the bots' controls")
+	conditions := network.Conditions{Latency: 60 * time.Millisecond, Jitter: 30 * time.Millisecond, Loss: 0.05}
+	conditions.AddFlags(flags)
+	flags.Parse(args)
+
+	if err := conditions.Validate(); err != nil {
+		return err
+	}
+
+	common := append([]string{"--ticks", fmt.Sprint(*ticks)}, conditions.Args()...)
+	bots := []struct {
+		name string
+		args []string
+	}{
+		{"host", append([]string{"--host", fmt.Sprintf(":%d", *port), "--seed", fmt.Sprint(*seed)}, common...)},
+		{"guest", append([]string{"--join", fmt.Sprintf("127.0.0.1:%d", *port), "--seed", fmt.Sprint(*seed + 1)}, common...)},
+	}
+
+	commands := []*exec.Cmd{}
+	outputs := []*bytes.Buffer{}
+	for _, bot := range bots {
+		output := &bytes.Buffer{}
+		command := exec.Command(os.Args[0], append([]string{"rollback-bot"}, bot.args...)...)
+		command.Stdout = output
+		command.Stderr = os.Stderr
+		if err := command.Start(); err != nil {
+			return err
+		}
+		commands = append(commands, command)
+		outputs = append(outputs, output)
+	}
+
+	errs := []error{}
+	results := []string{}
+	for i, command := range commands {
+		if err := command.Wait(); err != nil {
+			errs = append(errs, fmt.Errorf("%s bot failed: %w", bots[i].name, err))
+		}
+		result := strings.TrimSpace(outputs[i].String())
+		results = append(results, result)
+		fmt.Printf("%s: %s\n", bots[i].name, result)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	hostChecksum, _, _ := strings.Cut(results[0], " ")
+	guestChecksum, _, _ := strings.Cut(results[1], " ")
+	if hostChecksum != guestChecksum {
+		return fmt.Errorf("final checksums differ: %s and %s", hostChecksum, guestChecksum)
+	}
+	fmt.Println("games stayed in sync")
+	return nil
+}
+
+// Plays one side of a rollback test without a window, with random controls
+// which change every so often. Prints the checksum of the game at the final
+// tick along with how many times it was rolled back.
+func runRollbackBot(args []string) error {
+	flags := flag.NewFlagSet("rollback-bot", flag.ExitOnError)
+	host := flags.String("host", "", "address to host the match on")
+	join := flags.String("join", "", "address of the host to join")
+	ticks := flags.Int("ticks", 10*constants.TICK_RATE, "ticks to play")
+	seed := flags.Uint64("seed", 1, "seed of the bot's controls")
+	conditions := network.Conditions{}
+	conditions.AddFlags(flags)
+	flags.Parse(args)
+
+	var session *RollbackSession
+	var err error
+	if *join != "" {
+		session, err = JoinRollback(*join, conditions)
+	} else {
+		session, err = HostRollback(*host, conditions)
+	}
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	final := uint32(*ticks)
+	// Allow for the peer starting late and for every tick having to wait.
+	timeout := time.After(time.Duration(4**ticks/constants.TICK_RATE+10) * time.Second)
+
+	random := rand.New(rand.NewPCG(*seed, *seed))
+	controls := input.Controls{}
+	holdTimer := 0
+	finalChecksum := uint32(0)
+	linger := float32(0)
+
+	ticker := time.NewTicker(time.Second / constants.TICK_RATE)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return fmt.Errorf("timed out on tick %d", session.tick)
+		}
+
+		holdTimer--
+		if holdTimer <= 0 {
+			controls = randomControls(random)
+			holdTimer = random.IntN(constants.TICK_RATE / 2)
+		} else {
+			controls = controls.Held()
+		}
+		session.Update(controls)
+
+		if session.DesyncTick > 0 {
+			return fmt.Errorf("desync at tick %d", session.DesyncTick)
+		}
+		if local, ok := session.checksums[final]; ok {
+			finalChecksum = local
+		}
+
+		// The peer may finish first and leave once the final tick has been
+		// verified.
+		if session.VerifiedTick >= final {
+			linger += TICK
+			if linger >= BOT_LINGER || !session.isRunning() {
+				break
+			}
+		} else if session.started && !session.isRunning() {
+			return errors.New(session.status())
+		}
+	}
+
+	fmt.Printf("%08x after %d ticks, %d rollbacks\n", finalChecksum, final, session.Rollbacks)
+	return nil
+}
+
+// Returns controls like a player mashing the keyboard would send.
+func randomControls(random *rand.Rand) input.Controls {
+	return input.Controls{
+		Rotate:     float32(random.IntN(3) - 1),
+		Thrust:     float32(random.IntN(2)),
+		Fire:       random.IntN(2) == 0,
+		Hyperspace: random.IntN(20) == 0,
+		NextWeapon: random.IntN(10) == 0,
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/input"
+	"asteroids/internal/network"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	SERVER_PORT = 7777
+
+	// Ticks between the snapshots sent to clients, 20 times a second.
+	SNAPSHOT_INTERVAL = 6
+
+	// Clients which haven't been heard from for this long are dropped.
+	CLIENT_TIMEOUT = 5 * time.Second
+
+	// Most controls queued for a client. A client which runs ahead of the
+	// server has its oldest controls dropped, so that its input lag doesn't
+	// keep growing.
+	MAX_QUEUED_CONTROLS = 12
+
+	// Seconds that the game over screen is shown for before the server starts
+	// a new game.
+	SERVER_RESTART_DELAY = 5.0
+)
+
+// Controls from a client which are waiting to be applied, along with the
+// client tick they were sampled on.
+type queuedControls struct {
+	tick     uint32
+	controls input.Controls
+}
+
+// A client playing on the server.
+type remoteClient struct {
+	addr     *net.UDPAddr
+	queue    []queuedControls
+	received uint32         // Newest client tick received.
+	applied  uint32         // Newest client tick applied to the game.
+	last     input.Controls // Controls applied on the last tick.
+	lastSeen time.Time
+}
+
+// Returns the client's controls for the next tick. If they haven't arrived
+// yet the last controls are held, apart from one-off presses.
+func (client *remoteClient) next() input.Controls {
+	if len(client.queue) == 0 {
+		return client.last.Held()
+	}
+
+	next := client.queue[0]
+	client.queue = client.queue[1:]
+	client.applied = next.tick
+	client.last = next.controls
+	return next.controls
+}
+
+// Queues the controls of an inputs message which haven't been received yet.
+func (client *remoteClient) receive(message network.Message) {
+	first := message.Tick - uint32(len(message.Controls)) + 1
+	for i, controls := range message.Controls {
+		tick := first + uint32(i)
+		if tick > client.received {
+			client.queue = append(client.queue, queuedControls{tick: tick, controls: controls})
+			client.received = tick
+		}
+	}
+
+	if len(client.queue) > MAX_QUEUED_CONTROLS {
+		client.queue = client.queue[len(client.queue)-MAX_QUEUED_CONTROLS:]
+	}
+}
+
+// An authoritative server which runs the game and sends snapshots of it to
+// every client. Each client flies the ship of the player slot it was given,
+// and the game only runs once every slot has a client.
+type Server struct {
+	conn       *network.Conn
+	clients    []*remoteClient // Client in each player slot, or nil if the slot is free.
+	spectators *Broadcaster
+	state      GameState
+	tick       uint32
+	overTimer  float32 // Seconds since the game ended.
+	mode       GameMode
+	numPlayers int
+}
+
+// Returns the number of player slots without a client.
+func (server *Server) waiting() int {
+	waiting := 0
+	for _, client := range server.clients {
+		if client == nil {
+			waiting++
+		}
+	}
+	return waiting
+}
+
+// Returns the slot of the client at `addr`, or -1 if it hasn't joined.
+func (server *Server) slotOf(addr *net.UDPAddr) int {
+	for i, client := range server.clients {
+		if client != nil && client.addr.String() == addr.String() {
+			return i
+		}
+	}
+	return -1
+}
+
+// Handles the packets received since the last tick. Hellos are welcomed into
+// the first free slot, and repeated hellos from a client which has already
+// joined are welcomed again in case the welcome was lost.
+func (server *Server) handlePackets() {
+	for _, packet := range server.conn.Poll() {
+		if server.spectators.handle(packet) {
+			continue
+		}
+
+		message := packet.Message
+		slot := server.slotOf(packet.From)
+		if slot >= 0 {
+			server.clients[slot].lastSeen = time.Now()
+		}
+
+		switch message.Kind {
+		case network.Hello:
+			server.welcome(packet.From, message, slot)
+		case network.Inputs:
+			if slot >= 0 {
+				server.clients[slot].receive(message)
+			}
+		case network.Bye:
+			if slot >= 0 {
+				log.Printf("player %d left", slot+1)
+				server.clients[slot] = nil
+			}
+		}
+	}
+}
+
+// Gives the client a player slot, or tells it why it can't join.
+func (server *Server) welcome(addr *net.UDPAddr, hello network.Message, slot int) {
+	reject := func(reason string) {
+		server.conn.Send(addr, network.Message{Kind: network.Rejected, Reason: reason})
+	}
+
+	if hello.Version != network.VERSION {
+		reject(fmt.Sprintf("server is running version %d", network.VERSION))
+		return
+	}
+	if slot < 0 {
+		for i, client := range server.clients {
+			if client == nil {
+				slot = i
+				server.clients[i] = &remoteClient{addr: addr, lastSeen: time.Now()}
+				log.Printf("player %d joined from %s", slot+1, addr)
+				break
+			}
+		}
+	}
+	if slot < 0 {
+		reject("server is full")
+		return
+	}
+
+	server.conn.Send(addr, network.Message{
+		Kind:       network.Welcome,
+		Player:     slot,
+		Mode:       int(server.mode),
+		Difficulty: int(config.CurrentDifficulty()),
+	})
+}
+
+// Frees the slots of clients which haven't been heard from in a while.
+func (server *Server) dropTimedOut() {
+	for i, client := range server.clients {
+		if client != nil && time.Since(client.lastSeen) > CLIENT_TIMEOUT {
+			log.Printf("player %d timed out", i+1)
+			server.clients[i] = nil
+		}
+	}
+}
+
+// Advances the server by one tick. Every client's controls are consumed each
+// tick even while the game is waiting for players or over, so that their
+// acknowledgements keep up. A new game starts a while after the last one
+// ended.
+func (server *Server) update() {
+	server.handlePackets()
+	server.dropTimedOut()
+	server.spectators.dropTimedOut()
+
+	controls := make([]input.Controls, server.numPlayers)
+	for i, client := range server.clients {
+		if client != nil {
+			controls[i] = client.next()
+		}
+	}
+
+	switch {
+	case server.state.isGameOver:
+		server.overTimer += TICK
+		if server.overTimer >= SERVER_RESTART_DELAY {
+			log.Printf("starting a new game")
+			server.state = newGame(server.mode, server.numPlayers, &DailyRun{})
+			server.overTimer = 0
+		}
+	case server.waiting() == 0:
+		update(&server.state, controls)
+		if server.state.isGameOver {
+			log.Printf("game over with %d points", totalScore(&server.state))
+		}
+	}
+
+	server.tick++
+	if server.tick%SNAPSHOT_INTERVAL == 0 {
+		server.broadcast()
+	}
+}
+
+// Sends a snapshot of the game to every client, along with the newest of
+// their controls that have been applied, and to every spectator.
+func (server *Server) broadcast() {
+	data, err := encodeSnapshotToFit(takeSnapshot(&server.state, server.tick))
+	if err != nil {
+		log.Printf("failed to encode snapshot: %v", err)
+		return
+	}
+
+	waiting := server.waiting()
+	for _, client := range server.clients {
+		if client == nil {
+			continue
+		}
+
+		err := server.conn.Send(client.addr, network.Message{
+			Kind:    network.Snapshot,
+			Tick:    server.tick,
+			Ack:     client.applied,
+			State:   data,
+			Waiting: waiting,
+		})
+		if err != nil {
+			log.Printf("failed to send snapshot: %v", err)
+		}
+	}
+	server.spectators.send(data, server.tick)
+}
+
+// Runs a dedicated server without a window until it is killed. The server
+// picks the mode, difficulty and number of players, and reloads the tuning
+// file whenever it changes.
+func runServer(args []string) error {
+	flags := flag.NewFlagSet("server", flag.ExitOnError)
+	port := flags.Int("port", SERVER_PORT, "UDP port to listen on")
+	modeName := flags.String("mode", Classic.Key(), "game mode to play: "+modeKeys())
+	numPlayers := flags.Int("players", 2, fmt.Sprintf("number of player slots, from 1 to %d", input.MAX_PLAYERS))
+	difficultyName := flags.String("difficulty", "normal", "difficulty preset: easy, normal, hard or arcade")
+	conditions := network.Conditions{}
+	conditions.AddFlags(flags)
+	flags.Parse(args)
+
+	mode, ok := ParseMode(*modeName)
+	if !ok {
+		return fmt.Errorf("unknown mode %q", *modeName)
+	}
+	// The daily challenge is played alone and the zen tools need the mouse
+	// of whoever is running the game.
+	if mode == Daily || mode == Zen {
+		return fmt.Errorf("%s can't be played online", strings.ToLower(mode.String()))
+	}
+	if *numPlayers < 1 || *numPlayers > input.MAX_PLAYERS {
+		return fmt.Errorf("players must be from 1 to %d", input.MAX_PLAYERS)
+	}
+	if mode == Versus {
+		*numPlayers = max(*numPlayers, 2)
+	}
+	difficulty, ok := config.ParseDifficulty(*difficultyName)
+	if !ok {
+		return fmt.Errorf("unknown difficulty %q", *difficultyName)
+	}
+	config.SetDifficulty(difficulty)
+	if err := conditions.Validate(); err != nil {
+		return err
+	}
+
+	watcher, err := config.NewWatcher(constants.TUNING_FILE)
+	if err != nil {
+		log.Printf("using default tuning: %v", err)
+	}
+
+	conn, err := network.Listen(fmt.Sprintf(":%d", *port), conditions)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	server := Server{
+		conn:       conn,
+		clients:    make([]*remoteClient, *numPlayers),
+		spectators: NewBroadcaster(conn),
+		state:      newGame(mode, *numPlayers, &DailyRun{}),
+		mode:       mode,
+		numPlayers: *numPlayers,
+	}
+	log.Printf("serving %s for %d players on %s", mode, *numPlayers, conn.LocalAddr())
+
+	ticker := time.NewTicker(time.Second / constants.TICK_RATE)
+	defer ticker.Stop()
+	for range ticker.C {
+		changes, err := watcher.Poll(TICK)
+		if err != nil {
+			log.Printf("rejected %s: %v", watcher.Path, err)
+		} else if len(changes) > 0 {
+			log.Printf("reloaded %s: %s", watcher.Path, strings.Join(changes, ", "))
+		}
+
+		server.update()
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"asteroids/internal/entities"
+	"asteroids/internal/network"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// Largest encoded snapshot sent to clients, leaving room in the packet for
+// the rest of the message.
+const MAX_SNAPSHOT_SIZE = network.MAX_PACKET_SIZE - 1024
+
+// Everything about a player that is drawn, as sent from the server.
+type PlayerSnapshot struct {
+	Ship           entities.Ship
+	Lives          uint8
+	Score          uint64
+	Charge         float32
+	Beam           entities.Beam
+	Heat           [entities.NUM_WEAPONS]float32
+	Overheated     [entities.NUM_WEAPONS]bool
+	Effects        [entities.NUM_POWERUP_KINDS]float32
+	Combo          Combo // Only the hits and timer are sent.
+	LifeFlashTimer float32
+	ReviveTimer    float32
+	Kills          int
+	Wins           int
+}
+
+// Everything about the game that clients need to draw it. The rules are only
+// run on the server, so nothing that only the rules use is sent.
+type Snapshot struct {
+	Tick uint32 // Server tick that the snapshot was taken on.
+
+	Mode          GameMode
+	IsGameOver    bool
+	HighScoreRank int
+	TimeLeft      float32
+	Elapsed       float32
+	StormTimer    float32
+	StormEdge     entities.Edge
+	Match         Match
+	RoundBreak    float32 // Seconds left of the scoreboard between versus rounds.
+
+	Players    []PlayerSnapshot
+	Asteroids  []entities.Asteroid
+	Bullets    []entities.Bullet
+	PowerUps   []entities.PowerUp
+	Explosions []entities.Explosion
+	Hazards    []entities.Hazard
+	Popups     []entities.Popup
+
+	Boss             entities.Boss
+	BossWarningTimer float32
+
+	// Whether entities were left out so that the snapshot fits into a
+	// packet.
+	Truncated bool
+}
+
+// Takes a snapshot of the game as it is on the given server tick.
+func takeSnapshot(state *GameState, tick uint32) Snapshot {
+	players := []PlayerSnapshot{}
+	for _, player := range state.players {
+		players = append(players, PlayerSnapshot{
+			Ship:           player.ship,
+			Lives:          player.lives,
+			Score:          player.Score,
+			Charge:         player.charge,
+			Beam:           player.beam,
+			Heat:           player.heat,
+			Overheated:     player.overheated,
+			Effects:        player.effects,
+			Combo:          player.combo,
+			LifeFlashTimer: player.lifeFlashTimer,
+			ReviveTimer:    player.reviveTimer,
+			Kills:          player.kills,
+			Wins:           player.wins,
+		})
+	}
+
+	return Snapshot{
+		Tick:             tick,
+		Mode:             state.mode,
+		IsGameOver:       state.isGameOver,
+		HighScoreRank:    state.highScoreRank,
+		TimeLeft:         state.timeLeft,
+		Elapsed:          state.elapsed,
+		StormTimer:       state.stormTimer,
+		StormEdge:        state.stormEdge,
+		Match:            state.match,
+		RoundBreak:       state.match.breakTimer,
+		Players:          players,
+		Asteroids:        state.asteroids,
+		Bullets:          state.bullets,
+		PowerUps:         state.powerUps,
+		Explosions:       state.explosions,
+		Hazards:          state.hazards,
+		Popups:           state.popups,
+		Boss:             state.boss,
+		BossWarningTimer: state.bossWarningTimer,
+	}
+}
+
+// Builds a game state to draw from a snapshot.
+func restoreSnapshot(snapshot Snapshot) GameState {
+	players := []Player{}
+	for i, player := range snapshot.Players {
+		players = append(players, Player{
+			index:          i,
+			ship:           player.Ship,
+			lives:          player.Lives,
+			Score:          player.Score,
+			charge:         player.Charge,
+			beam:           player.Beam,
+			heat:           player.Heat,
+			overheated:     player.Overheated,
+			effects:        player.Effects,
+			combo:          player.Combo,
+			lifeFlashTimer: player.LifeFlashTimer,
+			reviveTimer:    player.ReviveTimer,
+			kills:          player.Kills,
+			wins:           player.Wins,
+		})
+	}
+
+	state := GameState{
+		players:          players,
+		mode:             snapshot.Mode,
+		isGameOver:       snapshot.IsGameOver,
+		highScoreRank:    snapshot.HighScoreRank,
+		timeLeft:         snapshot.TimeLeft,
+		elapsed:          snapshot.Elapsed,
+		stormTimer:       snapshot.StormTimer,
+		stormEdge:        snapshot.StormEdge,
+		match:            snapshot.Match,
+		asteroids:        snapshot.Asteroids,
+		bullets:          snapshot.Bullets,
+		powerUps:         snapshot.PowerUps,
+		explosions:       snapshot.Explosions,
+		hazards:          snapshot.Hazards,
+		popups:           snapshot.Popups,
+		boss:             snapshot.Boss,
+		bossWarningTimer: snapshot.BossWarningTimer,
+	}
+	state.match.breakTimer = snapshot.RoundBreak
+	return state
+}
+
+func encodeSnapshot(snapshot Snapshot) ([]byte, error) {
+	buffer := bytes.Buffer{}
+	if err := gob.NewEncoder(&buffer).Encode(snapshot); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Encodes a snapshot small enough to fit into a packet. When there is too
+// much going on, the least important entities are left out until it fits:
+// popups first, then explosions, bullets, power-ups and finally asteroids.
+// The snapshot is marked as truncated so that clients can tell.
+func encodeSnapshotToFit(snapshot Snapshot) ([]byte, error) {
+	data, err := encodeSnapshot(snapshot)
+	for err == nil && len(data) > MAX_SNAPSHOT_SIZE {
+		if !trimSnapshot(&snapshot) {
+			return nil, fmt.Errorf("snapshot of %d bytes is too large even without any entities", len(data))
+		}
+		snapshot.Truncated = true
+		data, err = encodeSnapshot(snapshot)
+	}
+	return data, err
+}
+
+// Halves the least important list of entities which isn't empty yet. Returns
+// false if there is nothing left to leave out.
+func trimSnapshot(snapshot *Snapshot) bool {
+	switch {
+	case len(snapshot.Popups) > 0:
+		snapshot.Popups = snapshot.Popups[:len(snapshot.Popups)/2]
+	case len(snapshot.Explosions) > 0:
+		snapshot.Explosions = snapshot.Explosions[:len(snapshot.Explosions)/2]
+	case len(snapshot.Bullets) > 0:
+		snapshot.Bullets = snapshot.Bullets[:len(snapshot.Bullets)/2]
+	case len(snapshot.PowerUps) > 0:
+		snapshot.PowerUps = snapshot.PowerUps[:len(snapshot.PowerUps)/2]
+	case len(snapshot.Asteroids) > 0:
+		snapshot.Asteroids = snapshot.Asteroids[:len(snapshot.Asteroids)/2]
+	default:
+		return false
+	}
+	return true
+}
+
+func decodeSnapshot(data []byte) (Snapshot, error) {
+	snapshot := Snapshot{}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snapshot)
+	return snapshot, err
+}
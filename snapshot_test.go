@@ -0,0 +1,63 @@
+package main
+
+import (
+	"asteroids/internal/entities"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	state := newTestState(t, 2)
+	state.players[1].Score = 500
+	addAsteroid(&state, rl.Vector2{X: 100, Y: 100}, entities.Medium, entities.Rock)
+
+	data, err := encodeSnapshotToFit(takeSnapshot(&state, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := decodeSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snapshot.Tick != 42 || snapshot.Truncated {
+		t.Errorf("got tick %d and truncated %v, want tick 42 and everything sent", snapshot.Tick, snapshot.Truncated)
+	}
+	restored := restoreSnapshot(snapshot)
+	if len(restored.asteroids) != len(state.asteroids) || restored.players[1].Score != 500 {
+		t.Errorf("restored %d asteroids and a score of %d, want %d and 500", len(restored.asteroids), restored.players[1].Score, len(state.asteroids))
+	}
+}
+
+func TestSnapshotFitsIntoPacket(t *testing.T) {
+	state := newTestState(t, 2)
+	for i := range 2000 {
+		pos := rl.Vector2{X: float32(i % SCREEN_WIDTH), Y: float32(i % SCREEN_HEIGHT)}
+		addAsteroid(&state, pos, entities.Small, entities.Rock)
+		state.bullets = append(state.bullets, entities.NewBullet(pos, rl.Vector2{X: 1}))
+		state.popups = append(state.popups, entities.NewPopup(pos, "+100", rl.Yellow))
+	}
+
+	data, err := encodeSnapshotToFit(takeSnapshot(&state, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > MAX_SNAPSHOT_SIZE {
+		t.Fatalf("snapshot of %d bytes, want at most %d", len(data), MAX_SNAPSHOT_SIZE)
+	}
+
+	snapshot, err := decodeSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot.Truncated {
+		t.Error("snapshot isn't marked as truncated")
+	}
+	if len(snapshot.Players) != 2 {
+		t.Errorf("%d players sent, want all 2", len(snapshot.Players))
+	}
+	if len(snapshot.Popups) > 0 && len(snapshot.Bullets) < len(state.bullets) {
+		t.Error("bullets were left out before the popups")
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/network"
+	"asteroids/internal/utils"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// Most spectators that a game is broadcast to at once.
+	MAX_SPECTATORS = 16
+
+	// Seconds between the hellos that spectators send to keep watching.
+	// Spectators which haven't sent one for a while are dropped.
+	SPECTATOR_KEEPALIVE = 1.0
+)
+
+// Keys used by spectators to pick what they watch.
+const (
+	SPECTATE_NEXT_KEY = rl.KeyTab
+	SPECTATE_FREE_KEY = rl.KeyF
+)
+
+const (
+	// Zoom of the camera when it starts following a ship.
+	FOLLOW_ZOOM = 1.6
+
+	// Closest and furthest that the camera can zoom. At the furthest the
+	// whole playfield is in view.
+	MAX_ZOOM = 4.0
+	MIN_ZOOM = 1.0
+
+	// Playfield pixels per second that the free camera pans at a zoom of 1.
+	FREE_CAMERA_SPEED = 600
+)
+
+// Someone watching a broadcast game.
+type spectator struct {
+	addr     *net.UDPAddr
+	lastSeen time.Time
+}
+
+// Sends snapshots of a game to spectators, who watch it without playing. A
+// server shares its socket with its broadcaster, while a local game opens one
+// of its own.
+type Broadcaster struct {
+	conn       *network.Conn
+	spectators []*spectator
+	tick       uint32
+}
+
+func NewBroadcaster(conn *network.Conn) *Broadcaster {
+	return &Broadcaster{conn: conn}
+}
+
+// Broadcasts a local game to spectators connecting to `address`, e.g. ":7779".
+func Broadcast(address string, conditions network.Conditions) (*Broadcaster, error) {
+	conn, err := network.Listen(address, conditions)
+	if err != nil {
+		return nil, err
+	}
+	return NewBroadcaster(conn), nil
+}
+
+func (broadcaster *Broadcaster) Close() {
+	broadcaster.conn.Close()
+}
+
+// Lets a spectator watch, or tells them why they can't. Hellos from a
+// spectator who is already watching keep them watching, and are answered
+// with the welcome again in case the first one was lost.
+func (broadcaster *Broadcaster) welcome(addr *net.UDPAddr, hello network.Message) {
+	reject := func(reason string) {
+		broadcaster.conn.Send(addr, network.Message{Kind: network.Rejected, Reason: reason})
+	}
+
+	if hello.Version != network.VERSION {
+		reject(fmt.Sprintf("game is running version %d", network.VERSION))
+		return
+	}
+
+	i := slices.IndexFunc(broadcaster.spectators, func(spectator *spectator) bool {
+		return spectator.addr.String() == addr.String()
+	})
+	switch {
+	case i >= 0:
+		broadcaster.spectators[i].lastSeen = time.Now()
+	case len(broadcaster.spectators) >= MAX_SPECTATORS:
+		reject("too many spectators")
+		return
+	default:
+		broadcaster.spectators = append(broadcaster.spectators, &spectator{addr: addr, lastSeen: time.Now()})
+		log.Printf("spectator joined from %s", addr)
+	}
+
+	broadcaster.conn.Send(addr, network.Message{
+		Kind:       network.Welcome,
+		Player:     -1,
+		Difficulty: int(config.CurrentDifficulty()),
+	})
+}
+
+// Handles a message from a spectator. Returns false if the message may be
+// meant for someone else, as a bye is also sent by players leaving a server.
+func (broadcaster *Broadcaster) handle(packet network.Packet) bool {
+	message := packet.Message
+	switch {
+	case message.Kind == network.Hello && message.Spectate:
+		broadcaster.welcome(packet.From, message)
+	case message.Kind == network.Bye:
+		broadcaster.spectators = slices.DeleteFunc(broadcaster.spectators, func(spectator *spectator) bool {
+			return spectator.addr.String() == packet.From.String()
+		})
+		return false
+	default:
+		return false
+	}
+	return true
+}
+
+// Stops sending to spectators which haven't been heard from in a while.
+func (broadcaster *Broadcaster) dropTimedOut() {
+	broadcaster.spectators = slices.DeleteFunc(broadcaster.spectators, func(spectator *spectator) bool {
+		return time.Since(spectator.lastSeen) > CLIENT_TIMEOUT
+	})
+}
+
+// Sends an encoded snapshot taken on `tick` to every spectator.
+func (broadcaster *Broadcaster) send(data []byte, tick uint32) {
+	for _, spectator := range broadcaster.spectators {
+		broadcaster.conn.Send(spectator.addr, network.Message{Kind: network.Snapshot, Tick: tick, State: data})
+	}
+}
+
+// Advances a local game's broadcast by one tick, sending a snapshot of the
+// game every snapshot interval.
+func (broadcaster *Broadcaster) Update(state *GameState) {
+	for _, packet := range broadcaster.conn.Poll() {
+		broadcaster.handle(packet)
+	}
+	broadcaster.dropTimedOut()
+
+	broadcaster.tick++
+	if broadcaster.tick%SNAPSHOT_INTERVAL != 0 || len(broadcaster.spectators) == 0 {
+		return
+	}
+	data, err := encodeSnapshotToFit(takeSnapshot(state, broadcaster.tick))
+	if err != nil {
+		return
+	}
+	broadcaster.send(data, broadcaster.tick)
+}
+
+// What a spectator is looking at: either following a ship around or flying a
+// free camera over the playfield.
+type SpectatorCamera struct {
+	Following int        // Index of the player being followed, or -1 for the free camera.
+	Target    rl.Vector2 // Middle of the view in playfield coordinates.
+	Zoom      float32
+}
+
+func NewSpectatorCamera() SpectatorCamera {
+	return SpectatorCamera{
+		Following: -1,
+		Target:    rl.Vector2{X: SCREEN_WIDTH / 2, Y: SCREEN_HEIGHT / 2},
+		Zoom:      MIN_ZOOM,
+	}
+}
+
+// Handles the spectator's keys and moves the camera along. Tab cycles through
+// the ships and then the free camera, the number keys follow a ship straight
+// away and F switches to the free camera, which pans with the arrow keys or
+// WASD. The mouse wheel zooms either camera.
+func UpdateSpectatorCamera(camera *SpectatorCamera, state *GameState) {
+	follow := func(index int) {
+		if camera.Following < 0 {
+			camera.Zoom = max(camera.Zoom, FOLLOW_ZOOM)
+		}
+		camera.Following = index
+	}
+
+	if rl.IsKeyPressed(SPECTATE_NEXT_KEY) {
+		if camera.Following+1 < len(state.players) {
+			follow(camera.Following + 1)
+		} else {
+			camera.Following = -1
+		}
+	}
+	for i := range state.players {
+		if rl.IsKeyPressed(rl.KeyOne + int32(i)) {
+			follow(i)
+		}
+	}
+	if rl.IsKeyPressed(SPECTATE_FREE_KEY) {
+		camera.Following = -1
+	}
+	// Players can leave a server between rounds.
+	if camera.Following >= len(state.players) {
+		camera.Following = -1
+	}
+
+	camera.Zoom = rl.Clamp(camera.Zoom*(1+0.1*rl.GetMouseWheelMove()), MIN_ZOOM, MAX_ZOOM)
+
+	if camera.Following >= 0 {
+		camera.Target = state.players[camera.Following].ship.Pos
+	} else {
+		pan := rl.Vector2{}
+		if rl.IsKeyDown(rl.KeyLeft) || rl.IsKeyDown(rl.KeyA) {
+			pan.X--
+		}
+		if rl.IsKeyDown(rl.KeyRight) || rl.IsKeyDown(rl.KeyD) {
+			pan.X++
+		}
+		if rl.IsKeyDown(rl.KeyUp) || rl.IsKeyDown(rl.KeyW) {
+			pan.Y--
+		}
+		if rl.IsKeyDown(rl.KeyDown) || rl.IsKeyDown(rl.KeyS) {
+			pan.Y++
+		}
+		speed := FREE_CAMERA_SPEED / camera.Zoom * rl.GetFrameTime()
+		camera.Target = rl.Vector2Add(camera.Target, rl.Vector2Scale(rl.Vector2Normalize(pan), speed))
+	}
+
+	// The view is kept inside the playfield.
+	halfWidth := SCREEN_WIDTH / 2 / camera.Zoom
+	halfHeight := SCREEN_HEIGHT / 2 / camera.Zoom
+	camera.Target.X = rl.Clamp(camera.Target.X, halfWidth, SCREEN_WIDTH-halfWidth)
+	camera.Target.Y = rl.Clamp(camera.Target.Y, halfHeight, SCREEN_HEIGHT-halfHeight)
+}
+
+// Returns the raylib camera which draws the playfield from the spectator's
+// point of view.
+func (camera SpectatorCamera) Camera2D() rl.Camera2D {
+	return rl.Camera2D{
+		Offset: rl.Vector2{X: SCREEN_WIDTH / 2, Y: SCREEN_HEIGHT / 2},
+		Target: camera.Target,
+		Zoom:   camera.Zoom,
+	}
+}
+
+// Renders the game as a spectator sees it, through their camera and with the
+// scores of every player on top.
+func renderSpectated(state *GameState, viewport utils.Viewport, camera SpectatorCamera) {
+	renderWithCamera(state, viewport, camera.Camera2D())
+	renderSpectatorOverlay(state, camera, hudScale(viewport))
+}
+
+// Renders who is being watched and how to change it at the top of the window,
+// and every player's score ranked down the right of the window. Versus players
+// are ranked by rounds won and then kills.
+func renderSpectatorOverlay(state *GameState, camera SpectatorCamera, scale float32) {
+	width := int32(rl.GetScreenWidth())
+	height := int32(rl.GetScreenHeight())
+	fontSize := int32(HUD_FONT_SIZE*scale) * 2 / 3
+	margin := int32(16 * scale)
+
+	watching := "Free camera"
+	watchingColor := rl.RayWhite
+	if camera.Following >= 0 {
+		player := state.players[camera.Following]
+		watching = "Following " + player.Name()
+		watchingColor = player.ship.Color
+	}
+	y := margin + int32(HUD_FONT_SIZE*scale)*2
+	rl.DrawText(watching, width/2-rl.MeasureText(watching, fontSize)/2, y, fontSize, watchingColor)
+	hint := "Tab/1-4: follow   F: free camera   Arrows: pan   Wheel: zoom"
+	rl.DrawText(hint, width/2-rl.MeasureText(hint, fontSize*3/4)/2, y+fontSize+margin/4, fontSize*3/4, rl.Gray)
+
+	ranked := slices.Clone(state.players)
+	slices.SortStableFunc(ranked, func(a Player, b Player) int {
+		if state.mode == Versus {
+			if a.wins != b.wins {
+				return b.wins - a.wins
+			}
+			return b.kills - a.kills
+		}
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+
+	y = height / 3
+	for rank, player := range ranked {
+		row := fmt.Sprintf("%d. %s  %d", rank+1, player.Name(), player.Score)
+		if state.mode == Versus {
+			row = fmt.Sprintf("%d. %s  %d wins  %d kills", rank+1, player.Name(), player.wins, player.kills)
+		}
+		rl.DrawText(row, width-margin-rl.MeasureText(row, fontSize), y, fontSize, player.ship.Color)
+		y += fontSize + margin/2
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"asteroids/internal/network"
+	"net"
+	"testing"
+	"time"
+)
+
+// Returns the messages received on `conn` until `count` have arrived or a
+// second has passed.
+func receiveMessages(conn *network.Conn, count int) []network.Message {
+	messages := []network.Message{}
+	deadline := time.Now().Add(time.Second)
+	for len(messages) < count && time.Now().Before(deadline) {
+		for _, packet := range conn.Poll() {
+			messages = append(messages, packet.Message)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return messages
+}
+
+func TestBroadcasterWelcomesSpectatorsAgain(t *testing.T) {
+	broadcaster, err := Broadcast("127.0.0.1:0", network.Conditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broadcaster.Close()
+	conn, err := network.Listen("127.0.0.1:0", network.Conditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The spectator says hello again, as if the first welcome was lost.
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	hello := network.Message{Kind: network.Hello, Version: network.VERSION, Spectate: true}
+	broadcaster.welcome(addr, hello)
+	broadcaster.welcome(addr, hello)
+
+	if len(broadcaster.spectators) != 1 {
+		t.Errorf("%d spectators, want 1", len(broadcaster.spectators))
+	}
+	messages := receiveMessages(conn, 2)
+	if len(messages) != 2 {
+		t.Fatalf("received %d messages, want a welcome for each hello", len(messages))
+	}
+	for _, message := range messages {
+		if message.Kind != network.Welcome || message.Player != -1 {
+			t.Errorf("received a %s for player %d, want a welcome for a spectator", message.Kind, message.Player)
+		}
+	}
+}
+
+func TestBroadcasterRejectsTooManySpectators(t *testing.T) {
+	broadcaster, err := Broadcast("127.0.0.1:0", network.Conditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broadcaster.Close()
+	conn, err := network.Listen("127.0.0.1:0", network.Conditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for i := range MAX_SPECTATORS {
+		addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1 + i}
+		broadcaster.spectators = append(broadcaster.spectators, &spectator{addr: addr, lastSeen: time.Now()})
+	}
+	hello := network.Message{Kind: network.Hello, Version: network.VERSION, Spectate: true}
+	broadcaster.welcome(conn.LocalAddr().(*net.UDPAddr), hello)
+
+	messages := receiveMessages(conn, 1)
+	if len(messages) != 1 || messages[0].Kind != network.Rejected {
+		t.Errorf("received %v, want a rejection", messages)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/entities"
+	"asteroids/internal/utils"
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Distance from the middle of the window that versus ships start at.
+const VERSUS_SPAWN_RADIUS = constants.SCREEN_HEIGHT / 3
+
+// Closest that cover asteroids are placed to a versus spawn point.
+const VERSUS_COVER_CLEARANCE = 150
+
+// Progress of a versus match, which is played over several rounds.
+type Match struct {
+	Round       int     // Round being played, starting from 1.
+	RoundWinner int     // Index of the player who won the last round, or -1 for a draw.
+	MatchWinner int     // Index of the player who won the match, or -1 while it is being played.
+	breakTimer  float32 // Seconds left of the scoreboard shown between rounds.
+}
+
+func NewMatch() Match {
+	return Match{Round: 1, RoundWinner: -1, MatchWinner: -1}
+}
+
+// Returns true when rounds are won by kills rather than by being the last
+// ship flying.
+func isPlayingForKills() bool {
+	return config.Get().Versus.WinCondition == "kills"
+}
+
+// Returns where the ship of the player at `index` out of `count` players
+// starts in versus, spread evenly around the middle of the window.
+func versusSpawn(index int, count int) rl.Vector2 {
+	centre := rl.Vector2{X: constants.SCREEN_WIDTH / 2, Y: constants.SCREEN_HEIGHT / 2}
+	offset := rl.Vector2Rotate(rl.Vector2{X: -VERSUS_SPAWN_RADIUS, Y: 0}, 2*rl.Pi*float32(index)/float32(count))
+	return rl.Vector2Add(centre, offset)
+}
+
+// Places the asteroids which the ships can hide behind at the start of a
+// round, keeping them clear of the spawn points.
+func placeCover(state *GameState) {
+	for range config.Get().Versus.Cover {
+		pos := rl.Vector2{}
+		for {
+			pos = rl.Vector2{
+				X: utils.RandInRange(0, constants.SCREEN_WIDTH),
+				Y: utils.RandInRange(0, constants.SCREEN_HEIGHT),
+			}
+			if isClearOfSpawns(pos, len(state.players)) {
+				break
+			}
+		}
+
+		asteroid := entities.SpawnAsteroidAt(pos, entities.Large)
+		state.asteroids = append(state.asteroids, asteroid)
+	}
+}
+
+func isClearOfSpawns(pos rl.Vector2, count int) bool {
+	for i := range count {
+		if rl.Vector2Distance(pos, versusSpawn(i, count)) < VERSUS_COVER_CLEARANCE {
+			return false
+		}
+	}
+	return true
+}
+
+// Ends the round once its winner is known. When playing for kills the first
+// player to reach the kill target wins, otherwise the last ship flying wins.
+// The round is a draw if the last ships go down together.
+func updateVersus(state *GameState) {
+	tuning := config.Get().Versus
+
+	winner := -1
+	if isPlayingForKills() {
+		for _, player := range state.players {
+			if player.kills >= tuning.KillTarget {
+				winner = player.index
+				break
+			}
+		}
+		if winner < 0 {
+			return
+		}
+	} else {
+		standing := 0
+		for _, player := range state.players {
+			if !player.IsDown() {
+				standing++
+				winner = player.index
+			}
+		}
+		if standing > 1 {
+			return
+		}
+	}
+
+	state.match.RoundWinner = winner
+	if winner >= 0 {
+		state.players[winner].wins++
+		if state.players[winner].wins >= tuning.RoundsToWin {
+			state.match.MatchWinner = winner
+			return
+		}
+	}
+	state.match.breakTimer = max(tuning.RoundBreak, TICK)
+}
+
+// Counts down the scoreboard shown between rounds and starts the next round
+// once it runs out. Returns true while the break is on, as nothing else moves
+// during it.
+func updateRoundBreak(state *GameState) bool {
+	if state.match.breakTimer <= 0 {
+		return false
+	}
+
+	state.match.breakTimer -= TICK
+	if state.match.breakTimer <= 0 {
+		startRound(state)
+	}
+	return true
+}
+
+// Clears the playfield and puts every ship back at its spawn point for the
+// next round. Only the rounds won carry over between rounds.
+func startRound(state *GameState) {
+	state.match.Round++
+
+	for i := range state.players {
+		wins := state.players[i].wins
+		state.players[i] = NewPlayer(i, len(state.players), state.mode)
+		state.players[i].wins = wins
+	}
+
+	state.asteroids = []entities.Asteroid{}
+	state.bullets = []entities.Bullet{}
+	state.powerUps = []entities.PowerUp{}
+	state.explosions = []entities.Explosion{}
+	state.popups = []entities.Popup{}
+	state.asteroidTimer = 0
+	placeCover(state)
+}
+
+// Credits the kill to the shooter when their projectile destroys another
+// ship in versus.
+func creditKill(state *GameState, shooter *Player, victim *Player) {
+	if state.mode != Versus {
+		return
+	}
+
+	shooter.kills++
+	text := fmt.Sprintf("%s > %s", shooter.Name(), victim.Name())
+	state.popups = append(state.popups, entities.NewPopup(victim.ship.Pos, text, shooter.ship.Color))
+}
+
+// Returns a summary of the versus rules from the tuning, shown on the menu.
+func versusRules() string {
+	tuning := config.Get().Versus
+	if isPlayingForKills() {
+		return fmt.Sprintf("First to %d kills wins the round, first to %d rounds wins the match", tuning.KillTarget, tuning.RoundsToWin)
+	}
+	return fmt.Sprintf("Last ship standing wins the round, first to %d rounds wins the match", tuning.RoundsToWin)
+}
+
+// Returns what the round or match is played for, shown at the top of the HUD.
+func versusGoal(state *GameState) string {
+	goal := "Last ship standing"
+	if isPlayingForKills() {
+		goal = fmt.Sprintf("First to %d kills", config.Get().Versus.KillTarget)
+	}
+	return fmt.Sprintf("Round %d: %s", state.match.Round, goal)
+}
+
+// Renders the scoreboard between rounds and at the end of the match, with the
+// winner and every player's kills and rounds won.
+func renderScoreboard(state *GameState, scale float32) {
+	if state.match.breakTimer <= 0 && !state.isGameOver {
+		return
+	}
+
+	width := int32(rl.GetScreenWidth())
+	height := int32(rl.GetScreenHeight())
+	fontSize := int32(HUD_FONT_SIZE * scale)
+	lineHeight := fontSize + int32(8*scale)
+	y := height / 6
+
+	title := fmt.Sprintf("ROUND %d: DRAW", state.match.Round)
+	titleColor := rl.RayWhite
+	if winner := state.match.RoundWinner; winner >= 0 {
+		title = fmt.Sprintf("ROUND %d: %s WINS", state.match.Round, state.players[winner].Name())
+		titleColor = state.players[winner].ship.Color
+	}
+	if winner := state.match.MatchWinner; winner >= 0 {
+		title = fmt.Sprintf("%s WINS THE MATCH", state.players[winner].Name())
+	}
+	rl.DrawText(title, width/2-rl.MeasureText(title, fontSize)/2, y, fontSize, titleColor)
+	y += lineHeight * 3 / 2
+
+	for _, player := range state.players {
+		row := fmt.Sprintf("%s   %d kills   %d/%d rounds", player.Name(), player.kills, player.wins, config.Get().Versus.RoundsToWin)
+		rl.DrawText(row, width/2-rl.MeasureText(row, fontSize*2/3)/2, y, fontSize*2/3, player.ship.Color)
+		y += lineHeight
+	}
+
+	if state.match.breakTimer > 0 {
+		next := fmt.Sprintf("Next round in %.0f", state.match.breakTimer)
+		rl.DrawText(next, width/2-rl.MeasureText(next, fontSize*2/3)/2, y+lineHeight/2, fontSize*2/3, rl.Gray)
+	}
+}
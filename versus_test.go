@@ -0,0 +1,96 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"testing"
+)
+
+func TestLastShipStandingWins(t *testing.T) {
+	newTestState(t, 1)
+	state := NewGameState(Versus, 3)
+
+	state.players[0].lives = 0
+	updateVersus(&state)
+	if state.match.breakTimer > 0 {
+		t.Fatal("the round ended with two ships flying")
+	}
+
+	state.players[2].lives = 0
+	updateVersus(&state)
+	if state.match.RoundWinner != 1 || state.players[1].wins != 1 {
+		t.Errorf("round won by player %d with %d wins, want player 2 with 1", state.match.RoundWinner, state.players[1].wins)
+	}
+	if state.match.breakTimer <= 0 || state.match.MatchWinner >= 0 {
+		t.Error("the match ended after the first round, want a break before the next")
+	}
+}
+
+func TestVersusDraw(t *testing.T) {
+	newTestState(t, 1)
+	state := NewGameState(Versus, 2)
+	state.players[0].lives = 0
+	state.players[1].lives = 0
+
+	updateVersus(&state)
+	if state.match.RoundWinner != -1 || state.players[0].wins+state.players[1].wins != 0 {
+		t.Errorf("round won by player %d, want a draw", state.match.RoundWinner)
+	}
+}
+
+func TestVersusMatchWinner(t *testing.T) {
+	newTestState(t, 1)
+	tuning := config.Get()
+	tuning.Versus.WinCondition = "kills"
+	config.Set(tuning)
+	state := NewGameState(Versus, 2)
+	state.players[1].wins = tuning.Versus.RoundsToWin - 1
+
+	state.players[0].kills = tuning.Versus.KillTarget - 1
+	updateVersus(&state)
+	if state.match.RoundWinner >= 0 || state.match.breakTimer > 0 {
+		t.Fatal("the round ended short of the kill target")
+	}
+
+	state.players[1].kills = tuning.Versus.KillTarget
+	updateVersus(&state)
+	if state.match.MatchWinner != 1 || !isModeOver(&state) {
+		t.Errorf("match won by player %d, want player 2 to win it on the last round", state.match.MatchWinner)
+	}
+}
+
+func TestStartRoundKeepsWins(t *testing.T) {
+	newTestState(t, 1)
+	state := NewGameState(Versus, 2)
+	state.players[0].wins = 2
+	state.players[0].kills = 4
+	state.players[1].lives = 0
+
+	startRound(&state)
+	if state.match.Round != 2 {
+		t.Errorf("playing round %d, want 2", state.match.Round)
+	}
+	if state.players[0].wins != 2 || state.players[0].kills != 0 {
+		t.Errorf("player 1 has %d wins and %d kills, want the wins kept and the kills reset", state.players[0].wins, state.players[0].kills)
+	}
+	if state.players[1].IsDown() {
+		t.Error("player 2 is still down in the new round")
+	}
+	if len(state.asteroids) != config.Get().Versus.Cover {
+		t.Errorf("%d asteroids, want only the %d placed as cover", len(state.asteroids), config.Get().Versus.Cover)
+	}
+}
+
+func TestCreditKill(t *testing.T) {
+	newTestState(t, 1)
+	state := NewGameState(Versus, 2)
+	creditKill(&state, &state.players[0], &state.players[1])
+	if state.players[0].kills != 1 || len(state.popups) != 1 {
+		t.Errorf("%d kills and %d popups, want the kill credited and shown", state.players[0].kills, len(state.popups))
+	}
+
+	state = NewGameState(Classic, 2)
+	creditKill(&state, &state.players[0], &state.players[1])
+	if state.players[0].kills != 0 {
+		t.Error("a kill was credited outside versus")
+	}
+}
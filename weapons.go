@@ -0,0 +1,179 @@
+package main
+
+import (
+	"asteroids/internal/entities"
+	"asteroids/internal/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Fires the selected weapon of the player's ship. Projectiles travel along the ship's
+// heading unless the player is aiming in twin-stick mode. Active power-ups
+// change how the weapons fire.
+func fireWeapon(state *GameState, player *Player, controls input.Controls) {
+	player.weaponTimer -= TICK
+	player.beam.Active = false
+	coolWeapons(player)
+
+	aim := player.ship.Heading()
+	if controls.Mode == input.TwinStick && rl.Vector2Length(controls.Aim) > 0 {
+		aim = rl.Vector2Normalize(controls.Aim)
+	}
+
+	released := player.wasFiring && !controls.Fire
+	player.wasFiring = controls.Fire
+
+	if player.ship.IsDead() {
+		player.charge = 0
+		return
+	}
+
+	weapon := player.ship.Weapon
+	tuning := weapon.Tuning()
+
+	// An overheated weapon is locked until it has cooled down completely.
+	if player.overheated[weapon] {
+		player.charge = 0
+		return
+	}
+
+	switch weapon {
+	case entities.Laser:
+		if controls.Fire {
+			fireLaser(state, player, aim)
+		}
+	case entities.Charge:
+		// The charge shot builds up while fire is held and is released when
+		// fire is let go.
+		if controls.Fire {
+			player.charge = min(player.charge+TICK, entities.CHARGE_MAX_TIME)
+		} else if released && player.weaponTimer <= 0 {
+			damage := entities.ChargeDamage(player.charge)
+			for _, aim := range powerUpAims(player, aim) {
+				bullet := entities.NewProjectile(entities.ChargedShot, player.ship.Pos, aim, tuning.Speed, damage)
+				bullet.Radius = entities.BULLET_RADIUS + 2*float32(damage)
+				launch(state, player, bullet)
+			}
+			// Charge shots heat up the weapon more the longer they are charged.
+			addHeat(player, tuning.Heat*(0.5+0.5*player.charge/entities.CHARGE_MAX_TIME))
+			player.charge = 0
+			resetWeaponTimer(player)
+		} else {
+			player.charge = 0
+		}
+	default:
+		if !controls.Fire || player.weaponTimer > 0 {
+			return
+		}
+
+		kind := entities.Shot
+		aims := powerUpAims(player, aim)
+		switch weapon {
+		case entities.Spread:
+			aims = entities.SpreadAims(aim, entities.SPREAD_WEAPON_BULLETS, entities.SPREAD_WEAPON_ANGLE)
+		case entities.Homing:
+			kind = entities.Missile
+		}
+
+		for _, aim := range aims {
+			launch(state, player, entities.NewProjectile(kind, player.ship.Pos, aim, tuning.Speed, tuning.Damage))
+		}
+		addHeat(player, tuning.Heat)
+		resetWeaponTimer(player)
+	}
+}
+
+// Returns the directions to fire in. The spread shot power-up fires two extra
+// projectiles either side of the aim.
+func powerUpAims(player *Player, aim rl.Vector2) []rl.Vector2 {
+	if isActive(player, entities.SpreadShot) {
+		return entities.SpreadAims(aim, 3, SPREAD_ANGLE)
+	}
+	return []rl.Vector2{aim}
+}
+
+// Adds a projectile fired by the player to the game, applying the piercing
+// rounds power-up.
+func launch(state *GameState, player *Player, bullet entities.Bullet) {
+	bullet.Piercing = isActive(player, entities.PiercingRounds)
+	bullet.Owner = player.index
+	state.bullets = append(state.bullets, bullet)
+	state.adaptive.shots++
+	recordShot(state, player)
+}
+
+// Restarts the cooldown of the selected weapon. Rapid fire shortens it.
+func resetWeaponTimer(player *Player) {
+	player.weaponTimer = player.ship.Weapon.Tuning().Cooldown
+	if isActive(player, entities.RapidFire) {
+		player.weaponTimer /= RAPID_FIRE_RATE
+	}
+}
+
+// Casts the laser beam from the ship and damages the first asteroid, part of
+// the boss or other ship it hits every time the weapon's cooldown runs out.
+// Other ships only stop the beam when they can be hit. The beam heats up on
+// every tick whether or not it hits anything.
+func fireLaser(state *GameState, player *Player, aim rl.Vector2) {
+	end, hit := entities.CastBeam(player.ship.Pos, aim, entities.LASER_RANGE, state.asteroids)
+
+	// The boss blocks the beam if it is closer than any asteroid.
+	hitsBoss := false
+	if state.boss.Active {
+		bossEnd, bossHit := entities.CastBeam(player.ship.Pos, aim, entities.LASER_RANGE, state.boss.Colliders())
+		if bossHit >= 0 && rl.Vector2Distance(player.ship.Pos, bossEnd) < rl.Vector2Distance(player.ship.Pos, end) {
+			end, hitsBoss = bossEnd, true
+		}
+	}
+
+	var hitShip *Player
+	if canHitShips(state) {
+		colliders, owners := otherShips(state, player)
+		shipEnd, shipHit := entities.CastBeam(player.ship.Pos, aim, entities.LASER_RANGE, colliders)
+		if shipHit >= 0 && rl.Vector2Distance(player.ship.Pos, shipEnd) < rl.Vector2Distance(player.ship.Pos, end) {
+			end, hitShip, hitsBoss = shipEnd, owners[shipHit], false
+		}
+	}
+	player.beam = entities.Beam{Active: true, Start: player.ship.Pos, End: end}
+
+	if player.weaponTimer <= 0 {
+		damage := entities.Laser.Tuning().Damage
+		if hitShip != nil {
+			shootShip(state, player, hitShip)
+		} else if hitsBoss {
+			// Nudge the hit point into the part that the beam stopped at.
+			hitBoss(state, player, rl.Vector2Add(end, rl.Vector2Scale(aim, 2)), 1, damage)
+		} else if hit >= 0 {
+			damageAsteroid(state, player, hit, damage)
+		}
+		addHeat(player, entities.Laser.Tuning().Heat)
+		resetWeaponTimer(player)
+	}
+}
+
+// Adds heat to the selected weapon. Rapid fire halves the heat so that the
+// faster fire rate does not immediately overheat the weapon.
+func addHeat(player *Player, heat float32) {
+	weapon := player.ship.Weapon
+	if isActive(player, entities.RapidFire) {
+		heat /= 2
+	}
+
+	player.heat[weapon] += heat
+	if player.heat[weapon] >= 1 {
+		player.heat[weapon] = 1
+		player.overheated[weapon] = true
+	}
+}
+
+// Dissipates the heat of every weapon, including those not selected, and
+// unlocks overheated weapons once they have fully cooled.
+func coolWeapons(player *Player) {
+	for i := range player.heat {
+		weapon := entities.WeaponKind(i)
+		player.heat[i] = max(0, player.heat[i]-weapon.Tuning().Cooling*TICK)
+		if player.heat[i] == 0 {
+			player.overheated[i] = false
+		}
+	}
+}
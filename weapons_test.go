@@ -0,0 +1,153 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/entities"
+	"asteroids/internal/input"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Fires the first player's weapon for a tick with fire held and returns the
+// number of projectiles launched.
+func fireOnce(state *GameState) int {
+	before := len(state.bullets)
+	fireWeapon(state, &state.players[0], input.Controls{Fire: true})
+	return len(state.bullets) - before
+}
+
+func TestWeaponOverheats(t *testing.T) {
+	state := newTestState(t, 1)
+	tuning := config.Get()
+	tuning.Weapons.Blaster.Cooldown = 0
+	tuning.Weapons.Blaster.Heat = 0.4
+	tuning.Weapons.Blaster.Cooling = 6
+	config.Set(tuning)
+	player := &state.players[0]
+
+	for shot := range 3 {
+		if fireOnce(&state) != 1 {
+			t.Fatalf("shot %d didn't fire", shot+1)
+		}
+	}
+	if !player.overheated[entities.Blaster] || player.heat[entities.Blaster] != 1 {
+		t.Fatalf("heat %v and overheated %v after three shots, want overheated", player.heat[entities.Blaster], player.overheated[entities.Blaster])
+	}
+
+	// The weapon stays locked until it has cooled down completely, even
+	// though it could fire again on the way down.
+	ticks := 1
+	for fireOnce(&state) == 0 {
+		ticks++
+		if ticks > constants.TICK_RATE {
+			t.Fatal("didn't fire again once cooled down")
+		}
+	}
+	if want := int(1 / (6 * TICK)); ticks < want {
+		t.Errorf("fired again after %d ticks, want at least %d to cool down", ticks, want)
+	}
+}
+
+func TestCoolingCoversEveryWeapon(t *testing.T) {
+	state := newTestState(t, 1)
+	player := &state.players[0]
+	player.heat[entities.Spread] = 0.5
+
+	coolWeapons(player)
+	want := 0.5 - entities.Spread.Tuning().Cooling*TICK
+	if got := player.heat[entities.Spread]; got != want {
+		t.Errorf("spread heat %v after a tick with the blaster selected, want %v", got, want)
+	}
+}
+
+func TestRapidFireHalvesHeat(t *testing.T) {
+	state := newTestState(t, 1)
+	player := &state.players[0]
+
+	addHeat(player, 0.2)
+	player.effects[entities.RapidFire] = 1
+	addHeat(player, 0.2)
+	if got := player.heat[player.ship.Weapon]; got < 0.299 || got > 0.301 {
+		t.Errorf("heat %v, want 0.2 for the normal shot and 0.1 with rapid fire", got)
+	}
+}
+
+func TestTwinStickFiresAlongAim(t *testing.T) {
+	state := newTestState(t, 1)
+	player := &state.players[0]
+	player.ship.Rot = 0
+	aim := rl.Vector2{X: 3, Y: -4}
+
+	fireWeapon(&state, player, input.Controls{Mode: input.TwinStick, Fire: true, Aim: aim})
+	if len(state.bullets) != 1 {
+		t.Fatalf("fired %d bullets, want 1", len(state.bullets))
+	}
+	if dir := state.bullets[0].Dir; rl.Vector2Distance(dir, rl.Vector2Normalize(aim)) > 1e-3 {
+		t.Errorf("bullet heads towards %v, want the aim %v rather than the ship's heading", dir, rl.Vector2Normalize(aim))
+	}
+}
+
+func TestWeaponsFire(t *testing.T) {
+	tests := []struct {
+		weapon  entities.WeaponKind
+		bullets int
+		kind    entities.ProjectileKind
+	}{
+		{entities.Blaster, 1, entities.Shot},
+		{entities.Spread, entities.SPREAD_WEAPON_BULLETS, entities.Shot},
+		{entities.Homing, 1, entities.Missile},
+	}
+	for _, test := range tests {
+		state := newTestState(t, 1)
+		state.players[0].ship.Weapon = test.weapon
+
+		if fired := fireOnce(&state); fired != test.bullets {
+			t.Errorf("%s fired %d projectiles, want %d", test.weapon, fired, test.bullets)
+			continue
+		}
+		if kind := state.bullets[0].Kind; kind != test.kind {
+			t.Errorf("%s fired a %v, want a %v", test.weapon, kind, test.kind)
+		}
+	}
+}
+
+func TestChargeShotFiresOnRelease(t *testing.T) {
+	state := newTestState(t, 1)
+	player := &state.players[0]
+	player.ship.Weapon = entities.Charge
+
+	for range int(entities.CHARGE_MAX_TIME/TICK) + 1 {
+		if fireOnce(&state) != 0 {
+			t.Fatal("fired while charging")
+		}
+	}
+	fireWeapon(&state, player, input.Controls{})
+	if len(state.bullets) != 1 {
+		t.Fatalf("fired %d projectiles on release, want 1", len(state.bullets))
+	}
+	if bullet := state.bullets[0]; bullet.Kind != entities.ChargedShot || bullet.Damage != entities.Charge.Tuning().Damage {
+		t.Errorf("released a %v doing %d damage, want a fully charged shot", bullet.Kind, bullet.Damage)
+	}
+}
+
+func TestLaserHitsFirstAsteroid(t *testing.T) {
+	state := newTestState(t, 1)
+	state.asteroids = nil
+	player := &state.players[0]
+	player.ship.Pos = rl.Vector2{X: 400, Y: 300}
+	player.ship.Rot = 0
+	near := addAsteroid(&state, rl.Vector2{X: 400, Y: 400}, entities.Large, entities.Rock)
+	far := addAsteroid(&state, rl.Vector2{X: 400, Y: 600}, entities.Large, entities.Rock)
+	player.ship.Weapon = entities.Laser
+	health := state.asteroids[near].Health
+
+	fireWeapon(&state, player, input.Controls{Fire: true})
+	if !player.beam.Active || player.beam.End.Y >= 400 {
+		t.Errorf("beam active %v ending at %v, want it stopped by the nearest asteroid", player.beam.Active, player.beam.End)
+	}
+	if state.asteroids[near].Health >= health || state.asteroids[far].Health != health {
+		t.Errorf("asteroid health %d and %d, want only the nearest damaged", state.asteroids[near].Health, state.asteroids[far].Health)
+	}
+}
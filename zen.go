@@ -0,0 +1,156 @@
+package main
+
+import (
+	"asteroids/internal/config"
+	"asteroids/internal/constants"
+	"asteroids/internal/entities"
+	"asteroids/internal/utils"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Keys used in zen mode. They are fixed and chosen so that they don't clash
+// with any of the control presets.
+const (
+	ZEN_HITBOX_KEY = rl.KeyH
+	ZEN_FREEZE_KEY = rl.KeyT
+	ZEN_CLEAR_KEY  = rl.KeyBackspace
+)
+
+// Position and size of the slider panel in the bottom left of the playfield.
+const (
+	ZEN_PANEL_X      = 20
+	ZEN_SLIDER_WIDTH = 220
+	ZEN_SLIDER_ROW   = 40
+)
+
+// A slider on the zen panel and the tuning value it edits.
+type zenSlider struct {
+	utils.Slider
+	value func(tuning *config.Tuning) *float32
+}
+
+var zenSliders = []zenSlider{
+	{utils.Slider{Label: "Rotation speed", Min: 0.01, Max: 0.15}, func(t *config.Tuning) *float32 { return &t.Ship.RotationSpeed }},
+	{utils.Slider{Label: "Accel", Min: 0, Max: 0.3}, func(t *config.Tuning) *float32 { return &t.Ship.Accel }},
+	{utils.Slider{Label: "Decel", Min: 0, Max: 0.1}, func(t *config.Tuning) *float32 { return &t.Ship.Decel }},
+	{utils.Slider{Label: "Min velocity", Min: 0, Max: 5}, func(t *config.Tuning) *float32 { return &t.Ship.MinVel }},
+	{utils.Slider{Label: "Max velocity", Min: 1, Max: 15}, func(t *config.Tuning) *float32 { return &t.Ship.MaxVel }},
+	{utils.Slider{Label: "Drag", Min: 0, Max: 0.1}, func(t *config.Tuning) *float32 { return &t.Ship.Drag }},
+}
+
+// Sandbox tools for zen mode, where the ship can't die and asteroids only
+// appear when they are placed.
+type Sandbox struct {
+	Size     entities.AsteroidSize // Size of the asteroids placed at the cursor.
+	Frozen   bool                  // Whether everything but the sandbox tools is paused.
+	dragging int                   // Index of the slider being dragged, or -1.
+	message  string                // Result of saving the tuning.
+}
+
+func NewSandbox() Sandbox {
+	return Sandbox{Size: entities.Medium, dragging: -1}
+}
+
+// Returns where the slider at index `i` is drawn in the playfield.
+func zenSliderBounds(i int) rl.Rectangle {
+	y := constants.SCREEN_HEIGHT - float32(len(zenSliders)-i)*ZEN_SLIDER_ROW
+	return rl.Rectangle{X: ZEN_PANEL_X, Y: y, Width: ZEN_SLIDER_WIDTH, Height: 10}
+}
+
+// Handles the sandbox tools. Right clicking places an asteroid at the cursor
+// and dragging a slider changes the tuning, which is saved once the slider is
+// let go. Returns true while the mouse is being used by the panel, so that it
+// doesn't also fire the ship's weapon.
+func updateSandbox(state *GameState, cursor rl.Vector2) bool {
+	sandbox := &state.sandbox
+
+	switch {
+	case rl.IsKeyPressed(rl.KeyOne):
+		sandbox.Size = entities.Small
+	case rl.IsKeyPressed(rl.KeyTwo):
+		sandbox.Size = entities.Medium
+	case rl.IsKeyPressed(rl.KeyThree):
+		sandbox.Size = entities.Large
+	case rl.IsKeyPressed(ZEN_HITBOX_KEY):
+		entities.ShowHitboxes = !entities.ShowHitboxes
+	case rl.IsKeyPressed(ZEN_FREEZE_KEY):
+		sandbox.Frozen = !sandbox.Frozen
+	case rl.IsKeyPressed(ZEN_CLEAR_KEY):
+		state.asteroids = []entities.Asteroid{}
+	}
+
+	if rl.IsMouseButtonPressed(rl.MouseButtonRight) {
+		state.asteroids = append(state.asteroids, entities.SpawnAsteroidAt(cursor, sandbox.Size))
+	}
+
+	if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
+		for i := range zenSliders {
+			bounds := zenSliderBounds(i)
+			// The grab area is taller than the track so that it is easy to hit.
+			bounds.Y -= ZEN_SLIDER_ROW / 4
+			bounds.Height += ZEN_SLIDER_ROW / 2
+			if rl.CheckCollisionPointRec(cursor, bounds) {
+				sandbox.dragging = i
+			}
+		}
+	}
+	if sandbox.dragging < 0 {
+		return false
+	}
+
+	slider := zenSliders[sandbox.dragging]
+	tuning := config.Base()
+	*slider.value(&tuning) = slider.ValueAt(zenSliderBounds(sandbox.dragging), cursor.X)
+	config.Set(tuning)
+
+	if rl.IsMouseButtonReleased(rl.MouseButtonLeft) {
+		sandbox.dragging = -1
+		sandbox.message = "Saved " + constants.TUNING_FILE
+		if err := config.Save(constants.TUNING_FILE, tuning); err != nil {
+			sandbox.message = "Failed to save tuning: " + err.Error()
+		}
+	}
+	return true
+}
+
+// Draws the zen panel in the playfield: the controls, the tuning sliders and,
+// if they are enabled, the hitboxes of everything but the asteroids.
+func renderSandbox(state *GameState) {
+	sandbox := state.sandbox
+	tuning := config.Base()
+
+	if entities.ShowHitboxes {
+		for _, player := range state.players {
+			rl.DrawCircleLinesV(player.ship.Pos, entities.SHIP_HITBOX_RADIUS, rl.Yellow)
+		}
+		for _, bullet := range state.bullets {
+			rl.DrawCircleLinesV(bullet.Start, bullet.Radius, rl.Yellow)
+		}
+		for _, powerUp := range state.powerUps {
+			rl.DrawCircleLinesV(powerUp.Pos, entities.POWERUP_HITBOX, rl.Yellow)
+		}
+	}
+
+	for i, slider := range zenSliders {
+		utils.DrawSlider(slider.Slider, zenSliderBounds(i), *slider.value(&tuning), i == sandbox.dragging)
+	}
+
+	lines := []string{
+		"ZEN - " + sandbox.Size.String() + " asteroids",
+		"1/2/3 size   RIGHT CLICK place   BACKSPACE clear",
+		"H hitboxes   T freeze time",
+	}
+	if sandbox.Frozen {
+		lines = append(lines, "TIME FROZEN")
+	}
+	if sandbox.message != "" {
+		lines = append(lines, sandbox.message)
+	}
+
+	y := zenSliderBounds(0).Y - ZEN_SLIDER_ROW - float32(len(lines))*20
+	for _, line := range lines {
+		rl.DrawText(line, ZEN_PANEL_X, int32(y), utils.SLIDER_FONT_SIZE, rl.Gray)
+		y += 20
+	}
+}